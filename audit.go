@@ -0,0 +1,144 @@
+package bawt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditBucket is the name of the Bolt DB bucket holding the audit log
+const AuditBucket = "audit"
+
+// Results recorded in the audit log
+const (
+	AuditSuccess = "success"
+	AuditDenied  = "denied"
+	AuditFailure = "failure"
+)
+
+// AuditEntry records a single privileged action. Entries are append-only.
+type AuditEntry struct {
+	Time   time.Time         `json:"time"`
+	Actor  string            `json:"actor"`            // Slack User ID, or a description for non-chat actors
+	Action string            `json:"action"`           // ex: "group:add-user"
+	Target string            `json:"target,omitempty"` // ex: the group name
+	Params map[string]string `json:"params,omitempty"`
+	Result string            `json:"result"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// String returns a one line summary of the entry
+func (e AuditEntry) String() string {
+	out := fmt.Sprintf("%s <@%s> %s", e.Time.Format("2006-01-02 15:04:05"), e.Actor, e.Action)
+	if e.Target != "" {
+		out += " " + e.Target
+	}
+
+	keys := make([]string, 0, len(e.Params))
+	for k := range e.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		out += fmt.Sprintf(" %s=%s", k, e.Params[k])
+	}
+
+	out += ": " + e.Result
+	if e.Error != "" {
+		out += " (" + e.Error + ")"
+	}
+
+	return out
+}
+
+// Audit appends an entry to the audit log, and mirrors it to the
+// configured `audit_channel` if any.
+func (bot *Bot) Audit(entry AuditEntry) error {
+	log := bot.Logging.Logger
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.Result == "" {
+		entry.Result = AuditSuccess
+	}
+
+	err := bot.DB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(AuditBucket))
+		if err != nil {
+			return err
+		}
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		cnt, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(auditKey(entry.Time, seq), cnt)
+	})
+	if err != nil {
+		log.WithError(err).Error("Unable to write to the audit log")
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"Type":   "Audit",
+		"Actor":  entry.Actor,
+		"Action": entry.Action,
+		"Target": entry.Target,
+		"Result": entry.Result,
+	}).Info("Privileged action.")
+
+	if bot.Config.AuditChannel != "" && bot.currentRTM() != nil {
+		bot.SendToChannel(bot.Config.AuditChannel, fmt.Sprintf(":closed_lock_with_key: %s", entry))
+	}
+
+	return nil
+}
+
+// AuditLog returns the entries recorded after `since`, oldest first. If
+// `actor` isn't empty, only entries for that actor are returned.
+func (bot *Bot) AuditLog(actor string, since time.Time) ([]AuditEntry, error) {
+	out := make([]AuditEntry, 0)
+
+	err := bot.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(AuditBucket))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(auditKey(since, 0)); k != nil; k, v = c.Next() {
+			var entry AuditEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+
+			if actor != "" && !strings.EqualFold(entry.Actor, actor) {
+				continue
+			}
+
+			out = append(out, entry)
+		}
+
+		return nil
+	})
+
+	return out, err
+}
+
+// auditKey sorts lexicographically by time, then by insertion order
+func auditKey(t time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%020d:%010d", t.UnixNano(), seq))
+}
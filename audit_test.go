@@ -0,0 +1,52 @@
+package bawt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBot_Audit(t *testing.T) {
+	bot := bawttest.NewBot(bawttest.NewDB(t))
+
+	now := time.Now()
+
+	assert.NoError(t, bot.Audit(bawt.AuditEntry{Time: now.Add(-48 * time.Hour), Actor: "U1", Action: "group:add-user", Target: "ops"}))
+	assert.NoError(t, bot.Audit(bawt.AuditEntry{Time: now.Add(-1 * time.Hour), Actor: "U2", Action: "bawt:dump-config", Result: bawt.AuditDenied}))
+	assert.NoError(t, bot.Audit(bawt.AuditEntry{Time: now.Add(-1 * time.Hour), Actor: "U1", Action: "group:remove-user", Target: "ops"}))
+
+	all, err := bot.AuditLog("", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, "group:add-user", all[0].Action)
+	assert.Equal(t, bawt.AuditSuccess, all[0].Result)
+
+	// Entries with identical times keep their insertion order
+	assert.Equal(t, "bawt:dump-config", all[1].Action)
+	assert.Equal(t, "group:remove-user", all[2].Action)
+
+	recent, err := bot.AuditLog("", now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, recent, 2)
+
+	byActor, err := bot.AuditLog("U1", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, byActor, 2)
+}
+
+func TestAuditEntry_String(t *testing.T) {
+	e := bawt.AuditEntry{
+		Time:   time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC),
+		Actor:  "U1",
+		Action: "group:add-user",
+		Target: "ops",
+		Params: map[string]string{"user": "U2", "a": "b"},
+		Result: bawt.AuditFailure,
+		Error:  "boom",
+	}
+
+	assert.Equal(t, "2019-08-01 10:00:00 <@U1> group:add-user ops a=b user=U2: failure (boom)", e.String())
+}
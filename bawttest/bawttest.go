@@ -0,0 +1,102 @@
+// Package bawttest provides the fixtures shared by the tests of bawt's
+// plugins
+package bawttest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/gopherworks/bawt"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+)
+
+// Sent is a message sent by a plugin under test, to a channel or a user
+type Sent struct {
+	To   string
+	Text string
+}
+
+// Recorder returns a function recording the messages sent in `out`, to
+// stand in for the senders of a plugin
+func Recorder(out *[]Sent) func(to, text string) {
+	return func(to, text string) {
+		*out = append(*out, Sent{to, text})
+	}
+}
+
+// NewDB opens a database in a temporary directory, with the buckets of
+// `setup` created. It is closed and removed when the test ends.
+func NewDB(t testing.TB, setup ...func(*bolt.Tx) error) *bolt.DB {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, f := range setup {
+		if err := db.Update(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return db
+}
+
+// NewBot returns a bot using `db`, logging nowhere
+func NewBot(db *bolt.DB) *bawt.Bot {
+	bot := bawt.New("")
+	bot.DB = db
+	bot.Logging.Logger = logrus.New()
+	bot.Logging.Logger.Out = ioutil.Discard
+
+	return bot
+}
+
+// WebServer stands in for the web server of a bot, authenticating every
+// request as User, if any
+type WebServer struct {
+	User *slack.User
+}
+
+func (s *WebServer) InitWebServer(*bawt.Bot, []string)                                 {}
+func (s *WebServer) RunServer()                                                        {}
+func (s *WebServer) SetAuthMiddleware(func(http.Handler) http.Handler)                 {}
+func (s *WebServer) SetAuthenticatedUserFunc(func(*http.Request) (*slack.User, error)) {}
+func (s *WebServer) PrivateRouter() *mux.Router                                        { return nil }
+func (s *WebServer) PublicRouter() *mux.Router                                         { return nil }
+func (s *WebServer) GetSession(*http.Request) *sessions.Session                        { return nil }
+
+// AuthenticatedUser returns User, or an error without one
+func (s *WebServer) AuthenticatedUser(*http.Request) (*slack.User, error) {
+	if s.User == nil {
+		return nil, errors.New("not logged in")
+	}
+	return s.User, nil
+}
+
+// MakeAdmin adds users to the GlobalAdmins of db
+func MakeAdmin(t testing.TB, db *bolt.DB, users ...string) {
+	t.Helper()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bawt.Groups))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admins := bawt.InternalGroup{Name: "GlobalAdmins", Members: users}
+	if err := admins.Put(db); err != nil {
+		t.Fatal(err)
+	}
+}
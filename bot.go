@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,19 +26,19 @@ var Version string
 // Bot connects Bawt's configuration and API
 type Bot struct {
 	configFile   string
-	Status       Status
+	Status       *Status
 	Config       Config   `json:"Config"`
 	Logging      Logging  `json:"Logging"`
 	GlobalAdmins []string `json:"GlobalAdmins"`
 
 	// Slack connectivity
-	Slack             *slack.Client
-	rtm               *slack.RTM
-	Users             map[string]slack.User
-	Groups            []InternalGroup
-	Channels          map[string]Channel
-	channelUpdateLock sync.Mutex
-	Myself            slack.UserDetails
+	Slack    *slack.Client
+	rtmLock  sync.RWMutex
+	rtm      *slack.RTM
+	Users    *UserDirectory
+	Groups   []InternalGroup
+	Channels *ChannelDirectory
+	Myself   slack.UserDetails
 
 	// Internal handling
 	listeners      []*Listener
@@ -45,6 +46,25 @@ type Bot struct {
 	delListenerCh  chan *Listener
 	outgoingMsgCh  chan *slack.OutgoingMessage
 	outgoingFileCh chan *slack.File
+	incomingEvents chan incomingEvent
+
+	// Connection and resync state, see connection.go
+	stopCh         chan struct{}
+	stopOnce       sync.Once
+	reconnecting   int32
+	connected      int32
+	retrying       int32
+	syncLock       sync.Mutex
+	connLock       sync.Mutex
+	lastSync       time.Time
+	disconnectedAt time.Time
+
+	// Additional workspaces, see workspace.go. `parent` is set on the
+	// Bots serving them.
+	parent         *Bot
+	workspaceID    string
+	workspacesLock sync.RWMutex
+	workspaces     []*Bot
 
 	// Storage
 	DB *bolt.DB
@@ -53,9 +73,12 @@ type Bot struct {
 	// "pluginName:eventType[:someOtherThing]"
 	PubSub *pubsub.PubSub
 
+	// Typed events over PubSub, see events.go
+	Events *EventBus
+
 	// Other features
 	WebServer WebServer
-	Mood      Mood
+	Moods     *MoodEngine
 }
 
 /*
@@ -65,18 +88,29 @@ searches the working directory and $HOME/.bawt/ for a file called
 config.json|toml|yaml instead
 */
 func New(configFile string) *Bot {
+	ps := pubsub.New(500)
+
+	status := NewStatus()
+	status.pubsub = ps
+
+	stopCh := make(chan struct{})
+
 	bot := &Bot{
 		configFile:     configFile,
-		Status:         NewStatus(),
+		Status:         status,
 		outgoingMsgCh:  make(chan *slack.OutgoingMessage, 500),
 		outgoingFileCh: make(chan *slack.File, 500),
+		incomingEvents: make(chan incomingEvent, 50),
+		stopCh:         stopCh,
 		addListenerCh:  make(chan *Listener, 500),
 		delListenerCh:  make(chan *Listener, 500),
 
-		Users:    make(map[string]slack.User),
-		Channels: make(map[string]Channel),
+		Users:    NewUserDirectory(ps),
+		Channels: NewChannelDirectory(ps),
+		Moods:    NewMoodEngine(ps),
 
-		PubSub: pubsub.New(500),
+		PubSub: ps,
+		Events: NewEventBus(ps, stopCh),
 	}
 
 	http.DefaultClient = &http.Client{
@@ -97,6 +131,10 @@ func (bot *Bot) Run() {
 		"config.team_domain",
 		"config.web_base_url",
 		"config.db_path",
+		"config.audit_channel",
+		"config.locale",
+		"config.locales_path",
+		"config.responses_path",
 		"logging.type",
 		"logging.level",
 		"globaladmins",
@@ -127,7 +165,7 @@ func (bot *Bot) Run() {
 	}
 
 	// The above command throws a Fatal if no connection is made
-	bot.Status.Update("db", "ok")
+	bot.Status.Set("db", StateOK, nil)
 
 	defer func() {
 		log.Warnf("Database is closing")
@@ -163,6 +201,36 @@ func (bot *Bot) Run() {
 		log.WithError(err).Fatalf("Unable to create bucket: %s", Groups)
 	}
 
+	// Ensure the audit bucket exists
+	if err = bot.DB.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(AuditBucket))
+		return err
+	}); err != nil {
+		log.WithError(err).Fatalf("Unable to create bucket: %s", AuditBucket)
+	}
+
+	// Translations override the strings registered by the plugins
+	if bot.Config.LocalesPath != "" {
+		if err = Messages().Load(bot.Config.LocalesPath); err != nil {
+			log.WithError(err).Error("Unable to load the translations")
+		}
+	}
+
+	// Response packs replace the lists registered by the plugins
+	if bot.Config.ResponsesPath != "" {
+		if err = ResponseStore().Load(bot.Config.ResponsesPath); err != nil {
+			log.WithError(err).Error("Unable to load the response packs")
+		}
+
+		go ResponseStore().Watch(responsesWatchInterval, bot.stopCh, func(err error) {
+			if err != nil {
+				log.WithError(err).Error("Unable to reload the response packs")
+				return
+			}
+			log.Info("Reloaded the response packs")
+		})
+	}
+
 	// Init all plugins
 	initPlugins(bot)
 
@@ -173,11 +241,13 @@ func (bot *Bot) Run() {
 		bot.Slack = slack.New(bot.Config.APIToken)
 	}
 
-	bot.rtm = bot.Slack.NewRTM()
+	bot.Users.SetFetcher(bot.Slack.GetUserInfo)
+	bot.Channels.SetWorkspace(bot.Config.TeamID)
 
 	bot.setupHandlers()
+	bot.startWorkspaces()
 
-	bot.rtm.ManageConnection()
+	bot.manageConnection()
 }
 
 func (bot *Bot) writePID() error {
@@ -266,33 +336,6 @@ func (bot *Bot) setupHandlers() {
 	log.Info("Startup complete. Bot ready.")
 }
 
-func (bot *Bot) cacheUsers(users []slack.User) {
-	bot.Users = make(map[string]slack.User)
-	for _, user := range users {
-		bot.Users[user.ID] = user
-	}
-}
-
-func (bot *Bot) cacheChannels(channels []slack.Channel, groups []slack.Group, ims []slack.IM) {
-	log := bot.Logging.Logger
-
-	log.Debugf("Channels: %v", len(channels))
-	log.Debugf("Groups: %v", len(groups))
-	log.Debugf("DM's: %v", len(ims))
-	bot.Channels = make(map[string]Channel)
-	for _, channel := range channels {
-		bot.updateChannel(ChannelFromSlackChannel(channel))
-	}
-
-	for _, group := range groups {
-		bot.updateChannel(ChannelFromSlackGroup(group))
-	}
-
-	for _, im := range ims {
-		bot.updateChannel(ChannelFromSlackIM(im))
-	}
-}
-
 // LoadConfig will load configuration from a file or environment variables and populate it into the Bot struct
 func (bot *Bot) LoadConfig(cfg interface{}, envVars ...string) error {
 	log := bot.Logging.Logger
@@ -338,7 +381,12 @@ func (bot *Bot) replyHandler() {
 			continue
 		}
 
-		bot.rtm.SendMessage(outMsg)
+		rtm := bot.currentRTM()
+		if rtm == nil {
+			continue
+		}
+
+		rtm.SendMessage(outMsg)
 
 		time.Sleep(50 * time.Millisecond)
 	}
@@ -402,7 +450,7 @@ func (bot *Bot) SendOutgoingMessage(text string, to string) *Reply {
 		"Message":   text,
 	}).Debug("Sending outgoing message.")
 
-	outMsg := bot.rtm.NewOutgoingMessage(text, to)
+	outMsg := bot.currentRTM().NewOutgoingMessage(text, to)
 	bot.outgoingMsgCh <- outMsg
 
 	return &Reply{outMsg, bot}
@@ -441,7 +489,7 @@ func (bot *Bot) SendPrivateMessage(username, message string) *Reply {
 		"Message":    message,
 	}).Info("Sending private message.")
 
-	outMsg := bot.rtm.NewOutgoingMessage(message, imChannel.ID)
+	outMsg := bot.currentRTM().NewOutgoingMessage(message, imChannel.ID)
 	bot.outgoingMsgCh <- outMsg
 
 	return &Reply{outMsg, bot}
@@ -469,8 +517,8 @@ func (bot *Bot) messageHandler() {
 		case listen := <-bot.delListenerCh:
 			bot.removeListener(listen)
 
-		case event := <-bot.rtm.IncomingEvents:
-			bot.handleRTMEvent(&event)
+		case in := <-bot.incomingEvents:
+			in.bot.handleRTMEvent(&in.event)
 		}
 
 		/*
@@ -499,7 +547,6 @@ The second part of the loop dispatches messages and events to listeners.
 */
 func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 	var msg *Message
-	var client = bot.Slack
 	//var reaction interface{}
 
 	log := bot.Logging.Logger
@@ -520,63 +567,18 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 			"Message":   ev.Msg,
 		}).Error("Real Time Messenger Error.")
 	case *slack.ConnectedEvent:
-		/*
-			We do a series of checks here to make sure that we haven't lost an API.
-			Slack doesn't do a great job of letting us know that an API will no longer
-			be in use.
-		*/
-
-		// Fetch all channels
-		channels, err := client.GetChannels(false)
-		if err != nil {
-			log.WithError(err).Fatal("Unable to fetch channels")
-		}
-
-		// Fetch all Slack groups
-		groups, err := client.GetGroups(false)
-		if err != nil {
-			log.WithError(err).Fatal("Unable to fetch groups")
-		}
-
-		// Fetch all DM's
-		ims, err := client.GetIMChannels()
-		if err != nil {
-			log.WithError(err).Fatal("Unable to fetch IM channels")
-		}
-
-		// Fetch all the users
-		users, err := client.GetUsers()
-		if err != nil {
-			log.WithError(err).Fatal("Unable to fetch users")
-		}
-
-		log.Infof("Bot connected, connection_count=%d", ev.ConnectionCount)
-		bot.Myself = *ev.Info.User
-		bot.cacheUsers(users)                    // Store users
-		bot.cacheChannels(channels, groups, ims) // Store channels
-
-		/*
-			Make sure that at a minimum we are in the channels described in the config. We currently
-			don't sync back the channels the bot was invited to.
-		*/
-
-		for _, channelName := range bot.Config.JoinChannels {
-			channel := bot.GetChannelByName(channelName)
-			if channel != nil && !channel.IsMember {
-				bot.Slack.JoinChannel(channel.ID)
-			}
-		}
-
-		err = bot.Status.Update("chat", "ok")
-		if err != nil {
-			log.WithError(err).Error("Error updating status field. This may result in healthcheck failures.")
-		}
+		bot.onConnected(ev)
 
 	case *slack.DisconnectedEvent:
 		log.Warn("Bot disconnected")
+		bot.onDisconnected()
+		bot.Status.Set(bot.chatComponent(), StateDegraded, fmt.Errorf("disconnected"))
+		bot.publishConnection(ConnectionDisconnected, nil)
 
 	case *slack.InvalidAuthEvent:
 		log.Warn("Received InvalidAuthEvent")
+		bot.Status.Set(bot.chatComponent(), StateFailing, fmt.Errorf("invalid auth"))
+		bot.publishConnection(ConnectionInvalidAuth, fmt.Errorf("invalid auth"))
 
 	case *slack.ConnectingEvent:
 		log.Infof("Bot connecting, connection_count=%d, attempt=%d", ev.ConnectionCount, ev.Attempt)
@@ -596,6 +598,7 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 		msg = &Message{
 			Msg:        &ev.Msg,
 			SubMessage: ev.SubMessage,
+			Workspace:  bot.WorkspaceID(),
 			bot:        bot,
 		}
 
@@ -606,77 +609,92 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 			msg.Msg.Text = ev.SubMessage.Text
 			msg.IsEdit = true
 		case "channel_topic":
-			if channel, ok := bot.Channels[ev.Channel]; ok {
+			bot.Channels.Modify(ev.Channel, func(channel *Channel) {
 				channel.Topic = slack.Topic{
 					Value:   ev.Topic,
 					Creator: ev.User,
 					LastSet: unixFromTimestamp(ev.Timestamp),
 				}
-				bot.Channels[ev.Channel] = channel
-			}
+			})
 		case "channel_purpose":
-			if channel, ok := bot.Channels[ev.Channel]; ok {
+			bot.Channels.Modify(ev.Channel, func(channel *Channel) {
 				channel.Purpose = slack.Purpose{
 					Value:   ev.Purpose,
 					Creator: ev.User,
 					LastSet: unixFromTimestamp(ev.Timestamp),
 				}
-				bot.Channels[ev.Channel] = channel
-			}
+			})
 		}
 
-		// Verify the UserMap
-		user, ok := bot.Users[userID]
-		if ok {
-			log.Debug("User map is ok.")
-			msg.FromUser = &user
-		} else if ev.Msg.SubType != "bot_message" { // Bot users don't get UID's so don't put them in the user map
-			log.WithFields(logrus.Fields{
-				"Type":    "BrokenUserMap",
-				"SubType": ev.Msg.SubType,
-				"Users":   len(bot.Users),
-				"User":    userID,
-			}).Error("UserMap is broken, unknown SubType.")
+		// Look the user up, and fetch it from Slack if we don't know it yet.
+		// Bot users don't get UID's so don't look them up.
+		if userID != "" && ev.Msg.SubType != "bot_message" {
+			user, err := bot.Users.Fetch(userID)
+			if err == nil {
+				msg.FromUser = &user
+			} else {
+				log.WithError(err).WithFields(logrus.Fields{
+					"Type":    "UnknownUser",
+					"SubType": ev.Msg.SubType,
+					"Users":   bot.Users.Len(),
+					"User":    userID,
+				}).Error("Unable to fetch user.")
+			}
 		}
 
 		// Verify the ChannelMap
-		channel, ok := bot.Channels[ev.Channel]
+		channel, ok := bot.Channels.Get(ev.Channel)
 		if ok {
 			log.Debug("Channel map is ok.")
 			msg.FromChannel = &channel
 		} else {
 			log.WithFields(logrus.Fields{
 				"Type":     "BrokenChannelMap",
-				"Channels": len(bot.Channels),
+				"Channels": bot.Channels.Len(),
 			}).Error("Channel map is broken.")
 		}
 
 		msg.applyMentionsMe(bot)
 		msg.applyFromMe(bot)
 
+		// A copy, as listeners set `Match` on theirs
+		received := *msg
+		bot.publish(TopicMessageReceived, &received)
+
+	case *slack.ReactionAddedEvent:
+		bot.publish(TopicReactionAdded, ParseReactionEvent(ev))
+
+	case *slack.ReactionRemovedEvent:
+		bot.publish(TopicReactionRemoved, ParseReactionEvent(ev))
+
 	case *slack.PresenceChangeEvent:
-		user := bot.Users[ev.User]
-		log.Infof("User %q is now %q", user.Name, ev.Presence)
-		user.Presence = ev.Presence
+		bot.Users.Modify(ev.User, func(user *slack.User) {
+			log.Infof("User %q is now %q", user.Name, ev.Presence)
+			user.Presence = ev.Presence
+		})
 
 	/*
 		User changes
 	*/
 
 	case *slack.UserChangeEvent:
-		bot.Users[ev.User.ID] = ev.User
+		bot.Users.Set(ev.User)
+
+	case *slack.TeamJoinEvent:
+		bot.Users.Set(ev.User)
+		bot.publish(TopicUserJoined, ev.User)
 
 	/*
 		Handle slack Channel changes
 	*/
 
 	case *slack.ChannelRenameEvent:
-		channel := bot.Channels[ev.Channel.ID]
-		channel.Name = ev.Channel.Name
-		bot.updateChannel(channel)
+		bot.Channels.Modify(ev.Channel.ID, func(channel *Channel) {
+			channel.Name = ev.Channel.Name
+		})
 
 	case *slack.ChannelJoinedEvent:
-		bot.updateChannel(ChannelFromSlackChannel(ev.Channel))
+		bot.Channels.Set(ChannelFromSlackChannel(ev.Channel))
 
 	case *slack.ChannelCreatedEvent:
 		c := Channel{}
@@ -684,32 +702,35 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 		c.Name = ev.Channel.Name
 		c.Creator = ev.Channel.Creator
 		c.IsChannel = true
-		bot.updateChannel(c)
+		bot.Channels.Set(c)
+		bot.publishChannel(TopicChannelCreated, c.ID)
 
 	case *slack.ChannelDeletedEvent:
-		bot.deleteChannel(ev.Channel)
+		bot.Channels.Delete(ev.Channel)
 
 	case *slack.ChannelArchiveEvent:
-		channel := bot.Channels[ev.Channel]
-		channel.IsArchived = true
-		bot.updateChannel(channel)
+		bot.Channels.Modify(ev.Channel, func(channel *Channel) {
+			channel.IsArchived = true
+		})
+		bot.publishChannel(TopicChannelArchived, ev.Channel)
 
 	case *slack.ChannelUnarchiveEvent:
-		channel := bot.Channels[ev.Channel]
-		channel.IsArchived = false
-		bot.updateChannel(channel)
+		bot.Channels.Modify(ev.Channel, func(channel *Channel) {
+			channel.IsArchived = false
+		})
+		bot.publishChannel(TopicChannelUnarchived, ev.Channel)
 
 	/*
 		Handle slack Group changes
 	*/
 
 	case *slack.GroupRenameEvent:
-		group := bot.Channels[ev.Group.ID]
-		group.Name = ev.Group.Name
-		bot.updateChannel(group)
+		bot.Channels.Modify(ev.Group.ID, func(group *Channel) {
+			group.Name = ev.Group.Name
+		})
 
 	case *slack.GroupJoinedEvent:
-		bot.updateChannel(ChannelFromSlackChannel(ev.Channel))
+		bot.Channels.Set(ChannelFromSlackChannel(ev.Channel))
 
 	case *slack.GroupCreatedEvent:
 		c := Channel{}
@@ -717,20 +738,23 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 		c.Name = ev.Channel.Name
 		c.Creator = ev.Channel.Creator
 		c.IsGroup = true
-		bot.updateChannel(c)
+		bot.Channels.Set(c)
+		bot.publishChannel(TopicChannelCreated, c.ID)
 
 	case *slack.GroupCloseEvent:
-		bot.deleteChannel(ev.Channel)
+		bot.Channels.Delete(ev.Channel)
 
 	case *slack.GroupArchiveEvent:
-		group := bot.Channels[ev.Channel]
-		group.IsArchived = true
-		bot.updateChannel(group)
+		bot.Channels.Modify(ev.Channel, func(group *Channel) {
+			group.IsArchived = true
+		})
+		bot.publishChannel(TopicChannelArchived, ev.Channel)
 
 	case *slack.GroupUnarchiveEvent:
-		group := bot.Channels[ev.Channel]
-		group.IsArchived = false
-		bot.updateChannel(group)
+		bot.Channels.Modify(ev.Channel, func(group *Channel) {
+			group.IsArchived = false
+		})
+		bot.publishChannel(TopicChannelUnarchived, ev.Channel)
 
 	/*
 		Handle slack IM changes
@@ -741,17 +765,17 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 		c.ID = ev.Channel.ID
 		c.User = ev.User
 		c.IsIM = true
-		bot.updateChannel(c)
+		bot.Channels.Set(c)
 
 	case *slack.IMOpenEvent:
 		c := Channel{}
 		c.ID = ev.Channel
 		c.User = ev.User
 		c.IsIM = true
-		bot.updateChannel(c)
+		bot.Channels.Set(c)
 
 	case *slack.IMCloseEvent:
-		bot.deleteChannel(ev.Channel)
+		bot.Channels.Delete(ev.Channel)
 
 	/*
 		Errors
@@ -763,43 +787,62 @@ func (bot *Bot) handleRTMEvent(event *slack.RTMEvent) {
 
 	case *slack.ConnectionErrorEvent:
 		log.Warnf("ConnectionErrorEvent: %s", ev)
+		bot.onDisconnected()
+		bot.Status.Set(bot.chatComponent(), StateDegraded, ev.ErrorObj)
+		bot.publishConnection(ConnectionError, ev.ErrorObj)
 
 	default:
 		log.Debugf("Unhandled Event: %T", ev)
 	}
 
-	// Dispatch listeners
-	for _, listen := range bot.listeners {
-		if msg != nil && listen.MessageHandlerFunc != nil {
-			listen.filterAndDispatchMessage(msg)
+	// Dispatch listeners, which are all registered on the main workspace
+	for _, listen := range bot.root().listeners {
+		if listen.MainWorkspaceOnly && bot.parent != nil {
+			continue
 		}
+		bot.dispatch(listen, msg, event)
+	}
+}
 
-		if listen.EventHandlerFunc != nil {
-			var handleEvent interface{} = event.Data
-			if msg != nil {
-				handleEvent = msg
-			}
-			listen.EventHandlerFunc(listen, handleEvent)
+// dispatch hands an event to a listener. A panicking listener is
+// reported as a plugin error instead of taking the bot down.
+func (bot *Bot) dispatch(listen *Listener, msg *Message, event *slack.RTMEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("panic in listener: %v", r)
+			bot.Logging.Logger.WithError(err).WithField("plugin", listen.Name).Errorf("Listener panicked\n%s", debug.Stack())
+			bot.publish(TopicPluginError, PluginError{Plugin: listen.Name, Workspace: bot.WorkspaceID(), Err: err, Panic: true})
 		}
+	}()
+
+	if msg != nil && listen.MessageHandlerFunc != nil {
+		listen.filterAndDispatchMessage(msg)
 	}
 
+	if listen.EventHandlerFunc != nil {
+		var handleEvent interface{} = event.Data
+		if msg != nil {
+			handleEvent = msg
+		}
+		listen.EventHandlerFunc(listen, handleEvent)
+	}
 }
 
-// Disconnect the websocket.
-func (bot *Bot) Disconnect() {
-	// FIXME: implement a Reconnect() method.. calling the RTM method of the same name.
-	// QUERYME: do we need that, really ?
-	bot.rtm.Disconnect()
+// publishChannel publishes the cached state of a channel
+func (bot *Bot) publishChannel(topic, id string) {
+	if channel, ok := bot.Channels.Get(id); ok {
+		bot.publish(topic, channel)
+	}
 }
 
-// GetUser returns a *slack.User by ID, Name, RealName or Email
+// GetUser returns a *slack.User by ID, Name, Email, DisplayName or
+// RealName. The returned user is a copy.
 func (bot *Bot) GetUser(find string) *slack.User {
-	for _, user := range bot.Users {
-		if user.Profile.Email == find || user.ID == find || user.Name == find || user.RealName == find {
-			return &user
-		}
+	user, ok := bot.Users.Find(find)
+	if !ok {
+		return nil
 	}
-	return nil
+	return &user
 }
 
 // GetGroup retrieves a group from BoltDB
@@ -808,28 +851,23 @@ func (bot *Bot) GetGroup(name string) *InternalGroup {
 	return nil
 }
 
-// GetChannelByName returns a *slack.Channel by Name
+// GetChannelByName returns a *slack.Channel by Name. The returned
+// channel is a copy.
 func (bot *Bot) GetChannelByName(name string) *Channel {
-	name = strings.TrimLeft(name, "#")
-	for _, channel := range bot.Channels {
-		if channel.Name == name {
-			return &channel
-		}
+	channel, ok := bot.Channels.ByName(name)
+	if !ok {
+		return nil
 	}
-	return nil
+	return &channel
 }
 
 // GetIMChannelWith returns the channel used to communicate with the specified slack user
 func (bot *Bot) GetIMChannelWith(user *slack.User) *Channel {
-	for _, channel := range bot.Channels {
-		if !channel.IsIM {
-			continue
-		}
-		if channel.User == user.ID {
-			return &channel
-		}
+	channel, ok := bot.Channels.IMWith(user.ID)
+	if !ok {
+		return nil
 	}
-	return nil
+	return &channel
 }
 
 // OpenIMChannelWith opens a conversation with the given slack User
@@ -850,23 +888,11 @@ func (bot *Bot) OpenIMChannelWith(user *slack.User) *Channel {
 		IsIM: true,
 		User: user.ID,
 	}
-	bot.updateChannel(c)
+	bot.Channels.Set(c)
 
 	return &c
 }
 
-func (bot *Bot) updateChannel(channel Channel) {
-	bot.channelUpdateLock.Lock()
-	bot.Channels[channel.ID] = channel
-	bot.channelUpdateLock.Unlock()
-}
-
-func (bot *Bot) deleteChannel(id string) {
-	bot.channelUpdateLock.Lock()
-	delete(bot.Channels, id)
-	bot.channelUpdateLock.Unlock()
-}
-
 func (bot *Bot) setupDB() (*bolt.DB, error) {
 	log := bot.Logging.Logger
 
@@ -891,17 +917,30 @@ func (bot *Bot) setupDB() (*bolt.DB, error) {
 	return db, nil
 }
 
-// NormalizeID normalizes slack user and channel ID's
+// NormalizeID normalizes slack user and channel ID's: a user mention
+// gives the user's ID, and a channel mention `#` with the channel's name,
+// or its ID without a name. Anything else is returned as is.
 func NormalizeID(id string) string {
-	if strings.HasPrefix(id, "<@") {
-		id = strings.TrimLeft(id, "<@")
-	} else {
-		// a channel
-		id = strings.Split(id, "|")[1]
-		id = fmt.Sprintf("#%s", id)
+	switch {
+	case strings.HasPrefix(id, "<@"):
+		return CleanUser(id)
+	case strings.HasPrefix(id, "<#"):
+		parts := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(id, "<#"), ">"), "|", 2)
+		if len(parts) == 2 && parts[1] != "" {
+			return "#" + parts[1]
+		}
+		return parts[0]
 	}
 
-	id = strings.TrimRight(id, ">")
-
 	return id
 }
+
+// CleanUser turns a user mention, `<@U1234>` or `<@U1234|bob>`, into the
+// user's ID, and `@bob` into `bob`
+func CleanUser(value string) string {
+	if strings.HasPrefix(value, "<@") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "<@"), ">")
+		return strings.SplitN(value, "|", 2)[0]
+	}
+	return strings.TrimPrefix(value, "@")
+}
@@ -21,8 +21,8 @@ func TestNew(t *testing.T) {
 	assert.Empty(t, a.outgoingFileCh)
 	assert.Empty(t, a.addListenerCh)
 	assert.Empty(t, a.delListenerCh)
-	assert.Empty(t, a.Users)
-	assert.Empty(t, a.Channels)
+	assert.Equal(t, 0, a.Users.Len())
+	assert.Equal(t, 0, a.Channels.Len())
 	assert.NotEmpty(t, a.PubSub)
 
 	// Test storing an empty config
@@ -34,8 +34,8 @@ func TestNew(t *testing.T) {
 	assert.Empty(t, a.outgoingFileCh)
 	assert.Empty(t, a.addListenerCh)
 	assert.Empty(t, a.delListenerCh)
-	assert.Empty(t, a.Users)
-	assert.Empty(t, a.Channels)
+	assert.Equal(t, 0, a.Users.Len())
+	assert.Equal(t, 0, a.Channels.Len())
 	assert.NotEmpty(t, a.PubSub)
 }
 
@@ -68,3 +68,28 @@ func TestBot_readInConfig(t *testing.T) {
 	assert.NotEmpty(t, bot.Config.PIDPath)
 	assert.Equal(t, e, bot.Config.PIDPath)
 }
+
+func TestNormalizeID(t *testing.T) {
+	for in, out := range map[string]string{
+		"<@U1234>":         "U1234",
+		"<@U1234|bob>":     "U1234",
+		"<#C1234|general>": "#general",
+		"<#C1234>":         "C1234",
+		"#general":         "#general",
+		"bob":              "bob",
+		"":                 "",
+	} {
+		assert.Equal(t, out, NormalizeID(in), in)
+	}
+}
+
+func TestCleanUser(t *testing.T) {
+	for in, out := range map[string]string{
+		"<@U1234>":     "U1234",
+		"<@U1234|bob>": "U1234",
+		"@bob":         "bob",
+		"bob":          "bob",
+	} {
+		assert.Equal(t, out, CleanUser(in), in)
+	}
+}
@@ -36,6 +36,9 @@ type Channel struct {
 	// Only for `IsChannel || IsGroup`
 	Topic   slack.Topic
 	Purpose slack.Purpose
+
+	// Workspace is the team ID of the workspace the channel belongs to
+	Workspace string
 }
 
 // ChannelFromSlackGroup converts a slack group to a Channel Struct
@@ -86,3 +89,36 @@ func ChannelFromSlackIM(im slack.IM) Channel {
 		IsIM:          true,
 	}
 }
+
+// ChannelFromConversation converts a conversation, as returned by
+// `conversations.list`, to a Channel Struct. Private channels and
+// multi-party IMs are reported as groups.
+func ChannelFromConversation(c slack.Channel) Channel {
+	channel := Channel{
+		ID:         c.ID,
+		Created:    c.Created.Time(),
+		IsOpen:     c.IsOpen,
+		LastRead:   c.LastRead,
+		Name:       c.Name,
+		Creator:    c.Creator,
+		Members:    c.Members,
+		IsGeneral:  c.IsGeneral,
+		IsMember:   c.IsMember,
+		IsArchived: c.IsArchived,
+		Topic:      c.Topic,
+		Purpose:    c.Purpose,
+	}
+
+	switch {
+	case c.IsIM:
+		channel.Name = c.User
+		channel.User = c.User
+		channel.IsIM = true
+	case c.IsPrivate || c.IsGroup || c.IsMpIM:
+		channel.IsGroup = true
+	default:
+		channel.IsChannel = true
+	}
+
+	return channel
+}
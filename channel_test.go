@@ -154,3 +154,35 @@ func TestChannelFromSlackIM(t *testing.T) {
 
 	assertChannelFromSlackIM(t, *slackIM, channel)
 }
+
+func TestChannelFromConversation(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		channel bool
+		group   bool
+		im      bool
+	}{
+		{"public", `{"id": "C1", "name": "fun", "is_channel": true, "is_member": true}`, true, false, false},
+		{"private", `{"id": "C2", "name": "secret", "is_channel": true, "is_private": true}`, false, true, false},
+		{"mpim", `{"id": "G1", "name": "mpdm-a--b", "is_mpim": true}`, false, true, false},
+		{"im", `{"id": "D1", "is_im": true, "user": "U1"}`, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversation, err := unmarshalChannel(tt.json)
+			assert.Nil(t, err)
+
+			channel := ChannelFromConversation(*conversation)
+			assert.Equal(t, conversation.ID, channel.ID)
+			assert.Equal(t, tt.channel, channel.IsChannel)
+			assert.Equal(t, tt.group, channel.IsGroup)
+			assert.Equal(t, tt.im, channel.IsIM)
+			if tt.im {
+				assert.Equal(t, "U1", channel.User)
+				assert.Equal(t, "U1", channel.Name)
+			}
+		})
+	}
+}
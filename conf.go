@@ -10,4 +10,16 @@ type Config struct {
 	WebBaseURL     string   `json:"web_base_url" mapstructure:"web_base_url"`
 	DBPath         string   `json:"db_path" mapstructure:"db_path"`
 	PIDPath        string   `json:"pid_path" mapstructure:"pid_path"`
+	AuditChannel   string   `json:"audit_channel" mapstructure:"audit_channel"`
+
+	// Localization, see i18n.go
+	Locale         string            `json:"locale" mapstructure:"locale"`
+	LocalesPath    string            `json:"locales_path" mapstructure:"locales_path"`
+	ChannelLocales map[string]string `json:"channel_locales" mapstructure:"channel_locales"`
+
+	// Directory of response packs, see responses.go
+	ResponsesPath string `json:"responses_path" mapstructure:"responses_path"`
+
+	// Additional Slack workspaces, see workspace.go
+	Workspaces []WorkspaceConfig `json:"workspaces" mapstructure:"workspaces"`
 }
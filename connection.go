@@ -0,0 +1,439 @@
+package bawt
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+const (
+	// minBackoff and maxBackoff bound the delay between two attempts to
+	// reconnect or to resync with Slack
+	minBackoff = 2 * time.Second
+	maxBackoff = 5 * time.Minute
+
+	// fullResyncAfter is the outage duration after which all the users and
+	// conversations are refetched on reconnect. Shorter outages only
+	// refresh the conversations the bot is in.
+	fullResyncAfter = 5 * time.Minute
+
+	// syncPageSize is the page size used with `conversations.list`,
+	// `users.conversations` and `users.list`
+	syncPageSize = 200
+)
+
+// backoff computes exponential delays with jitter
+type backoff struct {
+	min, max time.Duration
+	attempts uint
+}
+
+// Next returns the delay to wait before the next attempt
+func (b *backoff) Next() time.Duration {
+	d := b.min << b.attempts
+	if d > b.max || d <= 0 {
+		d = b.max
+	} else {
+		b.attempts++
+	}
+
+	// Up to 25% of jitter, so a fleet of bots doesn't retry in lockstep
+	return d - time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// Reset starts over from the minimum delay
+func (b *backoff) Reset() {
+	b.attempts = 0
+}
+
+/*
+Reconnect closes the current websocket and opens a new one right away.
+The caches are kept, and only what may have changed during the outage is
+resynced once connected again.
+*/
+func (bot *Bot) Reconnect() {
+	rtm := bot.currentRTM()
+	if rtm == nil {
+		return
+	}
+
+	atomic.StoreInt32(&bot.reconnecting, 1)
+	rtm.Disconnect()
+}
+
+// Disconnect closes the websockets of all the workspaces for good, which
+// makes `Run` return.
+func (bot *Bot) Disconnect() {
+	root := bot.root()
+	root.stopOnce.Do(func() { close(root.stopCh) })
+
+	for _, ws := range root.Workspaces() {
+		if rtm := ws.currentRTM(); rtm != nil {
+			rtm.Disconnect()
+		}
+	}
+}
+
+func (bot *Bot) currentRTM() *slack.RTM {
+	bot.rtmLock.RLock()
+	defer bot.rtmLock.RUnlock()
+
+	return bot.rtm
+}
+
+func (bot *Bot) stopped() bool {
+	select {
+	case <-bot.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+manageConnection runs the RTM connections until Disconnect is called.
+The slack library reconnects on its own after network errors, but gives
+up on fatal errors such as an invalid token; a new connection is then
+attempted with backoff, while the bot keeps serving from its caches.
+*/
+func (bot *Bot) manageConnection() {
+	log := bot.Logging.Logger
+	b := &backoff{min: minBackoff, max: maxBackoff}
+
+	for {
+		rtm := bot.Slack.NewRTM()
+
+		bot.rtmLock.Lock()
+		bot.rtm = rtm
+		bot.rtmLock.Unlock()
+
+		done := make(chan struct{})
+		go bot.forwardEvents(rtm, done)
+
+		started := time.Now()
+		rtm.ManageConnection()
+		close(done)
+
+		if bot.stopped() {
+			return
+		}
+
+		if atomic.CompareAndSwapInt32(&bot.reconnecting, 1, 0) {
+			log.Info("Reconnecting to Slack")
+			b.Reset()
+			continue
+		}
+
+		if time.Since(started) > maxBackoff {
+			b.Reset()
+		}
+
+		wait := b.Next()
+		log.Warnf("Connection to Slack lost, retrying in %s", wait)
+
+		select {
+		case <-time.After(wait):
+		case <-bot.stopCh:
+			return
+		}
+	}
+}
+
+// forwardEvents copies the events of one RTM connection to the bot's
+// event loop, until `done` is closed
+func (bot *Bot) forwardEvents(rtm *slack.RTM, done chan struct{}) {
+	for {
+		select {
+		case event := <-rtm.IncomingEvents:
+			bot.incomingEvents <- incomingEvent{bot, event}
+		case <-done:
+			// Flush what was emitted while the connection was closing
+			for {
+				select {
+				case event := <-rtm.IncomingEvents:
+					bot.incomingEvents <- incomingEvent{bot, event}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// onConnected resyncs the caches once connected, away from the event
+// loop. On failure, the bot keeps serving from the last caches in
+// degraded mode and retries in the background.
+func (bot *Bot) onConnected(ev *slack.ConnectedEvent) {
+	log := bot.Logging.Logger
+
+	log.Infof("Bot connected, connection_count=%d", ev.ConnectionCount)
+	bot.Myself = *ev.Info.User
+	atomic.StoreInt32(&bot.connected, 1)
+
+	if ev.Info.Team != nil && ev.Info.Team.ID != "" {
+		bot.connLock.Lock()
+		bot.workspaceID = ev.Info.Team.ID
+		bot.connLock.Unlock()
+		bot.Channels.SetWorkspace(ev.Info.Team.ID)
+	}
+
+	bot.publishConnection(ConnectionConnected, nil)
+
+	if atomic.LoadInt32(&bot.retrying) == 1 {
+		// The background retry will pick it up
+		return
+	}
+
+	go bot.resyncConnected()
+}
+
+// resyncConnected runs the resync following a connection
+func (bot *Bot) resyncConnected() {
+	log := bot.Logging.Logger
+
+	if err := bot.resync(bot.needsFullResync()); err != nil {
+		log.WithError(err).Error("Unable to resync with Slack, serving from the cache")
+		bot.Status.Set(bot.chatComponent(), StateDegraded, fmt.Errorf("resync failed: %s", err))
+
+		go bot.retryResync()
+		return
+	}
+
+	bot.synced()
+}
+
+// onDisconnected records the start of an outage
+func (bot *Bot) onDisconnected() {
+	atomic.StoreInt32(&bot.connected, 0)
+
+	bot.connLock.Lock()
+	if bot.disconnectedAt.IsZero() {
+		bot.disconnectedAt = time.Now()
+	}
+	bot.connLock.Unlock()
+}
+
+// needsFullResync tells whether the users and all the conversations must
+// be refetched: on the first sync, and after long outages
+func (bot *Bot) needsFullResync() bool {
+	bot.connLock.Lock()
+	defer bot.connLock.Unlock()
+
+	if bot.lastSync.IsZero() || bot.Users.Len() == 0 {
+		return true
+	}
+
+	return !bot.disconnectedAt.IsZero() && time.Since(bot.disconnectedAt) > fullResyncAfter
+}
+
+func (bot *Bot) retryResync() {
+	if !atomic.CompareAndSwapInt32(&bot.retrying, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&bot.retrying, 0)
+
+	log := bot.Logging.Logger
+	b := &backoff{min: minBackoff, max: maxBackoff}
+
+	for {
+		select {
+		case <-time.After(b.Next()):
+		case <-bot.stopCh:
+			return
+		}
+
+		if err := bot.resync(bot.needsFullResync()); err != nil {
+			log.WithError(err).Warn("Resync with Slack failed again")
+			continue
+		}
+
+		bot.synced()
+		return
+	}
+}
+
+// synced makes sure we are in the configured channels and reports the
+// chat as healthy, unless the websocket went away in the meantime
+func (bot *Bot) synced() {
+	log := bot.Logging.Logger
+
+	/*
+		Make sure that at a minimum we are in the channels described in the config. We currently
+		don't sync back the channels the bot was invited to.
+	*/
+
+	for _, channelName := range bot.Config.JoinChannels {
+		channel := bot.GetChannelByName(channelName)
+		if channel != nil && !channel.IsMember {
+			bot.Slack.JoinChannel(channel.ID)
+		}
+	}
+
+	if atomic.LoadInt32(&bot.connected) == 0 {
+		return
+	}
+
+	err := bot.Status.Set(bot.chatComponent(), StateOK, nil)
+	if err != nil {
+		log.WithError(err).Error("Error updating status field. This may result in healthcheck failures.")
+	}
+}
+
+/*
+resync refreshes the channel and user caches. When `full` is set, all the
+conversations and users are refetched, otherwise only the conversations
+the bot is in, whose events may have been missed during the outage. Only
+the differences are applied to the directories. The first sync replaces
+them wholesale.
+*/
+func (bot *Bot) resync(full bool) error {
+	log := bot.Logging.Logger
+
+	bot.syncLock.Lock()
+	defer bot.syncLock.Unlock()
+
+	bot.connLock.Lock()
+	first := bot.lastSync.IsZero()
+	bot.connLock.Unlock()
+
+	ctx := context.Background()
+
+	if !full {
+		channels, err := bot.fetchMemberships(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching conversations: %s", err)
+		}
+
+		changed, left := bot.Channels.SyncMemberships(channels)
+		log.Infof("Resynced the bot's channels, %d changed, %d left", changed, left)
+	} else {
+		channels, err := bot.fetchConversations(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching conversations: %s", err)
+		}
+
+		users, err := bot.fetchUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching users: %s", err)
+		}
+
+		if first {
+			bot.Channels.Replace(channels)
+			bot.Users.Replace(users)
+			log.Infof("Cached %d channels and %d users", bot.Channels.Len(), bot.Users.Len())
+		} else {
+			changed, removed := bot.Channels.Sync(channels)
+			log.Infof("Resynced channels, %d changed, %d removed", changed, removed)
+
+			changed, removed = bot.Users.Sync(users)
+			log.Infof("Resynced users, %d changed, %d removed", changed, removed)
+		}
+	}
+
+	bot.connLock.Lock()
+	bot.lastSync = time.Now()
+	bot.disconnectedAt = time.Time{}
+	bot.connLock.Unlock()
+
+	return nil
+}
+
+// fetchConversations lists all the channels, groups and IMs, page by page
+func (bot *Bot) fetchConversations(ctx context.Context) ([]Channel, error) {
+	var out []Channel
+
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: "false",
+		Limit:           syncPageSize,
+		Types:           []string{"public_channel", "private_channel", "mpim", "im"},
+	}
+
+	for {
+		page, cursor, err := bot.Slack.GetConversationsContext(ctx, params)
+		if err != nil {
+			if waitRateLimit(ctx, err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, c := range page {
+			out = append(out, ChannelFromConversation(c))
+		}
+
+		if cursor == "" {
+			return out, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// fetchMemberships lists the conversations the bot is in, page by page
+func (bot *Bot) fetchMemberships(ctx context.Context) ([]Channel, error) {
+	var out []Channel
+
+	params := &slack.GetConversationsForUserParameters{
+		Limit: syncPageSize,
+		Types: []string{"public_channel", "private_channel", "mpim", "im"},
+	}
+
+	for {
+		page, cursor, err := bot.Slack.GetConversationsForUserContext(ctx, params)
+		if err != nil {
+			if waitRateLimit(ctx, err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, c := range page {
+			channel := ChannelFromConversation(c)
+			channel.IsMember = !channel.IsIM
+			out = append(out, channel)
+		}
+
+		if cursor == "" {
+			return out, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// fetchUsers lists all the users, page by page
+func (bot *Bot) fetchUsers(ctx context.Context) ([]slack.User, error) {
+	var out []slack.User
+
+	p := bot.Slack.GetUsersPaginated(slack.GetUsersOptionLimit(syncPageSize))
+	for {
+		next, err := p.Next(ctx)
+		if err != nil {
+			if waitRateLimit(ctx, err) {
+				continue
+			}
+			return out, p.Failure(err)
+		}
+
+		p = next
+		out = append(out, p.Users...)
+	}
+}
+
+// waitRateLimit sleeps for the delay requested by Slack when `err` is a
+// rate limit, and tells whether the call should be retried
+func waitRateLimit(ctx context.Context, err error) bool {
+	rle, ok := err.(*slack.RateLimitedError)
+	if !ok {
+		return false
+	}
+
+	select {
+	case <-time.After(rle.RetryAfter):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
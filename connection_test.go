@@ -0,0 +1,50 @@
+package bawt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	b := &backoff{min: time.Second, max: 10 * time.Second}
+
+	var last time.Duration
+	for i := 0; i < 10; i++ {
+		d := b.Next()
+		assert.True(t, d > 0)
+		assert.True(t, d <= 10*time.Second)
+		last = d
+	}
+	assert.True(t, last >= 7*time.Second, "should settle around the max, got %s", last)
+
+	b.Reset()
+	d := b.Next()
+	assert.True(t, d <= time.Second)
+	assert.True(t, d >= 750*time.Millisecond)
+}
+
+func TestBot_NeedsFullResync(t *testing.T) {
+	bot := New("")
+	assert.True(t, bot.needsFullResync())
+
+	bot.Users.Set(slack.User{ID: "U1"})
+	bot.lastSync = time.Now()
+	assert.False(t, bot.needsFullResync())
+
+	bot.onDisconnected()
+	assert.False(t, bot.needsFullResync())
+
+	bot.disconnectedAt = time.Now().Add(-2 * fullResyncAfter)
+	assert.True(t, bot.needsFullResync())
+}
+
+func TestBot_DisconnectBeforeRun(t *testing.T) {
+	bot := New("")
+	bot.Disconnect()
+	bot.Disconnect()
+	bot.Reconnect()
+	assert.True(t, bot.stopped())
+}
@@ -1,6 +1,7 @@
 package bawt
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +34,27 @@ func AfterNextWeekdayTime(from time.Time, w time.Weekday, hour, min int) <-chan
 	return time.After(duration)
 }
 
+// ParseSince returns the time meant by a date (`2006-01-02`, in the
+// location of `now`), a number of days (`7d`) or a duration (`24h`) ago
+func ParseSince(input string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(input, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(input, "d"))
+		if err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(input); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date: %s, use YYYY-MM-DD, a number of days like 7d or a duration like 24h", input)
+}
+
 // unixFromTimestamp from an slack unique (per-channel) timestamp
 // returns the unix timestamp.
 func unixFromTimestamp(ts string) slack.JSONTime {
@@ -65,3 +65,22 @@ func TestShouldReturnCeroIfGivenTimestampIsInvalid(t *testing.T) {
 
 	assert.Equal(t, expectedTs, unixTs)
 }
+
+func TestShouldParseSinceDatesDaysAndDurations(t *testing.T) {
+	now := time.Date(2018, 10, 23, 12, 0, 0, 0, time.UTC)
+
+	since, err := ParseSince("7d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 10, 16, 12, 0, 0, 0, time.UTC), since)
+
+	since, err = ParseSince("2h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 10, 23, 10, 0, 0, 0, time.UTC), since)
+
+	since, err = ParseSince("2018-10-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), since)
+
+	_, err = ParseSince("yesterday", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,568 @@
+package bawt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/cskr/pubsub"
+	"github.com/nlopes/slack"
+)
+
+// PubSub topics published by the user and channel directories. The
+// payload is the new `slack.User` or `Channel`, except for deletions
+// which carry the ID, and reloads which carry the number of entries.
+const (
+	TopicUserChanged     = "bawt:user:changed"
+	TopicUserDeleted     = "bawt:user:deleted"
+	TopicUsersReloaded   = "bawt:users:reloaded"
+	TopicChannelChanged  = "bawt:channel:changed"
+	TopicChannelDeleted  = "bawt:channel:deleted"
+	TopicChannelReloaded = "bawt:channels:reloaded"
+)
+
+// UserFetcher retrieves a single user from Slack, see
+// `slack.Client.GetUserInfo`
+type UserFetcher func(id string) (*slack.User, error)
+
+// UserDirectory is a thread-safe cache of the Slack users, indexed by ID,
+// name, email, display name and real name. All lookups return copies.
+type UserDirectory struct {
+	lock    sync.RWMutex
+	byID    map[string]slack.User
+	indexes map[string]map[string]string // [index][lowercase key] = ID
+
+	fetch  UserFetcher
+	pubsub *pubsub.PubSub
+}
+
+// NewUserDirectory returns an empty directory publishing its changes
+// on `ps`, which can be nil
+func NewUserDirectory(ps *pubsub.PubSub) *UserDirectory {
+	d := &UserDirectory{pubsub: ps}
+	d.reset()
+	return d
+}
+
+// SetFetcher sets the function used by Fetch for unknown users
+func (d *UserDirectory) SetFetcher(f UserFetcher) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.fetch = f
+}
+
+// Get returns the user with the given ID
+func (d *UserDirectory) Get(id string) (slack.User, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	user, ok := d.byID[id]
+	return user, ok
+}
+
+// Find returns a user by ID, name, email, display name or real name,
+// in that order. Lookups other than the ID are case-insensitive.
+func (d *UserDirectory) Find(query string) (slack.User, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	if user, ok := d.byID[query]; ok {
+		return user, true
+	}
+
+	key := strings.ToLower(query)
+	for _, index := range userIndexes {
+		if id, ok := d.indexes[index][key]; ok {
+			return d.byID[id], true
+		}
+	}
+
+	return slack.User{}, false
+}
+
+// Fetch returns the user with the given ID, asking Slack for it when it
+// isn't known yet. Fetched users are added to the directory.
+func (d *UserDirectory) Fetch(id string) (slack.User, error) {
+	if user, ok := d.Get(id); ok {
+		return user, nil
+	}
+
+	d.lock.RLock()
+	fetch := d.fetch
+	d.lock.RUnlock()
+
+	if fetch == nil {
+		return slack.User{}, fmt.Errorf("unknown user %s", id)
+	}
+
+	user, err := fetch(id)
+	if err != nil {
+		return slack.User{}, err
+	}
+
+	d.Set(*user)
+
+	return *user, nil
+}
+
+// Set adds or replaces a user
+func (d *UserDirectory) Set(user slack.User) {
+	d.lock.Lock()
+	if old, ok := d.byID[user.ID]; ok {
+		d.unindex(old)
+	}
+	d.byID[user.ID] = user
+	d.index(user)
+	d.lock.Unlock()
+
+	d.publish(user, TopicUserChanged)
+}
+
+// Modify applies `f` to the user with the given ID, if it exists. `f`
+// runs under the write lock and must not use the directory.
+func (d *UserDirectory) Modify(id string, f func(*slack.User)) bool {
+	d.lock.Lock()
+	user, ok := d.byID[id]
+	if !ok {
+		d.lock.Unlock()
+		return false
+	}
+
+	d.unindex(user)
+	f(&user)
+	d.byID[user.ID] = user
+	d.index(user)
+	d.lock.Unlock()
+
+	d.publish(user, TopicUserChanged)
+
+	return true
+}
+
+// Delete removes a user
+func (d *UserDirectory) Delete(id string) {
+	d.lock.Lock()
+	if old, ok := d.byID[id]; ok {
+		d.unindex(old)
+		delete(d.byID, id)
+	}
+	d.lock.Unlock()
+
+	d.publish(id, TopicUserDeleted)
+}
+
+// Replace swaps the whole content of the directory
+func (d *UserDirectory) Replace(users []slack.User) {
+	d.lock.Lock()
+	d.reset()
+	for _, user := range users {
+		d.byID[user.ID] = user
+		d.index(user)
+	}
+	d.lock.Unlock()
+
+	d.publish(len(users), TopicUsersReloaded)
+}
+
+// Sync merges a fresh list of users into the directory: new and changed
+// users are set and the ones missing from the list are removed. The
+// presence, only known from RTM events, is kept. Only the actual changes
+// are published.
+func (d *UserDirectory) Sync(users []slack.User) (changed, removed int) {
+	var updates []slack.User
+	var deletes []string
+
+	d.lock.Lock()
+	seen := make(map[string]bool, len(users))
+	for _, user := range users {
+		seen[user.ID] = true
+
+		old, ok := d.byID[user.ID]
+		if ok {
+			if user.Presence == "" {
+				user.Presence = old.Presence
+			}
+			if reflect.DeepEqual(old, user) {
+				continue
+			}
+			d.unindex(old)
+		}
+		d.byID[user.ID] = user
+		d.index(user)
+		updates = append(updates, user)
+	}
+
+	for id, old := range d.byID {
+		if !seen[id] {
+			d.unindex(old)
+			delete(d.byID, id)
+			deletes = append(deletes, id)
+		}
+	}
+	d.lock.Unlock()
+
+	for _, user := range updates {
+		d.publish(user, TopicUserChanged)
+	}
+	for _, id := range deletes {
+		d.publish(id, TopicUserDeleted)
+	}
+
+	return len(updates), len(deletes)
+}
+
+// Len returns the number of users
+func (d *UserDirectory) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return len(d.byID)
+}
+
+// Snapshot returns a copy of all the users, by ID
+func (d *UserDirectory) Snapshot() map[string]slack.User {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	out := make(map[string]slack.User, len(d.byID))
+	for id, user := range d.byID {
+		out[id] = user
+	}
+	return out
+}
+
+// Each calls `f` for every user, on a snapshot of the directory, until
+// `f` returns false
+func (d *UserDirectory) Each(f func(slack.User) bool) {
+	for _, user := range d.Snapshot() {
+		if !f(user) {
+			return
+		}
+	}
+}
+
+var userIndexes = []string{"name", "email", "display_name", "real_name"}
+
+func userIndexKeys(user slack.User) map[string]string {
+	return map[string]string{
+		"name":         user.Name,
+		"email":        user.Profile.Email,
+		"display_name": user.Profile.DisplayName,
+		"real_name":    user.RealName,
+	}
+}
+
+func (d *UserDirectory) reset() {
+	d.byID = make(map[string]slack.User)
+	d.indexes = make(map[string]map[string]string)
+	for _, index := range userIndexes {
+		d.indexes[index] = make(map[string]string)
+	}
+}
+
+func (d *UserDirectory) index(user slack.User) {
+	for index, key := range userIndexKeys(user) {
+		if key != "" {
+			d.indexes[index][strings.ToLower(key)] = user.ID
+		}
+	}
+}
+
+func (d *UserDirectory) unindex(user slack.User) {
+	for index, key := range userIndexKeys(user) {
+		key = strings.ToLower(key)
+		if d.indexes[index][key] == user.ID {
+			delete(d.indexes[index], key)
+		}
+	}
+}
+
+func (d *UserDirectory) publish(v interface{}, topic string) {
+	if d.pubsub != nil {
+		d.pubsub.TryPub(v, topic)
+	}
+}
+
+// ChannelDirectory is a thread-safe cache of the channels, groups and
+// IMs the bot knows about, indexed by ID, name and IM user. All lookups
+// return copies.
+type ChannelDirectory struct {
+	lock   sync.RWMutex
+	byID   map[string]Channel
+	byName map[string]string // [name] = ID
+	byUser map[string]string // [IM user] = ID
+
+	workspace string
+	pubsub    *pubsub.PubSub
+}
+
+// NewChannelDirectory returns an empty directory publishing its changes
+// on `ps`, which can be nil
+func NewChannelDirectory(ps *pubsub.PubSub) *ChannelDirectory {
+	d := &ChannelDirectory{pubsub: ps}
+	d.reset()
+	return d
+}
+
+// SetWorkspace sets the workspace ID of all the channels, present and
+// future
+func (d *ChannelDirectory) SetWorkspace(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.workspace = id
+	for key, channel := range d.byID {
+		channel.Workspace = id
+		d.byID[key] = channel
+	}
+}
+
+// Get returns the channel with the given ID
+func (d *ChannelDirectory) Get(id string) (Channel, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	channel, ok := d.byID[id]
+	return channel, ok
+}
+
+// ByName returns a channel by name, with or without a leading `#`
+func (d *ChannelDirectory) ByName(name string) (Channel, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	id, ok := d.byName[strings.TrimLeft(name, "#")]
+	if !ok {
+		return Channel{}, false
+	}
+	return d.byID[id], true
+}
+
+// IMWith returns the IM channel open with the given user ID
+func (d *ChannelDirectory) IMWith(userID string) (Channel, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	id, ok := d.byUser[userID]
+	if !ok {
+		return Channel{}, false
+	}
+	return d.byID[id], true
+}
+
+// Set adds or replaces a channel
+func (d *ChannelDirectory) Set(channel Channel) {
+	d.lock.Lock()
+	channel.Workspace = d.workspace
+	if old, ok := d.byID[channel.ID]; ok {
+		d.unindex(old)
+	}
+	d.byID[channel.ID] = channel
+	d.index(channel)
+	d.lock.Unlock()
+
+	d.publish(channel, TopicChannelChanged)
+}
+
+// Modify applies `f` to the channel with the given ID, if it exists. `f`
+// runs under the write lock and must not use the directory.
+func (d *ChannelDirectory) Modify(id string, f func(*Channel)) bool {
+	d.lock.Lock()
+	channel, ok := d.byID[id]
+	if !ok {
+		d.lock.Unlock()
+		return false
+	}
+
+	d.unindex(channel)
+	f(&channel)
+	channel.Workspace = d.workspace
+	d.byID[channel.ID] = channel
+	d.index(channel)
+	d.lock.Unlock()
+
+	d.publish(channel, TopicChannelChanged)
+
+	return true
+}
+
+// Delete removes a channel
+func (d *ChannelDirectory) Delete(id string) {
+	d.lock.Lock()
+	if old, ok := d.byID[id]; ok {
+		d.unindex(old)
+		delete(d.byID, id)
+	}
+	d.lock.Unlock()
+
+	d.publish(id, TopicChannelDeleted)
+}
+
+// Replace swaps the whole content of the directory
+func (d *ChannelDirectory) Replace(channels []Channel) {
+	d.lock.Lock()
+	d.reset()
+	for _, channel := range channels {
+		channel.Workspace = d.workspace
+		d.byID[channel.ID] = channel
+		d.index(channel)
+	}
+	d.lock.Unlock()
+
+	d.publish(len(channels), TopicChannelReloaded)
+}
+
+// Sync merges a fresh list of channels into the directory: new and
+// changed channels are set and the ones missing from the list are
+// removed. Cached members are kept when the list doesn't carry them, as
+// with `conversations.list`. Only the actual changes are published.
+func (d *ChannelDirectory) Sync(channels []Channel) (changed, removed int) {
+	var updates []Channel
+	var deletes []string
+
+	d.lock.Lock()
+	seen := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		seen[channel.ID] = true
+		channel.Workspace = d.workspace
+
+		old, ok := d.byID[channel.ID]
+		if ok {
+			if channel.Members == nil {
+				channel.Members = old.Members
+			}
+			if reflect.DeepEqual(old, channel) {
+				continue
+			}
+			d.unindex(old)
+		}
+		d.byID[channel.ID] = channel
+		d.index(channel)
+		updates = append(updates, channel)
+	}
+
+	for id, old := range d.byID {
+		if !seen[id] {
+			d.unindex(old)
+			delete(d.byID, id)
+			deletes = append(deletes, id)
+		}
+	}
+	d.lock.Unlock()
+
+	for _, channel := range updates {
+		d.publish(channel, TopicChannelChanged)
+	}
+	for _, id := range deletes {
+		d.publish(id, TopicChannelDeleted)
+	}
+
+	return len(updates), len(deletes)
+}
+
+// SyncMemberships merges the fresh list of the conversations the bot is
+// in: new and changed ones are set like with Sync, and the channels
+// missing from the list are kept, but no longer marked as joined. Only the
+// actual changes are published.
+func (d *ChannelDirectory) SyncMemberships(channels []Channel) (changed, left int) {
+	var updates []Channel
+
+	d.lock.Lock()
+	seen := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		seen[channel.ID] = true
+		channel.Workspace = d.workspace
+
+		old, ok := d.byID[channel.ID]
+		if ok {
+			if channel.Members == nil {
+				channel.Members = old.Members
+			}
+			if reflect.DeepEqual(old, channel) {
+				continue
+			}
+			d.unindex(old)
+		}
+		d.byID[channel.ID] = channel
+		d.index(channel)
+		updates = append(updates, channel)
+	}
+
+	for id, channel := range d.byID {
+		if channel.IsMember && !seen[id] {
+			channel.IsMember = false
+			d.byID[id] = channel
+			updates = append(updates, channel)
+			left++
+		}
+	}
+	d.lock.Unlock()
+
+	for _, channel := range updates {
+		d.publish(channel, TopicChannelChanged)
+	}
+
+	return len(updates) - left, left
+}
+
+// Len returns the number of channels
+func (d *ChannelDirectory) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return len(d.byID)
+}
+
+// Snapshot returns a copy of all the channels, by ID
+func (d *ChannelDirectory) Snapshot() map[string]Channel {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	out := make(map[string]Channel, len(d.byID))
+	for id, channel := range d.byID {
+		out[id] = channel
+	}
+	return out
+}
+
+// Each calls `f` for every channel, on a snapshot of the directory,
+// until `f` returns false
+func (d *ChannelDirectory) Each(f func(Channel) bool) {
+	for _, channel := range d.Snapshot() {
+		if !f(channel) {
+			return
+		}
+	}
+}
+
+func (d *ChannelDirectory) reset() {
+	d.byID = make(map[string]Channel)
+	d.byName = make(map[string]string)
+	d.byUser = make(map[string]string)
+}
+
+func (d *ChannelDirectory) index(channel Channel) {
+	if channel.Name != "" {
+		d.byName[channel.Name] = channel.ID
+	}
+	if channel.IsIM && channel.User != "" {
+		d.byUser[channel.User] = channel.ID
+	}
+}
+
+func (d *ChannelDirectory) unindex(channel Channel) {
+	if d.byName[channel.Name] == channel.ID {
+		delete(d.byName, channel.Name)
+	}
+	if d.byUser[channel.User] == channel.ID {
+		delete(d.byUser, channel.User)
+	}
+}
+
+func (d *ChannelDirectory) publish(v interface{}, topic string) {
+	if d.pubsub != nil {
+		d.pubsub.TryPub(v, topic)
+	}
+}
@@ -0,0 +1,272 @@
+package bawt
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cskr/pubsub"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func testUser(id, name, email, display string) slack.User {
+	return slack.User{
+		ID:       id,
+		Name:     name,
+		RealName: "Real " + name,
+		Profile: slack.UserProfile{
+			Email:       email,
+			DisplayName: display,
+		},
+	}
+}
+
+func TestUserDirectory_Find(t *testing.T) {
+	d := NewUserDirectory(nil)
+	d.Replace([]slack.User{
+		testUser("U1", "alice", "Alice@example.com", "Al"),
+		testUser("U2", "bob", "bob@example.com", "bobby"),
+	})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"U1", "U1"},
+		{"bob", "U2"},
+		{"alice@example.com", "U1"},
+		{"BOBBY", "U2"},
+		{"Real alice", "U1"},
+		{"carol", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			user, ok := d.Find(tt.query)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, user.ID)
+		})
+	}
+}
+
+func TestUserDirectory_SetReindexes(t *testing.T) {
+	d := NewUserDirectory(nil)
+	d.Set(testUser("U1", "alice", "alice@example.com", "Al"))
+	d.Set(testUser("U1", "alicia", "alice@example.com", "Al"))
+
+	_, ok := d.Find("alice")
+	assert.False(t, ok)
+
+	user, ok := d.Find("alicia")
+	assert.True(t, ok)
+	assert.Equal(t, "U1", user.ID)
+
+	d.Delete("U1")
+	_, ok = d.Find("alice@example.com")
+	assert.False(t, ok)
+	assert.Equal(t, 0, d.Len())
+}
+
+func TestUserDirectory_Fetch(t *testing.T) {
+	d := NewUserDirectory(nil)
+
+	_, err := d.Fetch("U1")
+	assert.Error(t, err)
+
+	calls := 0
+	d.SetFetcher(func(id string) (*slack.User, error) {
+		calls++
+		if id == "U1" {
+			u := testUser("U1", "alice", "", "")
+			return &u, nil
+		}
+		return nil, fmt.Errorf("user_not_found")
+	})
+
+	user, err := d.Fetch("U1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", user.Name)
+
+	// Now cached
+	_, err = d.Fetch("U1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	_, err = d.Fetch("U2")
+	assert.Error(t, err)
+}
+
+func TestUserDirectory_Snapshot(t *testing.T) {
+	d := NewUserDirectory(nil)
+	d.Set(testUser("U1", "alice", "", ""))
+
+	snap := d.Snapshot()
+	snap["U2"] = testUser("U2", "bob", "", "")
+	assert.Equal(t, 1, d.Len())
+
+	count := 0
+	d.Each(func(slack.User) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 1, count)
+}
+
+func TestUserDirectory_Concurrency(t *testing.T) {
+	d := NewUserDirectory(nil)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.Set(testUser(fmt.Sprintf("U%d", j), fmt.Sprintf("user%d", i), "", ""))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.Find(fmt.Sprintf("user%d", j))
+				d.Snapshot()
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 100, d.Len())
+}
+
+func TestChannelDirectory(t *testing.T) {
+	ps := pubsub.New(10)
+	changes := ps.Sub(TopicChannelChanged, TopicChannelDeleted)
+
+	d := NewChannelDirectory(ps)
+	d.Replace([]Channel{
+		{ID: "C1", Name: "general", IsChannel: true},
+		{ID: "D1", Name: "U1", User: "U1", IsIM: true},
+	})
+
+	channel, ok := d.ByName("#general")
+	assert.True(t, ok)
+	assert.Equal(t, "C1", channel.ID)
+
+	im, ok := d.IMWith("U1")
+	assert.True(t, ok)
+	assert.Equal(t, "D1", im.ID)
+
+	assert.True(t, d.Modify("C1", func(c *Channel) { c.Name = "random" }))
+	assert.False(t, d.Modify("C2", func(c *Channel) { c.Name = "nope" }))
+
+	_, ok = d.ByName("general")
+	assert.False(t, ok)
+	channel, ok = d.ByName("random")
+	assert.True(t, ok)
+	assert.Equal(t, "C1", channel.ID)
+
+	select {
+	case ev := <-changes:
+		assert.Equal(t, "random", ev.(Channel).Name)
+	case <-time.After(time.Second):
+		t.Fatal("no change notification")
+	}
+
+	d.Delete("D1")
+	_, ok = d.IMWith("U1")
+	assert.False(t, ok)
+
+	select {
+	case ev := <-changes:
+		assert.Equal(t, "D1", ev.(string))
+	case <-time.After(time.Second):
+		t.Fatal("no delete notification")
+	}
+}
+
+func TestUserDirectory_Sync(t *testing.T) {
+	ps := pubsub.New(10)
+	changes := ps.Sub(TopicUserChanged, TopicUserDeleted)
+
+	d := NewUserDirectory(ps)
+	d.Replace([]slack.User{
+		testUser("U1", "alice", "alice@example.com", "Al"),
+		testUser("U2", "bob", "bob@example.com", "bobby"),
+	})
+	d.Modify("U1", func(u *slack.User) { u.Presence = "active" })
+	<-changes
+
+	changed, removed := d.Sync([]slack.User{
+		testUser("U1", "alice", "alice@example.com", "Al"),
+		testUser("U3", "carol", "carol@example.com", "caro"),
+	})
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, 1, removed)
+
+	select {
+	case ev := <-changes:
+		assert.Equal(t, "U3", ev.(slack.User).ID)
+	case <-time.After(time.Second):
+		t.Fatal("no change notification")
+	}
+	select {
+	case ev := <-changes:
+		assert.Equal(t, "U2", ev.(string))
+	case <-time.After(time.Second):
+		t.Fatal("no delete notification")
+	}
+
+	user, ok := d.Get("U1")
+	assert.True(t, ok)
+	assert.Equal(t, "active", user.Presence)
+
+	_, ok = d.Find("bobby")
+	assert.False(t, ok)
+	assert.Equal(t, 2, d.Len())
+}
+
+func TestChannelDirectory_Sync(t *testing.T) {
+	d := NewChannelDirectory(nil)
+	d.Replace([]Channel{
+		{ID: "C1", Name: "general", IsChannel: true, Members: []string{"U1"}},
+		{ID: "C2", Name: "random", IsChannel: true},
+	})
+
+	changed, removed := d.Sync([]Channel{
+		{ID: "C1", Name: "general", IsChannel: true},
+		{ID: "C3", Name: "ops", IsGroup: true},
+	})
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, 1, removed)
+
+	channel, ok := d.ByName("general")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"U1"}, channel.Members)
+
+	_, ok = d.ByName("random")
+	assert.False(t, ok)
+}
+
+func TestChannelDirectory_SyncMemberships(t *testing.T) {
+	d := NewChannelDirectory(nil)
+	d.Replace([]Channel{
+		{ID: "C1", Name: "general", IsChannel: true, IsMember: true, Members: []string{"U1"}},
+		{ID: "C2", Name: "random", IsChannel: true, IsMember: true},
+		{ID: "C3", Name: "ops", IsChannel: true},
+	})
+
+	changed, left := d.SyncMemberships([]Channel{
+		{ID: "C1", Name: "general", IsChannel: true, IsMember: true},
+		{ID: "C4", Name: "new", IsGroup: true, IsMember: true},
+	})
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, 1, left)
+	assert.Equal(t, 4, d.Len())
+
+	channel, _ := d.Get("C1")
+	assert.Equal(t, []string{"U1"}, channel.Members)
+	channel, _ = d.Get("C2")
+	assert.False(t, channel.IsMember)
+	_, ok := d.ByName("new")
+	assert.True(t, ok)
+}
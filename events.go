@@ -0,0 +1,300 @@
+package bawt
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cskr/pubsub"
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+)
+
+// Topics of the events published by the core, along with the ones of
+// the directories (directory.go), the status (status.go) and the moods
+// (mood.go). See `Events()` for their payload types.
+const (
+	TopicMessageReceived   = "bawt:message:received"
+	TopicReactionAdded     = "bawt:reaction:added"
+	TopicReactionRemoved   = "bawt:reaction:removed"
+	TopicUserJoined        = "bawt:user:joined"
+	TopicChannelCreated    = "bawt:channel:created"
+	TopicChannelArchived   = "bawt:channel:archived"
+	TopicChannelUnarchived = "bawt:channel:unarchived"
+	TopicConnectionChanged = "bawt:connection:changed"
+	TopicPluginError       = "bawt:plugin:error"
+)
+
+// States of a ConnectionEvent
+const (
+	ConnectionConnected    = "connected"
+	ConnectionDisconnected = "disconnected"
+	ConnectionInvalidAuth  = "invalid_auth"
+	ConnectionError        = "error"
+)
+
+// ConnectionEvent is published when the connection to a workspace
+// changes state
+type ConnectionEvent struct {
+	Workspace string
+	State     string
+	Err       error
+}
+
+// PluginError is published when a plugin reports an error with
+// `Bot.ReportError`, or when a listener or a subscriber panics
+type PluginError struct {
+	Plugin    string
+	Workspace string
+	Err       error
+	Panic     bool
+}
+
+var (
+	eventTypesLock sync.RWMutex
+	eventTypes     = make(map[string]reflect.Type)
+)
+
+func init() {
+	RegisterEvent(TopicMessageReceived, &Message{})
+	RegisterEvent(TopicReactionAdded, &ReactionEvent{})
+	RegisterEvent(TopicReactionRemoved, &ReactionEvent{})
+	RegisterEvent(TopicUserJoined, slack.User{})
+	RegisterEvent(TopicChannelCreated, Channel{})
+	RegisterEvent(TopicChannelArchived, Channel{})
+	RegisterEvent(TopicChannelUnarchived, Channel{})
+	RegisterEvent(TopicConnectionChanged, ConnectionEvent{})
+	RegisterEvent(TopicPluginError, PluginError{})
+
+	RegisterEvent(TopicUserChanged, slack.User{})
+	RegisterEvent(TopicUserDeleted, "")
+	RegisterEvent(TopicUsersReloaded, 0)
+	RegisterEvent(TopicChannelChanged, Channel{})
+	RegisterEvent(TopicChannelDeleted, "")
+	RegisterEvent(TopicChannelReloaded, 0)
+	RegisterEvent(TopicHealthChanged, ComponentStatus{})
+	RegisterEvent(TopicMoodChanged, MoodChange{})
+}
+
+/*
+RegisterEvent declares the payload type of a topic, given as a sample
+value. Plugins register their own topics from their `init()`, like
+`bawt.RegisterEvent("recognition:recognized", &Recognition{})`, and
+`EventBus.Publish` then refuses payloads of another type.
+*/
+func RegisterEvent(topic string, sample interface{}) {
+	eventTypesLock.Lock()
+	defer eventTypesLock.Unlock()
+
+	eventTypes[topic] = reflect.TypeOf(sample)
+}
+
+// EventInfo describes a registered topic
+type EventInfo struct {
+	Topic   string
+	Payload string
+}
+
+// Events returns the registered topics, sorted
+func Events() []EventInfo {
+	eventTypesLock.RLock()
+	defer eventTypesLock.RUnlock()
+
+	out := make([]EventInfo, 0, len(eventTypes))
+	for topic, t := range eventTypes {
+		out = append(out, EventInfo{Topic: topic, Payload: t.String()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Topic < out[j].Topic })
+
+	return out
+}
+
+/*
+EventBus is a typed layer over `Bot.PubSub`. Payloads are checked
+against the types registered with RegisterEvent, and subscriptions are
+dropped when the bot shuts down.
+*/
+type EventBus struct {
+	ps   *pubsub.PubSub
+	stop <-chan struct{}
+	log  *logrus.Logger // set along with the bot's logging
+}
+
+// NewEventBus returns a bus over `ps`, whose subscriptions end when
+// `stop` is closed
+func NewEventBus(ps *pubsub.PubSub, stop <-chan struct{}) *EventBus {
+	return &EventBus{ps: ps, stop: stop}
+}
+
+// Publish sends an event to the subscribers of a registered topic. It
+// never blocks: subscribers too slow to keep up miss events.
+func (b *EventBus) Publish(topic string, payload interface{}) error {
+	eventTypesLock.RLock()
+	t, ok := eventTypes[topic]
+	eventTypesLock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("unregistered event topic %q", topic)
+	}
+	if reflect.TypeOf(payload) != t {
+		return fmt.Errorf("invalid payload for %q: expected %s, got %T", topic, t, payload)
+	}
+
+	b.ps.TryPub(payload, topic)
+
+	return nil
+}
+
+// Subscription is returned by the Subscribe functions
+type Subscription struct {
+	ps   *pubsub.PubSub
+	ch   chan interface{}
+	once sync.Once
+	done chan struct{}
+}
+
+/*
+Subscribe calls `handler` with the payload of every event published on
+the topics, one at a time, in a dedicated goroutine, until Unsubscribe is
+called or the bot shuts down. A panicking handler is reported as a plugin
+error and keeps receiving the next events.
+*/
+func (b *EventBus) Subscribe(handler func(payload interface{}), topics ...string) *Subscription {
+	s := &Subscription{
+		ps:   b.ps,
+		ch:   b.ps.Sub(topics...),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case payload, ok := <-s.ch:
+				if !ok {
+					return
+				}
+				b.handle(handler, payload, topics)
+			case <-s.done:
+				s.drain()
+				return
+			case <-b.stop:
+				s.Unsubscribe()
+				s.drain()
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// handle calls a subscriber, recovering from its panics like dispatch
+// does for listeners
+func (b *EventBus) handle(handler func(payload interface{}), payload interface{}, topics []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			name := "subscriber of " + strings.Join(topics, ", ")
+			err := fmt.Errorf("panic in subscriber: %v", r)
+
+			log := b.log
+			if log == nil {
+				log = logrus.StandardLogger()
+			}
+			log.WithError(err).WithField("plugin", name).Errorf("Subscriber panicked\n%s", debug.Stack())
+
+			// Don't loop when the subscriber of plugin errors panics
+			for _, topic := range topics {
+				if topic == TopicPluginError {
+					return
+				}
+			}
+			b.Publish(TopicPluginError, PluginError{Plugin: name, Err: err, Panic: true})
+		}
+	}()
+
+	handler(payload)
+}
+
+// Unsubscribe stops the subscription. It is safe to call more than once.
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.done)
+		// Unsub goes through the PubSub's loop, which may be trying to
+		// deliver to us: don't wait for it
+		go s.ps.Unsub(s.ch)
+	})
+}
+
+// drain empties the channel until the PubSub closes it
+func (s *Subscription) drain() {
+	for range s.ch {
+	}
+}
+
+// OnMessage calls `f` for every message received, in all workspaces
+func (b *EventBus) OnMessage(f func(*Message)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(*Message)) }, TopicMessageReceived)
+}
+
+// OnReaction calls `f` for every reaction added or removed
+func (b *EventBus) OnReaction(f func(*ReactionEvent)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(*ReactionEvent)) }, TopicReactionAdded, TopicReactionRemoved)
+}
+
+// OnUserJoined calls `f` for every user joining a workspace
+func (b *EventBus) OnUserJoined(f func(slack.User)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(slack.User)) }, TopicUserJoined)
+}
+
+// OnUserChanged calls `f` for every change to a user, including
+// presence changes
+func (b *EventBus) OnUserChanged(f func(slack.User)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(slack.User)) }, TopicUserChanged)
+}
+
+// OnChannelCreated calls `f` for every channel or group created
+func (b *EventBus) OnChannelCreated(f func(Channel)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(Channel)) }, TopicChannelCreated)
+}
+
+// OnChannelArchived calls `f` for every channel or group archived
+func (b *EventBus) OnChannelArchived(f func(Channel)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(Channel)) }, TopicChannelArchived)
+}
+
+// OnConnectionChanged calls `f` when the connection to a workspace
+// changes state
+func (b *EventBus) OnConnectionChanged(f func(ConnectionEvent)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(ConnectionEvent)) }, TopicConnectionChanged)
+}
+
+// OnPluginError calls `f` for every error reported by plugins
+func (b *EventBus) OnPluginError(f func(PluginError)) *Subscription {
+	return b.Subscribe(func(p interface{}) { f(p.(PluginError)) }, TopicPluginError)
+}
+
+// ReportError logs an error of a plugin and publishes it on
+// TopicPluginError
+func (bot *Bot) ReportError(plugin string, err error) {
+	bot.Logging.Logger.WithError(err).WithField("plugin", plugin).Error("Plugin error")
+	bot.publish(TopicPluginError, PluginError{Plugin: plugin, Workspace: bot.WorkspaceID(), Err: err})
+}
+
+// publish sends a core event, when the bus is set up
+func (bot *Bot) publish(topic string, payload interface{}) {
+	if bot.Events == nil {
+		return
+	}
+
+	if err := bot.Events.Publish(topic, payload); err != nil {
+		bot.Logging.Logger.WithError(err).Error("Unable to publish event")
+	}
+}
+
+// publishConnection publishes a change of the connection state
+func (bot *Bot) publishConnection(state string, err error) {
+	bot.publish(TopicConnectionChanged, ConnectionEvent{Workspace: bot.WorkspaceID(), State: state, Err: err})
+}
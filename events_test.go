@@ -0,0 +1,138 @@
+package bawt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cskr/pubsub"
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Publish(t *testing.T) {
+	b := NewEventBus(pubsub.New(10), nil)
+
+	assert.NoError(t, b.Publish(TopicUserJoined, slack.User{ID: "U1"}))
+	assert.Error(t, b.Publish(TopicUserJoined, &slack.User{ID: "U1"}))
+	assert.Error(t, b.Publish("unknown:topic", 1))
+
+	RegisterEvent("test:event", "")
+	assert.NoError(t, b.Publish("test:event", "payload"))
+	assert.Contains(t, Events(), EventInfo{Topic: "test:event", Payload: "string"})
+}
+
+func TestEventBus_Subscribe(t *testing.T) {
+	stop := make(chan struct{})
+	b := NewEventBus(pubsub.New(10), stop)
+
+	joined := make(chan slack.User, 10)
+	sub := b.OnUserJoined(func(u slack.User) { joined <- u })
+
+	stopped := make(chan Channel, 10)
+	b.OnChannelArchived(func(c Channel) { stopped <- c })
+
+	b.Publish(TopicUserJoined, slack.User{ID: "U1"})
+	assert.Equal(t, "U1", receiveUser(t, joined).ID)
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	b.Publish(TopicUserJoined, slack.User{ID: "U2"})
+
+	b.Publish(TopicChannelArchived, Channel{ID: "C1"})
+	select {
+	case c := <-stopped:
+		assert.Equal(t, "C1", c.ID)
+	case <-time.After(time.Second):
+		t.Fatal("No channel event received")
+	}
+
+	select {
+	case u := <-joined:
+		t.Fatalf("Received %s after unsubscribing", u.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Shutting down drops the remaining subscriptions
+	close(stop)
+	time.Sleep(50 * time.Millisecond)
+	b.Publish(TopicChannelArchived, Channel{ID: "C2"})
+
+	select {
+	case c := <-stopped:
+		t.Fatalf("Received %s after shutdown", c.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventBus_SubscribePanic(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	b := NewEventBus(pubsub.New(10), stop)
+
+	errors := make(chan PluginError, 10)
+	b.OnPluginError(func(e PluginError) { errors <- e })
+
+	joined := make(chan slack.User, 10)
+	b.OnUserJoined(func(u slack.User) {
+		if u.ID == "U1" {
+			panic("boom")
+		}
+		joined <- u
+	})
+
+	b.Publish(TopicUserJoined, slack.User{ID: "U1"})
+	select {
+	case e := <-errors:
+		assert.True(t, e.Panic)
+		assert.Contains(t, e.Err.Error(), "boom")
+	case <-time.After(time.Second):
+		t.Fatal("No plugin error received")
+	}
+
+	// The subscription survives the panic
+	b.Publish(TopicUserJoined, slack.User{ID: "U2"})
+	assert.Equal(t, "U2", receiveUser(t, joined).ID)
+}
+
+func receiveUser(t *testing.T, ch chan slack.User) slack.User {
+	select {
+	case u := <-ch:
+		return u
+	case <-time.After(time.Second):
+		t.Fatal("No user event received")
+	}
+	return slack.User{}
+}
+
+func TestCoreEvents(t *testing.T) {
+	bot := New("")
+	bot.Logging.Logger = logrus.New()
+
+	joined := make(chan slack.User, 1)
+	bot.Events.OnUserJoined(func(u slack.User) { joined <- u })
+
+	errors := make(chan PluginError, 1)
+	bot.Events.OnPluginError(func(e PluginError) { errors <- e })
+
+	bot.listeners = append(bot.listeners, &Listener{
+		Name: "Broken",
+		Bot:  bot,
+		EventHandlerFunc: func(_ *Listener, event interface{}) {
+			panic("oops")
+		},
+	})
+
+	bot.handleRTMEvent(&slack.RTMEvent{Data: &slack.TeamJoinEvent{User: slack.User{ID: "U1", Name: "alice"}}})
+
+	assert.Equal(t, "alice", receiveUser(t, joined).Name)
+
+	select {
+	case e := <-errors:
+		assert.Equal(t, "Broken", e.Plugin)
+		assert.True(t, e.Panic)
+		assert.EqualError(t, e.Err, "panic in listener: oops")
+	case <-time.After(time.Second):
+		t.Fatal("No plugin error received")
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io"
+	"os"
 
 	"github.com/gopherworks/bawt"
 	_ "github.com/gopherworks/bawt/bugger"
@@ -13,10 +16,12 @@ import (
 	_ "github.com/gopherworks/bawt/mooder"
 	_ "github.com/gopherworks/bawt/plotberry"
 	_ "github.com/gopherworks/bawt/recognition"
+	_ "github.com/gopherworks/bawt/search"
 	_ "github.com/gopherworks/bawt/standup"
 	_ "github.com/gopherworks/bawt/todo"
 	_ "github.com/gopherworks/bawt/web"
 	_ "github.com/gopherworks/bawt/webauth"
+	_ "github.com/gopherworks/bawt/webhooks"
 	_ "github.com/gopherworks/bawt/webutils"
 	_ "github.com/gopherworks/bawt/wicked"
 )
@@ -32,5 +37,50 @@ func main() {
 
 	bot := bawt.New(*configFile)
 
+	if flag.Arg(0) == "reference" {
+		if err := reference(bot, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not generate the command reference: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	bot.Run()
 }
+
+// reference writes the command reference of the plugins linked in, as
+// Markdown or HTML, without connecting to Slack:
+//
+//	example-bot -config config.yaml reference -format html -o commands.html
+func reference(bot *bawt.Bot, args []string) error {
+	fs := flag.NewFlagSet("reference", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format, markdown or html")
+	output := fs.String("o", "", "output file, stdout by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := bot.InitOffline(); err != nil {
+		return err
+	}
+	ref := bot.CommandReference()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "markdown", "md":
+		return ref.WriteMarkdown(w)
+	case "html":
+		return ref.WriteHTML(w)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
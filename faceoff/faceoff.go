@@ -100,7 +100,7 @@ func (p *Faceoff) updateUsersFromSlack() {
 		p.users = make(map[string]*User)
 	}
 
-	for _, slackUser := range p.bot.Users {
+	for _, slackUser := range p.bot.Users.Snapshot() {
 		if slackUser.IsBot || slackUser.Deleted || slackUser.IsUltraRestricted || slackUser.IsRestricted || slackUser.RealName == "slackbot" {
 			delete(p.users, slackUser.ID)
 			continue
@@ -43,10 +43,10 @@ func (g *Game) Launch() {
 	var profileURLs []string
 	var lookedForUser slack.User
 	for idx, userID := range c.UsersShown {
-		u := g.Faceoff.bot.Users[userID]
+		u, _ := g.Faceoff.bot.Users.Get(userID)
 		if u.ID == "" {
 			log.Println("faceoff: error finding user with ID", userID)
-			g.OriginalMessage.Reply("error finding user with ID %q", userID)
+			g.OriginalMessage.ReplyT("faceoff.user_not_found", userID)
 			return
 		}
 
@@ -69,7 +69,7 @@ func (g *Game) Launch() {
 	// Trigger a line with who we're looking for..
 	// Add the reactions, slowly, in order..
 	// Send the image, after a good second..
-	prepared := g.OriginalMessage.Reply(fmt.Sprintf("---\nBe prepared! We're looking for *%s* in the next image:", lookedForUser.RealName))
+	prepared := g.OriginalMessage.ReplyT("faceoff.prepare", lookedForUser.RealName)
 	prepared.OnAck(func(ev *slack.AckMessage) {
 		go func() {
 			delay := 750 * time.Millisecond
@@ -90,7 +90,7 @@ func (g *Game) Launch() {
 func (g *Game) showChallenge(c *Challenge, lookedForUser slack.User, pngContent []byte, ts string) {
 	err := ioutil.WriteFile("/tmp/faceoff.png", pngContent, 0644)
 	if err != nil {
-		g.OriginalMessage.Reply("error writing temp faceoff image: %s", err)
+		g.OriginalMessage.ReplyT("faceoff.write_error", err)
 		return
 	}
 
@@ -99,11 +99,11 @@ func (g *Game) showChallenge(c *Challenge, lookedForUser slack.User, pngContent
 	_, err = g.Faceoff.bot.Slack.UploadFile(slack.FileUploadParameters{
 		File:     "/tmp/faceoff.png",
 		Filetype: "png",
-		Title:    fmt.Sprintf("Find: %s", lookedForUser.RealName),
+		Title:    g.OriginalMessage.T("faceoff.find", lookedForUser.RealName),
 		Channels: []string{g.Channel.ID},
 	})
 	if err != nil {
-		g.OriginalMessage.Reply("error uploading faceoff image: %s", err)
+		g.OriginalMessage.ReplyT("faceoff.upload_error", err)
 		return
 	}
 
@@ -141,7 +141,7 @@ func (g *Game) showChallenge(c *Challenge, lookedForUser slack.User, pngContent
 			defer listen.Close()
 
 			if len(c.Replies) == 0 {
-				g.OriginalMessage.Reply("oh well, I guess no one wanted to play!")
+				g.OriginalMessage.ReplyT("faceoff.no_players")
 				// TODO: remove the original image
 				return
 			}
@@ -158,12 +158,12 @@ func (g *Game) showChallenge(c *Challenge, lookedForUser slack.User, pngContent
 				for i := 0; i < 4; i++ {
 					user := g.Faceoff.bot.GetUser(c.UsersShown[i])
 					if user != nil {
-						whowaswho = append(whowaswho, fmt.Sprintf("%s was *%s*", numbers[i], user.RealName))
+						whowaswho = append(whowaswho, g.OriginalMessage.T("faceoff.was", numbers[i], user.RealName))
 					}
 				}
-				g.OriginalMessage.Reply("Congrats <@%s> ! You found <@%s> the fastest.\n%s\nYour scores: `%s`", c.FirstCorrectReply, c.UsersShown[c.RightAnswerIndex], strings.Join(whowaswho, ", "), user.ScoreLine())
+				g.OriginalMessage.ReplyT("faceoff.congrats", c.FirstCorrectReply, c.UsersShown[c.RightAnswerIndex], strings.Join(whowaswho, ", "), user.ScoreLine())
 			} else {
-				g.OriginalMessage.Reply("No one found out !? Try again !")
+				g.OriginalMessage.ReplyT("faceoff.nobody")
 			}
 
 			go g.Launch()
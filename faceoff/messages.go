@@ -0,0 +1,17 @@
+package faceoff
+
+import "github.com/gopherworks/bawt"
+
+func init() {
+	bawt.RegisterMessages(bawt.DefaultLocale, map[string]bawt.Translation{
+		"faceoff.user_not_found": {Other: "error finding user with ID %q"},
+		"faceoff.prepare":        {Other: "---\nBe prepared! We're looking for *%s* in the next image:"},
+		"faceoff.write_error":    {Other: "error writing temp faceoff image: %s"},
+		"faceoff.find":           {Other: "Find: %s"},
+		"faceoff.upload_error":   {Other: "error uploading faceoff image: %s"},
+		"faceoff.no_players":     {Other: "oh well, I guess no one wanted to play!"},
+		"faceoff.was":            {Other: "%s was *%s*"},
+		"faceoff.congrats":       {Other: "Congrats <@%s> ! You found <@%s> the fastest.\n%s\nYour scores: `%s`"},
+		"faceoff.nobody":         {Other: "No one found out !? Try again !"},
+	})
+}
@@ -82,11 +82,11 @@ func (funny *Funny) ChatHandler(listen *bawt.Listener, msg *bawt.Message) {
 	if msg.MentionsMe {
 		if msg.Contains("you're funny") {
 
-			if bot.Mood == bawt.Happy {
+			if msg.Mood() == bawt.Happy {
 				msg.Reply("/me blushes")
 			} else {
 				msg.Reply("here's another one")
-				msg.Reply(bawt.RandomString("robot jokes"))
+				msg.ReplyRandom("robot jokes")
 			}
 
 		} else if msg.ContainsAny([]string{"dumb ass", "dumbass"}) {
@@ -94,10 +94,10 @@ func (funny *Funny) ChatHandler(listen *bawt.Listener, msg *bawt.Message) {
 			msg.Reply("don't say such things")
 
 		} else if msg.ContainsAny([]string{"thanks", "thank you", "thx", "thnks"}) {
-			msg.Reply(bot.WithMood("my pleasure", "any time, just ask, I'm here for you, ffiieeewww!get a life"))
+			msg.Reply(msg.WithMood("my pleasure", "any time, just ask, I'm here for you, ffiieeewww!get a life"))
 
 		} else if msg.Contains("how are you") && msg.MentionsMe {
-			msg.ReplyMention(bot.WithMood("good, and you ?", "I'm wild today!! wadabout you ?"))
+			msg.ReplyMention(msg.WithMood("good, and you ?", "I'm wild today!! wadabout you ?"))
 			bot.Listen(&bawt.Listener{
 				Name:           "Funny",
 				Description:    "An app that makes jokes about a certain subject",
@@ -106,7 +106,7 @@ func (funny *Funny) ChatHandler(listen *bawt.Listener, msg *bawt.Message) {
 				FromChannel:    msg.FromChannel,
 				MentionsMeOnly: true,
 				MessageHandlerFunc: func(listen *bawt.Listener, msg *bawt.Message) {
-					msg.ReplyMention(bot.WithMood("glad to hear it!", "zwweeeeeeeeet !"))
+					msg.ReplyMention(msg.WithMood("glad to hear it!", "zwweeeeeeeeet !"))
 					listen.Close()
 				},
 				TimeoutFunc: func(listen *bawt.Listener) {
@@ -203,7 +203,7 @@ func (funny *Funny) ChatHandler(listen *bawt.Listener, msg *bawt.Message) {
 		msg.Reply("yeah, theory and practice perfectly match... in theory.")
 	} else if msg.Contains("dishes") {
 
-		msg.Reply(bawt.RandomString("dishes"))
+		msg.ReplyRandom("dishes")
 
 	} else if msg.Contains(" bean") {
 
@@ -2,14 +2,20 @@
 package healthy
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gopherworks/bawt"
 	log "github.com/sirupsen/logrus"
 )
 
+// client gives up on endpoints that don't answer, as checks run on every
+// request of /healthz and /readyz
+var client = &http.Client{Timeout: 5 * time.Second}
+
 // Healthy is a struct holding URL's to evaluate
 type Healthy struct {
 	urls []string
@@ -31,6 +37,11 @@ func (healthy *Healthy) InitPlugin(bot *bawt.Bot) {
 
 	healthy.urls = conf.HealthCheck.Urls
 
+	// Expose each endpoint in the bot's status registry as well
+	for _, url := range healthy.urls {
+		bot.Status.Register("healthcheck:"+url, false, checker(url))
+	}
+
 	bot.Listen(&bawt.Listener{
 		MentionsMeOnly:     true,
 		ContainsAny:        []string{"!health", "!healthy?", "!health_check"},
@@ -80,8 +91,18 @@ func (healthy *Healthy) CheckAll() string {
 	}
 }
 
+// checker wraps check into a bawt.HealthCheck
+func checker(url string) bawt.HealthCheck {
+	return func() (bawt.HealthState, error) {
+		if !check(url) {
+			return bawt.StateFailing, fmt.Errorf("%s did not return a 2xx", url)
+		}
+		return bawt.StateOK, nil
+	}
+}
+
 func check(url string) bool {
-	res, err := http.Get(url)
+	res, err := client.Get(url)
 	if err != nil {
 		return false
 	}
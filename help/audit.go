@@ -0,0 +1,78 @@
+package help
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// maxAuditLines caps the number of entries replied in chat
+const maxAuditLines = 50
+
+// handleAudit replies with the audit log: `!bawt audit [user] [since]`
+func (h *Help) handleAudit(listen *bawt.Listener, msg *bawt.Message) {
+	log := h.bot.Logging.Logger
+
+	parts := strings.Fields(msg.Match[0])[2:]
+
+	actor := ""
+	since := time.Now().AddDate(0, 0, -7)
+
+	for _, p := range parts {
+		if t, err := bawt.ParseSince(p, time.Now()); err == nil {
+			since = t
+			continue
+		}
+
+		id := p
+		if strings.HasPrefix(id, "<@") {
+			id = bawt.NormalizeID(id)
+		}
+		if user := h.bot.GetUser(strings.TrimLeft(id, "@")); user != nil {
+			id = user.ID
+		}
+		actor = id
+	}
+
+	entries, err := h.bot.AuditLog(actor, since)
+	if err != nil {
+		log.WithError(err).Error("Error reading the audit log")
+		msg.Reply("I couldn't read the audit log.")
+		return
+	}
+
+	if len(entries) == 0 {
+		msg.Reply("Nothing in the audit log since %s.", since.Format("2006-01-02 15:04"))
+		return
+	}
+
+	var lines []string
+	if len(entries) > maxAuditLines {
+		lines = append(lines, fmt.Sprintf("(%d older entries skipped)", len(entries)-maxAuditLines))
+		entries = entries[len(entries)-maxAuditLines:]
+	}
+
+	for _, e := range entries {
+		lines = append(lines, e.String())
+	}
+
+	msg.Reply(strings.Join(lines, "\n"))
+}
+
+// audit records a privileged chat command in the bot's audit log
+func (h *Help) audit(msg *bawt.Message, action, target string, params map[string]string, result string, err error) {
+	entry := bawt.AuditEntry{
+		Actor:  msg.FromUser.ID,
+		Action: action,
+		Target: target,
+		Params: params,
+		Result: result,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	h.bot.Audit(entry)
+}
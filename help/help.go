@@ -1,7 +1,9 @@
 package help
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/davecgh/go-spew/spew"
@@ -34,6 +36,7 @@ func (h *Help) listenHelp() {
 		Matches:            regexp.MustCompile(`^!help.*`),
 		MessageHandlerFunc: h.handleHelp,
 		Name:               "Help",
+		Slug:               "help",
 		Description:        "Provides useful information about the apps and commands available",
 		Commands: []bawt.Command{
 			{
@@ -41,8 +44,16 @@ func (h *Help) listenHelp() {
 				HelpText: "Displays the help topics for all registered plugins",
 			},
 			{
-				Usage:    "!help <slug>",
-				HelpText: "Displays the help topic for a particular plugin",
+				Usage:    "!help <slug|command> [page]",
+				HelpText: "Displays the help topic for a particular plugin, or the commands matching a search",
+			},
+			{
+				Usage:    "!help all [page]",
+				HelpText: "Displays every command available to you, grouped by plugin",
+			},
+			{
+				Usage:    "!help ... dm",
+				HelpText: "Sends the help privately instead of in the channel",
 			},
 		},
 	})
@@ -51,6 +62,7 @@ func (h *Help) listenHelp() {
 		Matches:            regexp.MustCompile(`^!apps`),
 		MessageHandlerFunc: h.handleApps,
 		Name:               "Help",
+		Slug:               "help",
 		Description:        "Provides Information",
 		Commands: []bawt.Command{
 			{
@@ -63,14 +75,39 @@ func (h *Help) listenHelp() {
 	h.bot.Listen(&bawt.Listener{
 		Matches:            regexp.MustCompile(`^!bawt.*`),
 		MessageHandlerFunc: h.handleBawt,
-		Name:               "Help",
-		Description:        "Provides Information",
+		Name:               "Admin",
+		Slug:               "bawt",
+		Description:        "Administers the bot",
 		FromInternalGroup:  []string{"GlobalAdmins"},
 		Commands: []bawt.Command{
 			{
 				Usage:    "!bawt",
 				HelpText: "Displays a list of plugins",
 			},
+			{
+				Usage:    "!bawt status",
+				HelpText: "Displays the health of the bot's components",
+			},
+			{
+				Usage:    "!bawt audit [user] [since]",
+				HelpText: "Displays the audit log of privileged actions, optionally for a user and since a duration (`24h`, `7d`) or date (`2006-01-02`)",
+			},
+			{
+				Usage:    "!bawt responses list|reload",
+				HelpText: "Lists the response packs, or reloads them from `responses_path`",
+			},
+			{
+				Usage:    "!bawt mood",
+				HelpText: "Displays the mood of the bot, globally and per channel",
+			},
+			{
+				Usage:    "!bawt mood set <mood> [here|#channel] [duration]",
+				HelpText: "Sets the mood, globally or for a channel, optionally for a duration like `2h`",
+			},
+			{
+				Usage:    "!bawt mood reset [here|#channel]",
+				HelpText: "Goes back to the usual mood, globally or for a channel",
+			},
 			{
 				Usage:    "!bawt group list",
 				HelpText: "Displays a list of groups",
@@ -93,14 +130,79 @@ func (h *Help) listenHelp() {
 
 // It's important to remember that the global help is and always will be opt-in
 func (h *Help) handleHelp(listen *bawt.Listener, msg *bawt.Message) {
+	args := strings.Fields(msg.Text)
+	if len(args) == 0 || args[0] != "!help" {
+		return
+	}
+	args = args[1:]
+
 	msg.AddReaction("+1") // Let the user know we're processing their request
-	listeners := h.bot.Listeners()
 
-	for _, l := range listeners {
-		for _, c := range l.Commands {
-			msg.Reply("%s\t\t%s", c.Usage, c.HelpText)
+	dm := false
+	if len(args) > 0 && strings.EqualFold(args[len(args)-1], "dm") {
+		dm = true
+		args = args[:len(args)-1]
+	}
+
+	page := 1
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[len(args)-1]); err == nil {
+			page = n
+			args = args[:len(args)-1]
+		}
+	}
+
+	topics := buildTopics(h.bot.Listeners(), bawt.AdminCommands(), allowedFor(msg.FromUser))
+	if len(topics) == 0 {
+		msg.ReplyT("help.empty")
+		return
+	}
+
+	var header string
+	var lines []string
+	next := "!help " + strings.Join(args, " ")
+
+	switch {
+	case len(args) == 0:
+		header = msg.T("help.index")
+		lines = renderIndex(topics)
+	case len(args) == 1 && args[0] == "all":
+		lines = renderTopics(topics)
+	default:
+		query := strings.Join(args, " ")
+		found, suggestion := findTopics(topics, query)
+		if len(found) == 0 {
+			if suggestion != "" {
+				msg.ReplyT("help.did_you_mean", query, suggestion)
+				return
+			}
+			msg.ReplyT("help.not_found", query)
+			return
 		}
+		lines = renderTopics(found)
+	}
+
+	body, page, pages := paginate(lines, page)
+	if header != "" {
+		body = append([]string{header}, body...)
+	}
+	if pages > 1 {
+		if page < pages {
+			body = append(body, msg.T("help.page_next", page, pages, strings.TrimSpace(next), page+1))
+		} else {
+			body = append(body, msg.T("help.page", page, pages))
+		}
+	}
+
+	text := strings.Join(body, "\n")
+
+	if dm && !msg.IsPrivate() {
+		msg.ReplyPrivately("%s", text)
+		msg.ReplyT("help.sent_dm")
+		return
 	}
+
+	msg.Reply("%s", text)
 }
 
 func (h *Help) handleApps(listen *bawt.Listener, msg *bawt.Message) {
@@ -129,9 +231,12 @@ func (h *Help) handleApps(listen *bawt.Listener, msg *bawt.Message) {
 		}
 	}
 
+	lines := make([]string, 0, len(apps))
 	for _, a := range apps {
-		msg.Reply("%s\t\t%s", a.name, a.description)
+		lines = append(lines, fmt.Sprintf("*%s* — %s", a.name, a.description))
 	}
+
+	msg.Reply("%s", strings.Join(lines, "\n"))
 }
 
 func (h *Help) handleBawt(listen *bawt.Listener, msg *bawt.Message) {
@@ -145,15 +250,17 @@ func (h *Help) handleBawt(listen *bawt.Listener, msg *bawt.Message) {
 	parts := strings.Split(msg.Match[0], " ")
 
 	if len(parts) == 1 {
-		msg.Reply("Looks like you're missing an argument! Maybe consider `!help`?")
+		msg.ReplyT("bawt.missing_argument")
 		return
 	}
 
 	a := parts[action]
 
 	switch a {
+	case "status":
+		msg.Reply("```%s```", h.bot.Status.String())
 	case "version":
-		msg.Reply("*bawt* `v%s` (Release Notes: https://github.com/gopherworks/bawt/releases/tag/v%s)", bawt.Version, bawt.Version)
+		msg.ReplyT("bawt.version", bawt.Version)
 	case "dump-config":
 		s := spew.ConfigState{
 			Indent: "\t",
@@ -165,11 +272,18 @@ func (h *Help) handleBawt(listen *bawt.Listener, msg *bawt.Message) {
 			Filetype:       "Go",
 			Filename:       "bot.go",
 			Title:          "bawt.Bot{}",
-			InitialComment: "This is a live snapshot of my config. This may contain sensitive data.",
+			InitialComment: msg.T("bawt.dump_config"),
 		}
 		p.Channels = append(p.Channels, msg.FromChannel.ID)
 
 		msg.ReplyWithFile(p)
+		h.audit(msg, "bawt:dump-config", msg.FromChannel.ID, nil, bawt.AuditSuccess, nil)
+	case "audit":
+		h.handleAudit(listen, msg)
+	case "responses":
+		h.handleResponses(listen, msg)
+	case "mood":
+		h.handleMood(listen, msg)
 	case "whois":
 
 		u := parts[user]
@@ -179,31 +293,35 @@ func (h *Help) handleBawt(listen *bawt.Listener, msg *bawt.Message) {
 		if err != nil {
 			// We've reached an error
 			log.WithError(err).Errorf("Error retrieving user info for %s", u)
-			msg.Reply("User not found")
+			msg.ReplyT("bawt.user_not_found")
 
 			return
 		}
 
 		// We found the user
-		msg.Reply("Their user ID is %s", usr.ID)
+		msg.ReplyT("bawt.whois", usr.ID)
 	case "whoami":
 		u := msg.FromUser
 
-		msg.Reply("Your real name is %s (User: %s/ID: %s). You live in the %s timezone. Admin: %b; Owner: %b; Primary Owner: %b", u.RealName, u.Name, u.ID, u.TZLabel, u.IsAdmin, u.IsOwner, u.IsPrimaryOwner)
+		msg.ReplyT("bawt.whoami", u.RealName, u.Name, u.ID, u.TZLabel, u.IsAdmin, u.IsOwner, u.IsPrimaryOwner)
 	case "channels":
 		chans := []string{}
 
-		for _, c := range h.bot.Channels {
+		for _, c := range h.bot.Channels.Snapshot() {
 			if c.IsChannel {
 				chans = append(chans, c.Name)
 			}
 		}
 
-		msg.Reply("I'm in the following channels: %s", strings.Join(chans, ", "))
+		msg.ReplyT("bawt.channels", strings.Join(chans, ", "))
 	case "group":
 		h.handleGroup(listen, msg)
 	default:
-		msg.Reply("I didn't recognize that argument! Maybe consider `!help`?")
+		if cmd, ok := bawt.FindAdminCommand(a); ok {
+			cmd.Handler(listen, msg)
+			return
+		}
+		msg.ReplyT("bawt.unknown_argument")
 	}
 }
 
@@ -216,8 +334,8 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 
 	parts := strings.Split(msg.Match[0], " ")
 
-	if len(parts) == 2 {
-		msg.Reply("Looks like you're missing an argument! Maybe consider `!help`?")
+	if len(parts) <= user {
+		msg.ReplyT("bawt.missing_argument")
 		return
 	}
 
@@ -225,6 +343,9 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 	a := parts[action]
 	u := bawt.NormalizeID(parts[user])
 
+	auditAction := "group:" + a
+	params := map[string]string{"user": u}
+
 	switch a {
 	case "add-user":
 		g := bawt.InternalGroup{
@@ -236,16 +357,19 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 		member, err := g.IsUserMember(h.bot.DB, msg.FromUser.ID)
 		if err != nil {
 			log.WithError(err).Error("Error determing user membership")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditFailure, err)
 			return
 		}
 
 		if !member {
 			msg.Reply("You don't have the proper permissions to do that.")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditDenied, nil)
 			return
 		}
 
 		if g.Name == "GlobalAdmins" {
 			msg.Reply("GlobalAdmins cannot be modified via chat.")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditDenied, nil)
 			return
 		}
 
@@ -254,7 +378,14 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 			return
 		}
 
-		g.AddMember(h.bot.DB, u)
+		err = g.AddMember(h.bot.DB, u)
+		if err != nil {
+			log.WithError(err).Error("Error adding user to group")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditFailure, err)
+			return
+		}
+
+		h.audit(msg, auditAction, g.Name, params, bawt.AuditSuccess, nil)
 	case "remove-user":
 		g := bawt.InternalGroup{
 			Name: g,
@@ -264,16 +395,19 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 
 		member, err := g.IsUserMember(h.bot.DB, msg.FromUser.ID)
 		if err != nil {
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditFailure, err)
 			return
 		}
 
 		if !member {
 			msg.Reply("You don't have the proper permissions to do that.")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditDenied, nil)
 			return
 		}
 
 		if g.Name == "GlobalAdmins" {
 			msg.Reply("GlobalAdmins cannot be modified via chat.")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditDenied, nil)
 			return
 		}
 
@@ -284,10 +418,18 @@ func (h *Help) handleGroup(listen *bawt.Listener, msg *bawt.Message) {
 
 		if u == msg.FromUser.ID {
 			msg.Reply("You cannot remove yourself from a group.")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditDenied, nil)
+			return
+		}
+
+		err = g.RemoveMember(h.bot.DB, u)
+		if err != nil {
+			log.WithError(err).Error("Error removing user from group")
+			h.audit(msg, auditAction, g.Name, params, bawt.AuditFailure, err)
 			return
 		}
 
-		g.RemoveMember(h.bot.DB, u)
+		h.audit(msg, auditAction, g.Name, params, bawt.AuditSuccess, nil)
 	default:
 		msg.Reply("I didn't understand your message.")
 	}
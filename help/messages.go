@@ -0,0 +1,23 @@
+package help
+
+import "github.com/gopherworks/bawt"
+
+func init() {
+	bawt.RegisterMessages(bawt.DefaultLocale, map[string]bawt.Translation{
+		"bawt.missing_argument": {Other: "Looks like you're missing an argument! Maybe consider `!help`?"},
+		"bawt.unknown_argument": {Other: "I didn't recognize that argument! Maybe consider `!help`?"},
+		"bawt.version":          {Other: "*bawt* `v%[1]s` (Release Notes: https://github.com/gopherworks/bawt/releases/tag/v%[1]s)"},
+		"bawt.dump_config":      {Other: "This is a live snapshot of my config. This may contain sensitive data."},
+		"bawt.user_not_found":   {Other: "User not found"},
+		"bawt.whois":            {Other: "Their user ID is %s"},
+		"bawt.whoami":           {Other: "Your real name is %s (User: %s/ID: %s). You live in the %s timezone. Admin: %t; Owner: %t; Primary Owner: %t"},
+		"bawt.channels":         {Other: "I'm in the following channels: %s"},
+		"help.index":            {Other: "*Help topics* — `!help <topic>` for its commands, `!help all` for everything, add `dm` to get it privately"},
+		"help.page":             {Other: "_Page %d of %d_"},
+		"help.page_next":        {Other: "_Page %d of %d — `%s %d` for the next one_"},
+		"help.not_found":        {Other: "I couldn't find any help about `%s`."},
+		"help.did_you_mean":     {Other: "I couldn't find any help about `%s`. Did you mean `%s`?"},
+		"help.sent_dm":          {Other: "I've sent you the help privately."},
+		"help.empty":            {Other: "There are no commands available to you."},
+	})
+}
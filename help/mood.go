@@ -0,0 +1,147 @@
+package help
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// handleMood views or sets the mood:
+// `!bawt mood [set <mood> [here|#channel] [duration] | reset [here|#channel]]`
+func (h *Help) handleMood(listen *bawt.Listener, msg *bawt.Message) {
+	parts := strings.Fields(msg.Match[0])[2:]
+
+	if len(parts) == 0 {
+		h.replyMood(msg)
+		return
+	}
+
+	switch parts[0] {
+	case "set":
+		if len(parts) < 2 {
+			msg.Reply("Use `!bawt mood set <mood> [here|#channel] [duration]`, with one of: %s", joinMoods(h.bot.Moods.Known()))
+			return
+		}
+		h.setMood(msg, bawt.Mood(parts[1]), parts[2:])
+
+	case "reset":
+		h.setMood(msg, "", parts[1:])
+
+	default:
+		msg.Reply("Use `!bawt mood`, `!bawt mood set <mood> [here|#channel] [duration]` or `!bawt mood reset [here|#channel]`")
+	}
+}
+
+func (h *Help) replyMood(msg *bawt.Message) {
+	moods := h.bot.Moods
+
+	lines := []string{fmt.Sprintf("I'm feeling *%s*.", moods.Current())}
+
+	if forced, until := moods.Forced(); forced != "" {
+		if until.IsZero() {
+			lines = append(lines, "That mood was set by an admin, until reset.")
+		} else {
+			lines = append(lines, fmt.Sprintf("That mood was set by an admin, until %s.", until.Format("2006-01-02 15:04")))
+		}
+	}
+
+	if m := msg.Mood(); m != moods.Current() {
+		lines = append(lines, fmt.Sprintf("In this channel, I'm feeling *%s*.", m))
+	}
+
+	overrides := moods.Overrides()
+	if len(overrides) > 0 {
+		var channels []string
+		for channel := range overrides {
+			channels = append(channels, channel)
+		}
+		sort.Strings(channels)
+
+		lines = append(lines, "Channel moods:")
+		for _, channel := range channels {
+			name := channel
+			if c, ok := h.bot.Channels.Get(strings.ToUpper(channel)); ok {
+				name = c.Name
+			}
+			lines = append(lines, fmt.Sprintf("• #%s: %s", name, overrides[channel]))
+		}
+	}
+
+	lines = append(lines, fmt.Sprintf("Known moods: %s", joinMoods(moods.Known())))
+
+	msg.Reply(strings.Join(lines, "\n"))
+}
+
+// setMood forces the global mood, or overrides a channel's. An empty
+// mood resets it.
+func (h *Help) setMood(msg *bawt.Message, mood bawt.Mood, args []string) {
+	channel := ""
+	var until time.Time
+
+	for _, arg := range args {
+		switch {
+		case arg == "here":
+			channel = msg.Channel
+		case strings.HasPrefix(arg, "<#"):
+			channel = bawt.NormalizeID(arg)
+		case strings.HasPrefix(arg, "#"):
+			channel = arg
+		default:
+			d, err := time.ParseDuration(arg)
+			if err != nil || d <= 0 {
+				msg.Reply("I don't understand `%s`, use `here`, a #channel or a duration like `2h`.", arg)
+				return
+			}
+			until = time.Now().Add(d)
+		}
+	}
+
+	action := "bawt:mood-set"
+	if mood == "" {
+		action = "bawt:mood-reset"
+	}
+	target := channel
+	if target == "" {
+		target = "global"
+	}
+	params := map[string]string{"mood": string(mood)}
+
+	var err error
+	if channel != "" {
+		err = h.bot.Moods.SetChannel(channel, mood, "set by "+msg.FromUser.Name)
+	} else {
+		err = h.bot.Moods.Force(mood, until, "set by "+msg.FromUser.Name)
+	}
+
+	if err != nil {
+		h.audit(msg, action, target, params, bawt.AuditFailure, err)
+		msg.Reply("I couldn't change my mood: %s. Known moods: %s", err, joinMoods(h.bot.Moods.Known()))
+		return
+	}
+
+	h.audit(msg, action, target, params, bawt.AuditSuccess, nil)
+
+	switch {
+	case mood == "" && channel != "":
+		msg.Reply("I'm back to my usual mood in that channel.")
+	case mood == "":
+		msg.Reply("I'm back to my usual mood, *%s*.", h.bot.Moods.Current())
+	case channel != "":
+		msg.Reply("I'm now feeling *%s* in that channel.", mood)
+	case !until.IsZero():
+		msg.Reply("I'm now feeling *%s*, until %s.", mood, until.Format("2006-01-02 15:04"))
+	default:
+		msg.Reply("I'm now feeling *%s*, until `!bawt mood reset`.", mood)
+	}
+}
+
+func joinMoods(moods []bawt.Mood) string {
+	names := make([]string, 0, len(moods))
+	for _, m := range moods {
+		names = append(names, "`"+string(m)+"`")
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,50 @@
+package help
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gopherworks/bawt"
+)
+
+// handleResponses lists or reloads the response packs:
+// `!bawt responses list|reload`
+func (h *Help) handleResponses(listen *bawt.Listener, msg *bawt.Message) {
+	parts := strings.Fields(msg.Match[0])
+
+	sub := "list"
+	if len(parts) > 2 {
+		sub = parts[2]
+	}
+
+	store := bawt.ResponseStore()
+
+	switch sub {
+	case "list":
+		packs := store.Packs()
+		if len(packs) == 0 {
+			msg.Reply("No response packs registered.")
+			return
+		}
+
+		lines := []string{"Response packs:"}
+		for _, p := range packs {
+			lines = append(lines, fmt.Sprintf("• `%s`: %d responses (%s)", p.Category, p.Count, p.Source))
+		}
+		msg.Reply(strings.Join(lines, "\n"))
+
+	case "reload":
+		err := store.Reload()
+		if err != nil {
+			h.audit(msg, "bawt:responses-reload", "", nil, bawt.AuditFailure, err)
+			msg.Reply("I couldn't reload the response packs: %s", err)
+			return
+		}
+
+		h.audit(msg, "bawt:responses-reload", "", nil, bawt.AuditSuccess, nil)
+		msg.Reply("Reloaded %d response packs.", len(store.Packs()))
+
+	default:
+		msg.Reply("Use `!bawt responses list` or `!bawt responses reload`")
+	}
+}
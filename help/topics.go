@@ -0,0 +1,161 @@
+package help
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+)
+
+// pageSize is the number of lines of a single help message
+const pageSize = 40
+
+// buildTopics groups the commands of the listeners `allowed` lets through
+// by plugin, with the admin commands folded into the `bawt` topic
+func buildTopics(listeners []*bawt.Listener, admin []bawt.AdminCommand, allowed func(*bawt.Listener) bool) []bawt.PluginReference {
+	var kept []*bawt.Listener
+	for _, l := range listeners {
+		if allowed(l) {
+			kept = append(kept, l)
+		}
+	}
+
+	return bawt.PluginReferences(kept, admin)
+}
+
+// findTopics looks up a query, trying in turn an exact slug or name, a
+// slug prefix, then the usages and help texts of the commands. For the
+// latter, only the matching commands are kept. When nothing matches, the
+// closest slug is returned as a suggestion.
+func findTopics(topics []bawt.PluginReference, query string) ([]bawt.PluginReference, string) {
+	q := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query), "!"))
+	if q == "" {
+		return nil, ""
+	}
+
+	for _, t := range topics {
+		if t.Slug == q || strings.ToLower(t.Name) == q {
+			return []bawt.PluginReference{t}, ""
+		}
+	}
+
+	var found []bawt.PluginReference
+	for _, t := range topics {
+		if strings.HasPrefix(t.Slug, q) {
+			found = append(found, t)
+		}
+	}
+	if len(found) > 0 {
+		return found, ""
+	}
+
+	for _, t := range topics {
+		var cmds []bawt.CommandReference
+		for _, c := range t.Commands {
+			usage := strings.ToLower(strings.TrimPrefix(c.Usage, "!"))
+			if strings.Contains(usage, q) || strings.Contains(strings.ToLower(c.HelpText), q) {
+				cmds = append(cmds, c)
+			}
+		}
+		if len(cmds) > 0 {
+			t.Commands = cmds
+			found = append(found, t)
+		}
+	}
+	if len(found) > 0 {
+		return found, ""
+	}
+
+	best, bestDist := "", len(q)/3+2
+	for _, t := range topics {
+		if d := levenshtein(q, t.Slug); d < bestDist {
+			best, bestDist = t.Slug, d
+		}
+	}
+
+	return nil, best
+}
+
+// levenshtein is the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j] + 1
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// renderIndex lists the topics, one line each
+func renderIndex(topics []bawt.PluginReference) []string {
+	lines := make([]string, 0, len(topics))
+	for _, t := range topics {
+		lines = append(lines, fmt.Sprintf("• `%s` *%s* — %s", t.Slug, t.Name, t.Description))
+	}
+	return lines
+}
+
+// renderTopics lists the commands of the topics, grouped by topic
+func renderTopics(topics []bawt.PluginReference) []string {
+	var lines []string
+	for i, t := range topics {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, fmt.Sprintf("*%s* (`%s`) — %s", t.Name, t.Slug, t.Description))
+		for _, c := range t.Commands {
+			lines = append(lines, fmt.Sprintf("`%s`  %s", c.Usage, c.HelpText))
+		}
+	}
+	return lines
+}
+
+// paginate splits lines into pages of at most pageSize lines, and returns
+// the requested page, 1-based and clamped, along with the page count.
+func paginate(lines []string, page int) ([]string, int, int) {
+	pages := (len(lines) + pageSize - 1) / pageSize
+	if pages == 0 {
+		return nil, 1, 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > pages {
+		page = pages
+	}
+
+	end := page * pageSize
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	return lines[(page-1)*pageSize : end], page, pages
+}
+
+// allowedFor returns a filter keeping the listeners user can trigger
+func allowedFor(user *slack.User) func(*bawt.Listener) bool {
+	return func(l *bawt.Listener) bool {
+		return l.AllowsUser(user)
+	}
+}
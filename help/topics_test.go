@@ -0,0 +1,133 @@
+package help
+
+import (
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/stretchr/testify/assert"
+)
+
+func testListeners() []*bawt.Listener {
+	return []*bawt.Listener{
+		{
+			Name:        "To Do",
+			Description: "Keeps a tab of all your to do's!",
+			Commands: []bawt.Command{
+				{Usage: "!todo", HelpText: "List the tasks of the channel"},
+				{Usage: "!todo add <task>", HelpText: "Add a task"},
+			},
+		},
+		{
+			Name:        "To Do",
+			Description: "Keeps a tab of all your to do's!",
+			Commands: []bawt.Command{
+				{Usage: "!todo add <task>", HelpText: "Add a task"},
+				{Usage: "!todo close <id>", HelpText: "Close a task"},
+			},
+		},
+		{
+			Name:        "Faceoff",
+			Description: "A game",
+			Commands: []bawt.Command{
+				{Usage: "!faceoff", HelpText: "Start a game"},
+			},
+		},
+		{
+			Name:              "Admin",
+			Slug:              "bawt",
+			Description:       "Administers the bot",
+			FromInternalGroup: []string{"GlobalAdmins"},
+			Commands: []bawt.Command{
+				{Usage: "!bawt status", HelpText: "Displays the health of the bot's components"},
+			},
+		},
+		{
+			Name: "Silent",
+		},
+	}
+}
+
+func everyone(*bawt.Listener) bool { return true }
+
+func TestBuildTopics(t *testing.T) {
+	admin := []bawt.AdminCommand{{
+		Name:     "webhooks",
+		Commands: []bawt.Command{{Usage: "!bawt webhooks", HelpText: "Lists the dead deliveries"}},
+	}}
+
+	topics := buildTopics(testListeners(), admin, everyone)
+
+	if assert.Len(t, topics, 3) {
+		assert.Equal(t, "bawt", topics[0].Slug)
+		assert.Len(t, topics[0].Commands, 2)
+		assert.Equal(t, "faceoff", topics[1].Slug)
+		assert.Equal(t, "todo", topics[2].Slug)
+		assert.Len(t, topics[2].Commands, 3)
+	}
+
+	noAdmins := func(l *bawt.Listener) bool { return len(l.FromInternalGroup) == 0 }
+	topics = buildTopics(testListeners(), admin, noAdmins)
+	assert.Len(t, topics, 2)
+}
+
+func TestFindTopics(t *testing.T) {
+	topics := buildTopics(testListeners(), nil, everyone)
+
+	tests := []struct {
+		name       string
+		query      string
+		slugs      []string
+		commands   int
+		suggestion string
+	}{
+		{name: "slug", query: "todo", slugs: []string{"todo"}, commands: 3},
+		{name: "name", query: "To Do", slugs: []string{"todo"}, commands: 3},
+		{name: "command", query: "!faceoff", slugs: []string{"faceoff"}, commands: 1},
+		{name: "prefix", query: "face", slugs: []string{"faceoff"}, commands: 1},
+		{name: "usage", query: "close", slugs: []string{"todo"}, commands: 1},
+		{name: "help text", query: "health", slugs: []string{"bawt"}, commands: 1},
+		{name: "typo", query: "fceoff", suggestion: "faceoff"},
+		{name: "nothing", query: "weather"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, suggestion := findTopics(topics, test.query)
+			assert.Equal(t, test.suggestion, suggestion)
+
+			var slugs []string
+			commands := 0
+			for _, tp := range found {
+				slugs = append(slugs, tp.Slug)
+				commands += len(tp.Commands)
+			}
+			assert.Equal(t, test.slugs, slugs)
+			assert.Equal(t, test.commands, commands)
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("todo", "todo"))
+	assert.Equal(t, 1, levenshtein("tod", "todo"))
+	assert.Equal(t, 2, levenshtein("stnadup", "standup"))
+	assert.Equal(t, 4, levenshtein("", "bawt"))
+}
+
+func TestPaginate(t *testing.T) {
+	lines := make([]string, pageSize+5)
+
+	page, n, pages := paginate(lines, 0)
+	assert.Len(t, page, pageSize)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, pages)
+
+	page, n, _ = paginate(lines, 9)
+	assert.Len(t, page, 5)
+	assert.Equal(t, 2, n)
+
+	page, n, pages = paginate(nil, 3)
+	assert.Empty(t, page)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, pages)
+}
@@ -0,0 +1,672 @@
+package hooker
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+)
+
+/*
+AlertsConfig is the `hooker.alerts` section of the configuration, for the
+receiver of Prometheus Alertmanager (`/public/alertmanager`) and Monit
+(`/public/monit`) alerts:
+
+	hooker:
+	  alerts:
+	    token: s3cr3t
+	    channel: "#alerts"
+	    routes:
+	      - match: {severity: critical}
+	        channel: "#oncall"
+	    oncall_group: oncall
+	    silence_duration: 4h
+
+Alerts are grouped like Alertmanager groups them, or by host and service
+for Monit, and every group is a single message, updated as its alerts
+fire and resolve. Reacting with AckEmoji acknowledges a group, and with
+SilenceEmoji keeps it from being posted again for SilenceDuration.
+*/
+type AlertsConfig struct {
+	Token           string       `json:"-" mapstructure:"token"` // Expected in the `token` query parameter, the X-Hook-Token header or as a bearer token. Required.
+	Channel         string       `json:"channel" mapstructure:"channel"`
+	Workspace       string       `json:"workspace" mapstructure:"workspace"`
+	Routes          []AlertRoute `json:"routes" mapstructure:"routes"`                     // The first route matching the labels of a group wins
+	OncallGroup     string       `json:"oncall_group" mapstructure:"oncall_group"`         // Internal group allowed to acknowledge and silence. Anyone, when empty.
+	AckEmoji        string       `json:"ack_emoji" mapstructure:"ack_emoji"`               // Defaults to `eyes`
+	SilenceEmoji    string       `json:"silence_emoji" mapstructure:"silence_emoji"`       // Defaults to `mute`
+	SilenceDuration string       `json:"silence_duration" mapstructure:"silence_duration"` // Defaults to 4h
+	MonitResolved   string       `json:"monit_resolved" mapstructure:"monit_resolved"`     // Regexp of the Monit alerts that resolve a service
+}
+
+// AlertRoute sends the groups whose labels have all the Match values to
+// a channel
+type AlertRoute struct {
+	Match     map[string]string `json:"match" mapstructure:"match"`
+	Channel   string            `json:"channel" mapstructure:"channel"`
+	Workspace string            `json:"workspace" mapstructure:"workspace"`
+}
+
+// errAlertsToken keeps the receivers from being mounted without a token
+var errAlertsToken = errors.New("`alerts.token` isn't set")
+
+const (
+	defaultAckEmoji        = "eyes"
+	defaultSilenceEmoji    = "mute"
+	defaultSilenceDuration = 4 * time.Hour
+	defaultMonitResolved   = `(?i)(succeeded|recovered|resolved|^exists|^running)`
+
+	// alertRetention is how long resolved groups are kept
+	alertRetention = 24 * time.Hour
+)
+
+// Status of an Alert
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Alert is a single alert, in the format of Alertmanager
+type Alert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+// AlertGroup is a set of alerts posted as one message
+type AlertGroup struct {
+	Key       string            `json:"key"`
+	Source    string            `json:"source"`
+	Title     string            `json:"title"`
+	Labels    map[string]string `json:"labels"`
+	URL       string            `json:"url,omitempty"`
+	Alerts    map[string]*Alert `json:"alerts"` // by fingerprint
+	UpdatedAt time.Time         `json:"updated_at"`
+
+	// Where it was posted
+	Channel   string `json:"channel"`
+	Workspace string `json:"workspace,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	TS        string `json:"ts,omitempty"`
+
+	AckedBy       string    `json:"acked_by,omitempty"`
+	AckedAt       time.Time `json:"acked_at,omitempty"`
+	SilencedBy    string    `json:"silenced_by,omitempty"`
+	SilencedUntil time.Time `json:"silenced_until,omitempty"`
+}
+
+// Firing returns the number of firing alerts
+func (g *AlertGroup) Firing() int {
+	n := 0
+	for _, a := range g.Alerts {
+		if a.Status != AlertResolved {
+			n++
+		}
+	}
+	return n
+}
+
+// Silenced tells whether the group is silenced at the given time
+func (g *AlertGroup) Silenced(now time.Time) bool {
+	return now.Before(g.SilencedUntil)
+}
+
+// sortedAlerts returns the alerts by start time
+func (g *AlertGroup) sortedAlerts() []*Alert {
+	out := make([]*Alert, 0, len(g.Alerts))
+	for _, a := range g.Alerts {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartsAt.Equal(out[j].StartsAt) {
+			return out[i].StartsAt.Before(out[j].StartsAt)
+		}
+		return out[i].Fingerprint < out[j].Fingerprint
+	})
+	return out
+}
+
+// alertmanagerPayload is the body of the webhooks of Alertmanager
+type alertmanagerPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []*Alert          `json:"alerts"`
+}
+
+// alerter receives the alerts and keeps their messages up to date
+type alerter struct {
+	bot      *bawt.Bot
+	config   AlertsConfig
+	store    *alertStore
+	resolved *regexp.Regexp
+	silence  time.Duration
+
+	// notify posts a group as a new message when `post` is set, or
+	// updates its message
+	notify func(g *AlertGroup, text string, post bool) error
+
+	// lock serializes the changes to the groups
+	lock    sync.Mutex
+	replies map[string]*bawt.UpdateableReply // by group key, for the messages posted since startup
+}
+
+func newAlerter(bot *bawt.Bot, config AlertsConfig) (*alerter, error) {
+	if config.Token == "" {
+		return nil, errAlertsToken
+	}
+
+	a := &alerter{
+		bot:     bot,
+		config:  config,
+		store:   &alertStore{db: bot.DB},
+		silence: defaultSilenceDuration,
+		replies: make(map[string]*bawt.UpdateableReply),
+	}
+	a.notify = a.notifySlack
+
+	if a.config.Channel == "" {
+		a.config.Channel = bot.Config.GeneralChannel
+	}
+	if a.config.AckEmoji == "" {
+		a.config.AckEmoji = defaultAckEmoji
+	}
+	if a.config.SilenceEmoji == "" {
+		a.config.SilenceEmoji = defaultSilenceEmoji
+	}
+	a.config.AckEmoji = strings.Trim(a.config.AckEmoji, ":")
+	a.config.SilenceEmoji = strings.Trim(a.config.SilenceEmoji, ":")
+
+	if a.config.SilenceDuration != "" {
+		d, err := time.ParseDuration(a.config.SilenceDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid silence_duration: %s", err)
+		}
+		a.silence = d
+	}
+
+	expr := a.config.MonitResolved
+	if expr == "" {
+		expr = defaultMonitResolved
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid monit_resolved: %s", err)
+	}
+	a.resolved = re
+
+	if err := bot.DB.Update(createAlertBuckets); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// authorized checks the token of a request
+func (a *alerter) authorized(r *http.Request) bool {
+	if a.config.Token == "" {
+		return false
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get(tokenHeader)
+	}
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.config.Token)) == 1
+}
+
+// onAlertmanager serves the webhooks of Alertmanager
+func (a *alerter) onAlertmanager(w http.ResponseWriter, r *http.Request) {
+	var payload alertmanagerPayload
+	if !a.decode(w, r, &payload) {
+		return
+	}
+
+	labels := make(map[string]string)
+	for k, v := range payload.CommonLabels {
+		labels[k] = v
+	}
+	for k, v := range payload.GroupLabels {
+		labels[k] = v
+	}
+	labels["receiver"] = payload.Receiver
+
+	key := payload.GroupKey
+	if key == "" {
+		key = fingerprint(payload.GroupLabels)
+	}
+
+	group := &AlertGroup{
+		Key:    "alertmanager/" + key,
+		Source: "alertmanager",
+		Title:  alertTitle(payload.GroupLabels, payload.CommonLabels),
+		Labels: labels,
+		URL:    payload.ExternalURL,
+		Alerts: make(map[string]*Alert),
+	}
+	for _, alert := range payload.Alerts {
+		if alert.Fingerprint == "" {
+			alert.Fingerprint = fingerprint(alert.Labels)
+		}
+		group.Alerts[alert.Fingerprint] = alert
+	}
+
+	a.respond(w, group)
+}
+
+// onMonit serves the alerts of Monit, sent as MonitAlert by a script
+func (a *alerter) onMonit(w http.ResponseWriter, r *http.Request) {
+	var alert MonitAlert
+	if !a.decode(w, r, &alert) {
+		return
+	}
+
+	labels := map[string]string{"source": "monit", "host": alert.Host, "service": alert.Service, "alertname": alert.Service}
+
+	status := AlertFiring
+	if a.resolved.MatchString(strings.TrimSpace(alert.Alert)) {
+		status = AlertResolved
+	}
+
+	startsAt, err := time.Parse(time.RFC1123Z, alert.Date)
+	if err != nil {
+		startsAt = time.Now()
+	}
+
+	fp := fingerprint(labels)
+	group := &AlertGroup{
+		Key:    "monit/" + fp,
+		Source: "monit",
+		Title:  fmt.Sprintf("%s on %s", alert.Service, alert.Host),
+		Labels: labels,
+		Alerts: map[string]*Alert{fp: {
+			Fingerprint: fp,
+			Status:      status,
+			Labels:      labels,
+			Annotations: map[string]string{"summary": alert.Alert},
+			StartsAt:    startsAt,
+		}},
+	}
+
+	a.respond(w, group)
+}
+
+// decode checks and reads the body of an alert
+func (a *alerter) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != "POST" {
+		http.Error(w, "Method not accepted", 405)
+		return false
+	}
+	if !a.authorized(r) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return false
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "unable to read the body", http.StatusBadRequest)
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON: %s", err), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (a *alerter) respond(w http.ResponseWriter, group *AlertGroup) {
+	if err := a.receive(group, time.Now()); err != nil {
+		a.bot.ReportError("hooker", fmt.Errorf("unable to handle alerts of %s: %s", group.Key, err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+/*
+receive merges incoming alerts into their group, then posts it when it
+starts firing, or updates its message when an alert changes. Alerts sent
+again without changes, like Alertmanager does on every repeat interval,
+change nothing.
+*/
+func (a *alerter) receive(in *AlertGroup, now time.Time) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	g, err := a.store.Get(in.Key)
+	if err != nil {
+		return err
+	}
+
+	if g == nil {
+		if in.Firing() == 0 {
+			return nil
+		}
+
+		// Possibly pruned since it was posted
+		delete(a.replies, in.Key)
+
+		g = &AlertGroup{Key: in.Key, Source: in.Source, Alerts: make(map[string]*Alert)}
+		g.Labels = in.Labels
+		a.route(g)
+	} else if g.Firing() == 0 && in.Firing() > 0 {
+		// A resolved group firing again is a new incident
+		if g.TS != "" {
+			if err := a.store.Forget(g.ChannelID, g.TS); err != nil {
+				return err
+			}
+		}
+		delete(a.replies, g.Key)
+
+		g.Alerts = make(map[string]*Alert)
+		g.ChannelID, g.TS = "", ""
+		g.AckedBy, g.AckedAt = "", time.Time{}
+		g.Labels = in.Labels
+		a.route(g)
+	}
+
+	changed := false
+	for fp, alert := range in.Alerts {
+		old, ok := g.Alerts[fp]
+		if !ok || old.Status != alert.Status {
+			changed = true
+		}
+		g.Alerts[fp] = alert
+	}
+	g.Title, g.Labels, g.URL = in.Title, in.Labels, in.URL
+
+	if !changed {
+		return nil
+	}
+	g.UpdatedAt = now
+
+	if err := a.store.Put(g); err != nil {
+		return err
+	}
+
+	_, posted := a.replies[g.Key]
+	posted = posted || g.TS != ""
+
+	switch {
+	case posted:
+		return a.notify(g, a.render(g, now), false)
+	case g.Firing() > 0 && !g.Silenced(now):
+		return a.notify(g, a.render(g, now), true)
+	}
+
+	return nil
+}
+
+// route picks the channel of a group
+func (a *alerter) route(g *AlertGroup) {
+	g.Channel, g.Workspace = a.config.Channel, a.config.Workspace
+
+	for _, r := range a.config.Routes {
+		matches := true
+		for k, v := range r.Match {
+			if g.Labels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			g.Channel, g.Workspace = r.Channel, r.Workspace
+			return
+		}
+	}
+}
+
+// onReaction acknowledges or silences the group of a message
+func (a *alerter) onReaction(re *bawt.ReactionEvent) {
+	if re.Type != bawt.ReactionAdded || (re.Emoji != a.config.AckEmoji && re.Emoji != a.config.SilenceEmoji) {
+		return
+	}
+
+	key := a.store.ByMessage(re.Item.Channel, re.Item.Timestamp)
+	if key == "" {
+		return
+	}
+
+	action := "alerts:ack"
+	if re.Emoji == a.config.SilenceEmoji {
+		action = "alerts:silence"
+	}
+	entry := bawt.AuditEntry{Actor: re.User, Action: action, Target: key, Result: bawt.AuditSuccess}
+
+	if a.config.OncallGroup != "" {
+		member, err := bawt.InternalGroup{Name: a.config.OncallGroup}.IsUserMember(a.bot.DB, re.User)
+		if err != nil || !member {
+			entry.Result = bawt.AuditDenied
+			a.bot.Audit(entry)
+			return
+		}
+	}
+
+	if err := a.react(key, re.User, re.Emoji, time.Now()); err != nil {
+		entry.Result = bawt.AuditFailure
+		entry.Error = err.Error()
+		a.bot.ReportError("hooker", err)
+	}
+	a.bot.Audit(entry)
+}
+
+// react applies an acknowledgement or a silence to a group
+func (a *alerter) react(key, user, emoji string, now time.Time) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	g, err := a.store.Get(key)
+	if err != nil || g == nil {
+		return err
+	}
+
+	if emoji == a.config.SilenceEmoji {
+		g.SilencedBy = user
+		g.SilencedUntil = now.Add(a.silence)
+	} else {
+		g.AckedBy = user
+		g.AckedAt = now
+	}
+
+	if err := a.store.Put(g); err != nil {
+		return err
+	}
+
+	return a.notify(g, a.render(g, now), false)
+}
+
+// posted records the message a group was posted as
+func (a *alerter) posted(key, channelID, ts string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	g, err := a.store.Get(key)
+	if err != nil || g == nil || g.TS != "" {
+		return
+	}
+
+	g.ChannelID, g.TS = channelID, ts
+	if err := a.store.Put(g); err != nil {
+		a.bot.ReportError("hooker", err)
+	}
+}
+
+// notifySlack posts or updates the message of a group
+func (a *alerter) notifySlack(g *AlertGroup, text string, post bool) error {
+	if !post {
+		if u, ok := a.replies[g.Key]; ok {
+			u.Update("%s", text)
+			return nil
+		}
+
+		bot := a.bot.Workspace(g.Workspace)
+		if bot == nil {
+			return fmt.Errorf("unknown workspace %q", g.Workspace)
+		}
+		bot.UpdateableMessage(g.ChannelID, g.TS).Update("%s", text)
+		return nil
+	}
+
+	bot := a.bot.Workspace(g.Workspace)
+	if bot == nil {
+		return fmt.Errorf("unknown workspace %q", g.Workspace)
+	}
+
+	reply := bot.SendToChannel(g.Channel, text)
+	if reply == nil {
+		return fmt.Errorf("unknown channel %q", g.Channel)
+	}
+
+	key := g.Key
+	reply.OnAck(func(ack *slack.AckMessage) {
+		a.posted(key, reply.Channel, ack.Timestamp)
+	})
+	a.replies[key] = reply.Updateable()
+
+	return nil
+}
+
+// render formats the message of a group
+func (a *alerter) render(g *AlertGroup, now time.Time) string {
+	var lines []string
+
+	if n := g.Firing(); n > 0 {
+		lines = append(lines, fmt.Sprintf(":rotating_light: *[FIRING:%d] %s*", n, g.Title))
+	} else {
+		lines = append(lines, fmt.Sprintf(":white_check_mark: *[RESOLVED] %s*", g.Title))
+	}
+
+	for _, alert := range g.sortedAlerts() {
+		text := alertText(alert)
+		if alert.GeneratorURL != "" {
+			text = link(alert.GeneratorURL, text)
+		}
+
+		if alert.Status == AlertResolved {
+			resolved := ""
+			if !alert.EndsAt.IsZero() {
+				resolved = " at " + alert.EndsAt.Local().Format("15:04")
+			}
+			lines = append(lines, fmt.Sprintf("• ~%s~ resolved%s", text, resolved))
+		} else {
+			lines = append(lines, fmt.Sprintf("• %s, since %s", text, alert.StartsAt.Local().Format("Jan 2 15:04")))
+		}
+	}
+
+	if g.AckedBy != "" {
+		lines = append(lines, fmt.Sprintf("Acknowledged by <@%s> at %s", g.AckedBy, g.AckedAt.Local().Format("15:04")))
+	}
+	if g.Silenced(now) {
+		lines = append(lines, fmt.Sprintf("Silenced by <@%s> until %s", g.SilencedBy, g.SilencedUntil.Local().Format("Jan 2 15:04")))
+	}
+
+	if g.Firing() > 0 && g.AckedBy == "" {
+		lines = append(lines, fmt.Sprintf("_React with :%s: to acknowledge, or :%s: to silence for %s._", a.config.AckEmoji, a.config.SilenceEmoji, a.silence))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// alertText describes an alert with its summary or its labels
+func alertText(alert *Alert) string {
+	for _, k := range []string{"summary", "description", "message"} {
+		if v := alert.Annotations[k]; v != "" {
+			return excerpt(v, 200)
+		}
+	}
+
+	keys := make([]string, 0, len(alert.Labels))
+	for k := range alert.Labels {
+		if k != "alertname" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, alert.Labels[k]))
+	}
+	return "`" + strings.Join(pairs, " ") + "`"
+}
+
+// alertTitle names a group after its alert name, or its labels
+func alertTitle(group, common map[string]string) string {
+	if name := common["alertname"]; name != "" {
+		return name
+	}
+	if name := group["alertname"]; name != "" {
+		return name
+	}
+
+	keys := make([]string, 0, len(group))
+	for k := range group {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, group[k])
+	}
+	if len(values) == 0 {
+		return "Alerts"
+	}
+	return strings.Join(values, " ")
+}
+
+// fingerprint identifies a label set, for alerts without a fingerprint
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, labels[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// pruneLoop drops the old resolved groups every hour
+func (a *alerter) pruneLoop() {
+	for {
+		a.lock.Lock()
+		_, err := a.store.Prune(time.Now().Add(-alertRetention))
+		a.lock.Unlock()
+
+		if err != nil {
+			a.bot.ReportError("hooker", fmt.Errorf("unable to prune alerts: %s", err))
+		}
+
+		time.Sleep(time.Hour)
+	}
+}
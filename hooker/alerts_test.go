@@ -0,0 +1,186 @@
+package hooker
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+type notification struct {
+	channel string
+	text    string
+	post    bool
+}
+
+func newTestAlerter(t *testing.T, config AlertsConfig) (*alerter, *mux.Router, *[]notification) {
+	hooker, _, _ := newTestHooker(t)
+	config.Token = "s3cr3t"
+
+	a, err := newAlerter(hooker.bot, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out []notification
+	a.notify = func(g *AlertGroup, text string, post bool) error {
+		out = append(out, notification{g.Channel, text, post})
+		if post {
+			// What the Ack of Slack does
+			go a.posted(g.Key, "C1", "1.1")
+		}
+		return nil
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/public/alertmanager", a.onAlertmanager)
+	router.HandleFunc("/public/monit", a.onMonit)
+
+	return a, router, &out
+}
+
+func sendAlert(t *testing.T, router *mux.Router, path, file string) int {
+	body, err := ioutil.ReadFile(filepath.Join("testdata", "alertmanager", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", path+"?token=s3cr3t", bytes.NewReader(body)))
+	return rec.Code
+}
+
+// waitPosted waits for the fake Ack of notify
+func waitPosted(a *alerter, key string) {
+	for i := 0; i < 100; i++ {
+		if a.store.ByMessage("C1", "1.1") == key {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func reactionOn(emoji, channel string) *bawt.ReactionEvent {
+	re := &bawt.ReactionEvent{Type: bawt.ReactionAdded, User: "U1", Emoji: emoji}
+	re.Item.Type = "message"
+	re.Item.Channel = channel
+	re.Item.Timestamp = "1.1"
+	return re
+}
+
+func TestAlerterLifecycle(t *testing.T) {
+	a, router, out := newTestAlerter(t, AlertsConfig{
+		Channel: "#alerts",
+		Routes:  []AlertRoute{{Match: map[string]string{"severity": "critical"}, Channel: "#oncall"}},
+	})
+
+	key := `alertmanager/{}/{severity="critical"}:{alertname="HighLatency"}`
+
+	steps := []struct {
+		name     string
+		send     string
+		react    string
+		posts    int
+		updates  int
+		contains []string
+	}{
+		{name: "firing", send: "firing.json", posts: 1, contains: []string{"[FIRING:2] HighLatency", "High latency on web-1", "High latency on web-2", "React with :eyes:"}},
+		{name: "repeated", send: "firing.json"},
+		{name: "partially resolved", send: "partially_resolved.json", updates: 1, contains: []string{"[FIRING:1] HighLatency", "~<http://prometheus.example.com/graph?g0.expr=latency_seconds%3E1|High latency on web-2>~ resolved"}},
+		{name: "acknowledged", react: "eyes", updates: 1, contains: []string{"Acknowledged by <@U1>"}},
+		{name: "resolved", send: "resolved.json", updates: 1, contains: []string{"[RESOLVED] HighLatency", "Acknowledged by <@U1>"}},
+		{name: "firing again", send: "firing.json", posts: 1, contains: []string{"[FIRING:2] HighLatency"}},
+		{name: "silenced", react: "mute", updates: 1, contains: []string{"Silenced by <@U1>"}},
+		{name: "resolved while silenced", send: "resolved.json", updates: 1},
+		{name: "firing while silenced", send: "firing.json"},
+	}
+
+	for _, step := range steps {
+		*out = nil
+
+		if step.send != "" {
+			assert.Equal(t, 200, sendAlert(t, router, "/public/alertmanager", step.send), step.name)
+		}
+		if step.react != "" {
+			a.onReaction(reactionOn(step.react, "C1"))
+		}
+
+		posts, updates := 0, 0
+		for _, n := range *out {
+			if n.post {
+				posts++
+				assert.Equal(t, "#oncall", n.channel, step.name)
+				waitPosted(a, key)
+			} else {
+				updates++
+			}
+			for _, s := range step.contains {
+				assert.Contains(t, n.text, s, step.name)
+			}
+		}
+		assert.Equal(t, step.posts, posts, step.name)
+		assert.Equal(t, step.updates, updates, step.name)
+	}
+
+	g, err := a.store.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, g.Firing())
+	assert.Equal(t, "U1", g.SilencedBy)
+}
+
+func TestAlerterWithoutToken(t *testing.T) {
+	hooker, _, _ := newTestHooker(t)
+
+	_, err := newAlerter(hooker.bot, AlertsConfig{Channel: "#alerts"})
+	assert.Equal(t, errAlertsToken, err)
+}
+
+func TestAlerterMonit(t *testing.T) {
+	a, router, out := newTestAlerter(t, AlertsConfig{Channel: "#alerts"})
+
+	send := func(path, alert string) int {
+		rec := httptest.NewRecorder()
+		body := `{"host": "db-1", "service": "postgres", "date": "Fri, 18 Oct 2019 14:03:00 +0200", "alert": "` + alert + `"}`
+		router.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return rec.Code
+	}
+
+	assert.Equal(t, 401, send("/public/monit", "Does not exist"))
+	assert.Empty(t, *out)
+
+	assert.Equal(t, 200, send("/public/monit?token=s3cr3t", "Does not exist"))
+	if assert.Len(t, *out, 1) {
+		assert.True(t, (*out)[0].post)
+		assert.Equal(t, "#alerts", (*out)[0].channel)
+		assert.Contains(t, (*out)[0].text, "[FIRING:1] postgres on db-1")
+	}
+	waitPosted(a, "monit/"+fingerprint(map[string]string{"source": "monit", "host": "db-1", "service": "postgres", "alertname": "postgres"}))
+
+	*out = nil
+	assert.Equal(t, 200, send("/public/monit?token=s3cr3t", "Exists"))
+	if assert.Len(t, *out, 1) {
+		assert.False(t, (*out)[0].post)
+		assert.Contains(t, (*out)[0].text, "[RESOLVED] postgres on db-1")
+	}
+}
+
+func TestAlerterIgnoresOtherReactions(t *testing.T) {
+	a, router, out := newTestAlerter(t, AlertsConfig{Channel: "#alerts", OncallGroup: "oncall"})
+
+	assert.Equal(t, 200, sendAlert(t, router, "/public/alertmanager", "firing.json"))
+	waitPosted(a, `alertmanager/{}/{severity="critical"}:{alertname="HighLatency"}`)
+	*out = nil
+
+	a.onReaction(reactionOn("tada", "C1"))
+	a.onReaction(reactionOn("eyes", "C2"))
+	a.onReaction(reactionOn("eyes", "C1"))
+
+	assert.Empty(t, *out, "not a member of the on-call group")
+}
@@ -0,0 +1,137 @@
+package hooker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// alertStore keeps the alert groups, and the messages they were posted
+// as, so that reactions keep working after a restart
+type alertStore struct {
+	db *bolt.DB
+}
+
+var (
+	alertsBucket   = []byte("alerts")
+	groupsBucket   = []byte("groups")   // [group key] = AlertGroup
+	messagesBucket = []byte("messages") // [channel/ts] = group key
+)
+
+func createAlertBuckets(tx *bolt.Tx) error {
+	b, err := tx.CreateBucketIfNotExists(alertsBucket)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range [][]byte{groupsBucket, messagesBucket} {
+		if _, err := b.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func messageKey(channel, ts string) []byte {
+	return []byte(channel + "/" + ts)
+}
+
+// Get returns a group, or nil when it isn't known
+func (s *alertStore) Get(key string) (g *AlertGroup, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(alertsBucket).Bucket(groupsBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+
+		g = &AlertGroup{}
+		return json.Unmarshal(v, g)
+	})
+
+	return
+}
+
+// Put saves a group, and indexes the message it was posted as
+func (s *alertStore) Put(g *AlertGroup) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(alertsBucket)
+
+		cnt, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		if err := b.Bucket(groupsBucket).Put([]byte(g.Key), cnt); err != nil {
+			return err
+		}
+
+		if g.TS == "" {
+			return nil
+		}
+		return b.Bucket(messagesBucket).Put(messageKey(g.ChannelID, g.TS), []byte(g.Key))
+	})
+}
+
+// ByMessage returns the key of the group posted as a message
+func (s *alertStore) ByMessage(channel, ts string) (key string) {
+	s.db.View(func(tx *bolt.Tx) error {
+		key = string(tx.Bucket(alertsBucket).Bucket(messagesBucket).Get(messageKey(channel, ts)))
+		return nil
+	})
+
+	return
+}
+
+// Forget drops the index of a message, whose reactions are then ignored
+func (s *alertStore) Forget(channel, ts string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(alertsBucket).Bucket(messagesBucket).Delete(messageKey(channel, ts))
+	})
+}
+
+/*
+Prune drops the groups resolved before `before` whose silence is over,
+along with their messages. It returns the number of groups removed.
+*/
+func (s *alertStore) Prune(before time.Time) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(alertsBucket)
+		groups := b.Bucket(groupsBucket)
+
+		var drop []*AlertGroup
+		err := groups.ForEach(func(k, v []byte) error {
+			var g AlertGroup
+			if err := json.Unmarshal(v, &g); err != nil {
+				return err
+			}
+
+			if g.Firing() == 0 && g.UpdatedAt.Before(before) && !g.Silenced(time.Now()) {
+				drop = append(drop, &g)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		messages := b.Bucket(messagesBucket)
+		for _, g := range drop {
+			if err := groups.Delete([]byte(g.Key)); err != nil {
+				return err
+			}
+			if g.TS != "" {
+				if err := messages.Delete(messageKey(g.ChannelID, g.TS)); err != nil {
+					return err
+				}
+			}
+		}
+		removed = len(drop)
+
+		return nil
+	})
+
+	return removed, err
+}
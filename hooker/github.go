@@ -0,0 +1,438 @@
+package hooker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+GitHubConfig is the `hooker.github` section of the configuration, routing
+the events GitHub sends to `/public/github`, signed with `github_secret`:
+
+	hooker:
+	  github_secret: s3cr3t
+	  github:
+	    channel: "#dev"
+	    repos:
+	      gopherworks/bawt: "#bawt"
+	      gopherworks: "#gopherworks"
+	    users:
+	      octocat: U0123ABCD
+	    events: [push, pull_request, release]
+
+Repositories are matched by full name, then by owner, then go to Channel.
+Users maps GitHub logins to Slack users, by ID, name or email. Keys are
+case-insensitive.
+*/
+type GitHubConfig struct {
+	Channel   string            `json:"channel" mapstructure:"channel"`
+	Workspace string            `json:"workspace" mapstructure:"workspace"`
+	Repos     map[string]string `json:"repos" mapstructure:"repos"`
+	Users     map[string]string `json:"users" mapstructure:"users"`
+	Events    []string          `json:"events" mapstructure:"events"` // Events to post, defaults to all the supported ones
+}
+
+// gitHubHook is the name of the GitHub endpoint in the delivery log
+const gitHubHook = "github"
+
+// maxPushCommits is the number of commits listed for a push
+const maxPushCommits = 5
+
+type ghUser struct {
+	Login string `json:"login"`
+}
+
+type ghRepo struct {
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type ghCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
+type ghPullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	Merged  bool   `json:"merged"`
+}
+
+type ghIssue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	HTMLURL     string    `json:"html_url"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+type ghComment struct {
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+type ghRelease struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	HTMLURL    string `json:"html_url"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type ghWorkflowRun struct {
+	Name       string `json:"name"`
+	HeadBranch string `json:"head_branch"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+	RunNumber  int    `json:"run_number"`
+}
+
+// gitHubEvent holds the fields used from the supported events
+type gitHubEvent struct {
+	Action     string `json:"action"`
+	Repository ghRepo `json:"repository"`
+	Sender     ghUser `json:"sender"`
+
+	// push
+	Ref     string     `json:"ref"`
+	Created bool       `json:"created"`
+	Deleted bool       `json:"deleted"`
+	Forced  bool       `json:"forced"`
+	Compare string     `json:"compare"`
+	Commits []ghCommit `json:"commits"`
+
+	PullRequest       *ghPullRequest `json:"pull_request"`
+	RequestedReviewer *ghUser        `json:"requested_reviewer"`
+	Issue             *ghIssue       `json:"issue"`
+	Comment           *ghComment     `json:"comment"`
+	Release           *ghRelease     `json:"release"`
+	WorkflowRun       *ghWorkflowRun `json:"workflow_run"`
+}
+
+// gitHubEvents are the supported values of X-GitHub-Event
+var gitHubEvents = []string{"push", "pull_request", "issues", "issue_comment", "release", "workflow_run"}
+
+var slackIDRegexp = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// onGitHub serves the webhooks of GitHub, and records the outcome in the
+// delivery log
+func (hooker *Hooker) onGitHub(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not accepted", 405)
+		return
+	}
+
+	d := hooker.handleGitHub(r)
+
+	if err := hooker.log.Add(gitHubHook, d); err != nil {
+		hooker.bot.Logging.Logger.WithError(err).Error("Hooker: unable to record delivery")
+	}
+
+	if d.Status >= 400 {
+		http.Error(w, d.Error, d.Status)
+		return
+	}
+	w.WriteHeader(d.Status)
+}
+
+func (hooker *Hooker) handleGitHub(r *http.Request) Delivery {
+	d := Delivery{Time: time.Now(), RemoteAddr: r.RemoteAddr}
+
+	fail := func(result string, status int, err error) Delivery {
+		d.Result = result
+		d.Status = status
+		d.Error = err.Error()
+		return d
+	}
+	skip := func(reason string) Delivery {
+		d.Result = ResultFiltered
+		d.Status = http.StatusAccepted
+		d.Message = reason
+		return d
+	}
+
+	if hooker.config.GitHubSecret == "" {
+		return fail(ResultRejected, http.StatusForbidden, fmt.Errorf("github_secret is not configured"))
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	if err != nil {
+		return fail(ResultRejected, http.StatusBadRequest, fmt.Errorf("unable to read the body: %s", err))
+	}
+
+	if !validHMAC(hooker.config.GitHubSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		return fail(ResultRejected, http.StatusUnauthorized, fmt.Errorf("invalid X-Hub-Signature-256"))
+	}
+
+	kind := r.Header.Get("X-GitHub-Event")
+	if kind == "ping" {
+		return skip("ping")
+	}
+	if !hooker.gitHubEnabled(kind) {
+		return skip(fmt.Sprintf("%s events are not posted", kind))
+	}
+
+	var ev gitHubEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return fail(ResultRejected, http.StatusBadRequest, fmt.Errorf("invalid JSON: %s", err))
+	}
+
+	text := hooker.formatGitHub(kind, &ev)
+	if text == "" {
+		return skip(fmt.Sprintf("%s %s is not posted", kind, ev.Action))
+	}
+	d.Message = text
+
+	channel := hooker.gitHubChannel(ev.Repository.FullName)
+	if channel == "" {
+		return skip(fmt.Sprintf("no channel for %s", ev.Repository.FullName))
+	}
+
+	if err := hooker.post(hooker.config.GitHub.Workspace, channel, text, nil); err != nil {
+		return fail(ResultError, http.StatusBadGateway, err)
+	}
+
+	d.Result = ResultPosted
+	d.Status = http.StatusOK
+	return d
+}
+
+// gitHubEnabled tells whether an event type is supported and configured
+func (hooker *Hooker) gitHubEnabled(kind string) bool {
+	events := hooker.config.GitHub.Events
+	if len(events) == 0 {
+		events = gitHubEvents
+	}
+
+	for _, e := range events {
+		if e == kind {
+			for _, supported := range gitHubEvents {
+				if supported == kind {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// gitHubChannel routes a repository to a channel
+func (hooker *Hooker) gitHubChannel(repo string) string {
+	repo = strings.ToLower(repo)
+	repos := hooker.config.GitHub.Repos
+
+	if channel, ok := repos[repo]; ok {
+		return channel
+	}
+	if channel, ok := repos[strings.SplitN(repo, "/", 2)[0]]; ok {
+		return channel
+	}
+
+	return hooker.config.GitHub.Channel
+}
+
+// mention returns how to refer to a GitHub user in Slack
+func (hooker *Hooker) mention(u ghUser) string {
+	slackUser, ok := hooker.config.GitHub.Users[strings.ToLower(u.Login)]
+	if !ok {
+		return "*" + escape(u.Login) + "*"
+	}
+
+	if user, found := hooker.bot.Users.Find(slackUser); found {
+		return "<@" + user.ID + ">"
+	}
+	if slackIDRegexp.MatchString(slackUser) {
+		return "<@" + slackUser + ">"
+	}
+
+	return "*" + escape(u.Login) + "*"
+}
+
+/*
+formatGitHub returns a one line summary of an event, followed by the
+commits of pushes. Actions not worth a message, like labels being added,
+return an empty string.
+*/
+func (hooker *Hooker) formatGitHub(kind string, ev *gitHubEvent) string {
+	repo := link(ev.Repository.HTMLURL, escape(ev.Repository.FullName))
+	who := hooker.mention(ev.Sender)
+
+	switch kind {
+	case "push":
+		return hooker.formatPush(ev, who)
+
+	case "pull_request":
+		pr := ev.PullRequest
+		if pr == nil {
+			return ""
+		}
+		title := link(pr.HTMLURL, escape(fmt.Sprintf("#%d %s", pr.Number, pr.Title)))
+
+		switch ev.Action {
+		case "opened", "reopened":
+			return fmt.Sprintf("[%s] %s %s pull request %s", repo, who, ev.Action, title)
+		case "ready_for_review":
+			return fmt.Sprintf("[%s] %s marked pull request %s as ready for review", repo, who, title)
+		case "closed":
+			if pr.Merged {
+				return fmt.Sprintf("[%s] %s merged pull request %s", repo, who, title)
+			}
+			return fmt.Sprintf("[%s] %s closed pull request %s without merging", repo, who, title)
+		case "review_requested":
+			if ev.RequestedReviewer == nil {
+				return ""
+			}
+			return fmt.Sprintf("[%s] %s requested a review from %s on %s", repo, who, hooker.mention(*ev.RequestedReviewer), title)
+		}
+
+	case "issues":
+		issue := ev.Issue
+		if issue == nil {
+			return ""
+		}
+
+		switch ev.Action {
+		case "opened", "closed", "reopened":
+			return fmt.Sprintf("[%s] %s %s issue %s", repo, who, ev.Action, link(issue.HTMLURL, escape(fmt.Sprintf("#%d %s", issue.Number, issue.Title))))
+		}
+
+	case "issue_comment":
+		if ev.Action != "created" || ev.Issue == nil || ev.Comment == nil {
+			return ""
+		}
+
+		what := "issue"
+		if ev.Issue.PullRequest != nil {
+			what = "pull request"
+		}
+		return fmt.Sprintf("[%s] %s commented on %s %s: %s", repo, who, what,
+			link(ev.Comment.HTMLURL, escape(fmt.Sprintf("#%d %s", ev.Issue.Number, ev.Issue.Title))), escape(excerpt(ev.Comment.Body, 140)))
+
+	case "release":
+		rel := ev.Release
+		if ev.Action != "published" || rel == nil {
+			return ""
+		}
+
+		name := rel.TagName
+		if rel.Name != "" && rel.Name != rel.TagName {
+			name = fmt.Sprintf("%s (%s)", rel.TagName, rel.Name)
+		}
+		what := "release"
+		if rel.Prerelease {
+			what = "pre-release"
+		}
+		return fmt.Sprintf("[%s] %s published %s %s", repo, who, what, link(rel.HTMLURL, escape(name)))
+
+	case "workflow_run":
+		run := ev.WorkflowRun
+		if ev.Action != "completed" || run == nil {
+			return ""
+		}
+
+		return fmt.Sprintf("[%s] Workflow %s on `%s` %s", repo,
+			link(run.HTMLURL, escape(fmt.Sprintf("%s #%d", run.Name, run.RunNumber))), escape(run.HeadBranch), conclusion(run.Conclusion))
+	}
+
+	return ""
+}
+
+func (hooker *Hooker) formatPush(ev *gitHubEvent, who string) string {
+	repo := link(ev.Repository.HTMLURL, escape(ev.Repository.FullName))
+
+	if strings.HasPrefix(ev.Ref, "refs/tags/") {
+		tag := escape(strings.TrimPrefix(ev.Ref, "refs/tags/"))
+		if ev.Deleted {
+			return fmt.Sprintf("[%s] %s deleted tag `%s`", repo, who, tag)
+		}
+		return fmt.Sprintf("[%s] %s pushed tag `%s`", repo, who, tag)
+	}
+
+	branch := escape(strings.TrimPrefix(ev.Ref, "refs/heads/"))
+	switch {
+	case ev.Deleted:
+		return fmt.Sprintf("[%s] %s deleted branch `%s`", repo, who, branch)
+	case len(ev.Commits) == 0 && ev.Created:
+		return fmt.Sprintf("[%s] %s created branch `%s`", repo, who, branch)
+	case len(ev.Commits) == 0:
+		return ""
+	}
+
+	commits := "commit"
+	if len(ev.Commits) > 1 {
+		commits = "commits"
+	}
+	forced := ""
+	if ev.Forced {
+		forced = " (forced)"
+	}
+
+	lines := []string{fmt.Sprintf("[%s:%s] %s pushed %s%s", repo, branch, who,
+		link(ev.Compare, fmt.Sprintf("%d %s", len(ev.Commits), commits)), forced)}
+
+	for i, c := range ev.Commits {
+		if i == maxPushCommits {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(ev.Commits)-maxPushCommits))
+			break
+		}
+
+		id := c.ID
+		if len(id) > 7 {
+			id = id[:7]
+		}
+		lines = append(lines, fmt.Sprintf("• %s %s", link(c.URL, "`"+escape(id)+"`"), escape(excerpt(c.Message, 80))))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// link formats a Slack link, or just the text without a URL. A `|` in the
+// text would end the URL, it's replaced.
+func link(url, text string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("<%s|%s>", url, strings.Replace(text, "|", "¦", -1))
+}
+
+// slackEscaper escapes the characters with a meaning in Slack messages
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escape makes a text written on GitHub safe to post: `<!channel>` or a
+// link can't be slipped into a title, a comment or a commit message
+func escape(text string) string {
+	return slackEscaper.Replace(text)
+}
+
+// excerpt returns the first line of a text, cut to `n` characters
+func excerpt(text string, n int) string {
+	text = strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+
+	r := []rune(text)
+	if len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return text
+}
+
+func conclusion(c string) string {
+	switch c {
+	case "success":
+		return "succeeded :white_check_mark:"
+	case "failure":
+		return "failed :x:"
+	case "cancelled":
+		return "was cancelled"
+	case "timed_out":
+		return "timed out :x:"
+	case "":
+		return "completed"
+	}
+	return "completed: " + c
+}
@@ -0,0 +1,141 @@
+package hooker
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnGitHub(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		file    string
+		secret  string
+		status  int
+		channel string
+		want    string
+	}{
+		{
+			name: "push", event: "push", file: "push.json", status: 200, channel: "#bawt",
+			want: "[<https://github.com/gopherworks/bawt|gopherworks/bawt>:master] <@U0JANE> pushed <https://github.com/gopherworks/bawt/compare/6e3d18f2b6a1...17ef144c7d9a|2 commits>\n" +
+				"• <https://github.com/gopherworks/bawt/commit/6cb657c1f0e2d3c4b5a697887766554433221100|`6cb657c`> Add config-defined templated incoming webhooks to hooker\n" +
+				"• <https://github.com/gopherworks/bawt/commit/17ef144c7d9a0e1b2c3d4e5f60718293a4b5c6d7|`17ef144`> Fix the delivery log cap",
+		},
+		{
+			name: "pull request merged", event: "pull_request", file: "pull_request.json", status: 200, channel: "#bawt",
+			want: "[<https://github.com/gopherworks/bawt|gopherworks/bawt>] <@U0JANE> merged pull request <https://github.com/gopherworks/bawt/pull/42|#42 Add outgoing webhooks>",
+		},
+		{
+			name: "issue opened, routed by owner", event: "issues", file: "issues.json", status: 200, channel: "#gopherworks",
+			want: "[<https://github.com/gopherworks/plugins|gopherworks/plugins>] <@U0OCTO> opened issue <https://github.com/gopherworks/plugins/issues/7|#7 Standup reminders are sent on holidays>",
+		},
+		{
+			name: "comment on a pull request", event: "issue_comment", file: "issue_comment.json", status: 200, channel: "#bawt",
+			want: "[<https://github.com/gopherworks/bawt|gopherworks/bawt>] <@U0JANE> commented on pull request <https://github.com/gopherworks/bawt/pull/42#issuecomment-543219876|#42 Add outgoing webhooks>: Looks good, but the dead-letter queue should be capped.",
+		},
+		{
+			name: "release", event: "release", file: "release.json", status: 200, channel: "#bawt",
+			want: "[<https://github.com/gopherworks/bawt|gopherworks/bawt>] <@U0JANE> published release <https://github.com/gopherworks/bawt/releases/tag/v0.5.0|v0.5.0 (Webhooks)>",
+		},
+		{
+			name: "workflow run", event: "workflow_run", file: "workflow_run.json", status: 200, channel: "#bawt",
+			want: "[<https://github.com/gopherworks/bawt|gopherworks/bawt>] Workflow <https://github.com/gopherworks/bawt/actions/runs/987654321|CI #118> on `master` failed :x:",
+		},
+		{name: "ignored action", event: "pull_request", file: "pull_request_labeled.json", status: 202},
+		{name: "disabled event", event: "fork", file: "push.json", status: 202},
+		{name: "ping", event: "ping", file: "push.json", status: 202},
+		{name: "bad signature", event: "push", file: "push.json", secret: "other", status: 401},
+	}
+
+	hooker, router, out := newTestHooker(t)
+
+	hooker.bot.Users.Set(slack.User{ID: "U0JANE", Name: "jane"})
+	hooker.config = HookerConfig{
+		GitHubSecret: "s3cr3t",
+		GitHub: GitHubConfig{
+			Channel: "#dev",
+			Repos:   map[string]string{"gopherworks/bawt": "#bawt", "gopherworks": "#gopherworks"},
+			Users:   map[string]string{"janedoe": "jane", "octocat": "U0OCTO"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*out = nil
+
+			body, err := ioutil.ReadFile(filepath.Join("testdata", "github", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			secret := test.secret
+			if secret == "" {
+				secret = "s3cr3t"
+			}
+
+			req := httptest.NewRequest("POST", "/public/github", bytes.NewReader(body))
+			req.Header.Set("X-GitHub-Event", test.event)
+			req.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex(secret, string(body)))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+
+			if test.want == "" {
+				assert.Empty(t, *out)
+				return
+			}
+			if assert.Len(t, *out, 1) {
+				assert.Equal(t, test.channel, (*out)[0].channel)
+				assert.Equal(t, test.want, (*out)[0].text)
+			}
+		})
+	}
+
+	log, err := hooker.log.List(gitHubHook)
+	assert.NoError(t, err)
+	assert.Len(t, log, len(tests))
+}
+
+func TestOnGitHubWithoutSecret(t *testing.T) {
+	_, router, _ := newTestHooker(t)
+
+	req := httptest.NewRequest("POST", "/public/github", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("X-GitHub-Event", "push")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, 403, rec.Code)
+}
+
+func TestFormatGitHubEscapes(t *testing.T) {
+	hooker, _, _ := newTestHooker(t)
+
+	ev := &gitHubEvent{
+		Action:     "created",
+		Repository: ghRepo{FullName: "gopherworks/bawt", HTMLURL: "https://github.com/gopherworks/bawt"},
+		Sender:     ghUser{Login: "mallory"},
+		Issue:      &ghIssue{Number: 7, Title: "a > b | c", HTMLURL: "https://github.com/gopherworks/bawt/issues/7"},
+		Comment:    &ghComment{Body: "<!channel> look & see <https://evil.example|here>", HTMLURL: "https://github.com/gopherworks/bawt/issues/7#c1"},
+	}
+	assert.Equal(t, "[<https://github.com/gopherworks/bawt|gopherworks/bawt>] *mallory* commented on issue "+
+		"<https://github.com/gopherworks/bawt/issues/7#c1|#7 a &gt; b ¦ c>: &lt;!channel&gt; look &amp; see &lt;https://evil.example|here&gt;",
+		hooker.formatGitHub("issue_comment", ev))
+
+	ev = &gitHubEvent{
+		Repository: ghRepo{FullName: "gopherworks/bawt"},
+		Sender:     ghUser{Login: "mallory"},
+		Ref:        "refs/heads/<!everyone>",
+		Compare:    "https://github.com/gopherworks/bawt/compare/a...b",
+		Commits:    []ghCommit{{ID: "6cb657c1f0e2", Message: "<!here> fix", URL: "https://github.com/gopherworks/bawt/commit/6cb657c1f0e2"}},
+	}
+	assert.Equal(t, "[gopherworks/bawt:&lt;!everyone&gt;] *mallory* pushed <https://github.com/gopherworks/bawt/compare/a...b|1 commit>\n"+
+		"• <https://github.com/gopherworks/bawt/commit/6cb657c1f0e2|`6cb657c`> &lt;!here&gt; fix",
+		hooker.formatGitHub("push", ev))
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/nlopes/slack"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/gopherworks/bawt"
@@ -19,13 +21,25 @@ func init() {
 type Hooker struct {
 	bot    *bawt.Bot
 	config HookerConfig
+	hooks  map[string]*Hook // by path
+	log    DeliveryLog
+
+	// post sends a message to a channel of a workspace, `blocks` being
+	// nil for plain text ones
+	post func(workspace, channel, text string, blocks []slack.Block) error
 }
 
 type HookerConfig struct {
-	StripeSecret string `json:"stripe_secret" mapstructure:"stripe_secret"`
-	GitHubSecret string `json:"github_secret" mapstructure:"github_secret"`
+	StripeSecret string       `json:"stripe_secret" mapstructure:"stripe_secret"`
+	GitHubSecret string       `json:"github_secret" mapstructure:"github_secret"`
+	GitHub       GitHubConfig `json:"github" mapstructure:"github"`
+	Alerts       AlertsConfig `json:"alerts" mapstructure:"alerts"`
+	Hooks        []Hook       `json:"hooks" mapstructure:"hooks"`
 }
 
+// maxBodySize caps the payloads of the hooks
+const maxBodySize = 1 << 20
+
 type MonitAlert struct {
 	Host    string `json:"host"`
 	Date    string `json:"date"`
@@ -42,42 +56,169 @@ func (hooker *Hooker) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, pubRo
 	bot.LoadConfig(&conf)
 	hooker.config = conf.Hooker
 
-	pubRouter.HandleFunc("/public/updated_bawt_repo", hooker.updatedbawtRepo)
+	err := bot.DB.Update(createBuckets)
+	if err != nil {
+		bot.Logging.Logger.Fatalln("Couldn't create the `hooker` bucket")
+	}
+
+	hooker.log = &boltLog{db: bot.DB}
+	hooker.post = hooker.postToSlack
+	hooker.hooks = make(map[string]*Hook)
+
+	for i := range hooker.config.Hooks {
+		hook := &hooker.config.Hooks[i]
+		if err := hook.compile(); err != nil {
+			bot.Logging.Logger.WithError(err).Error("Skipping incoming webhook")
+			continue
+		}
+		if _, ok := hooker.hooks[hook.Path]; ok {
+			bot.Logging.Logger.Errorf("Skipping incoming webhook %q: path %q already used", hook.Name, hook.Path)
+			continue
+		}
+		hooker.hooks[hook.Path] = hook
+	}
+
+	pubRouter.HandleFunc("/public/hooks/{path}", hooker.onHook)
+
+	if hooker.config.GitHubSecret == "" {
+		bot.Logging.Logger.Warn("Hooker: `github_secret` isn't set, GitHub webhooks will be refused")
+	}
+	pubRouter.HandleFunc("/public/github", hooker.onGitHub)
+	// Historical path of the GitHub webhook
+	pubRouter.HandleFunc("/public/updated_bawt_repo", hooker.onGitHub)
 
 	stripeUrl := fmt.Sprintf("/public/stripehook/%s", hooker.config.StripeSecret)
 	pubRouter.HandleFunc(stripeUrl, hooker.onPayingUser)
 
-	pubRouter.HandleFunc("/public/monit", hooker.onMonit)
-
-	privRouter.HandleFunc("/plugins/hooker.json", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "GET" {
-			http.Error(w, "Method not accepted", 405)
-			return
-		}
+	alerts, err := newAlerter(bot, hooker.config.Alerts)
+	if err == errAlertsToken {
+		bot.Logging.Logger.Warn("Hooker: `alerts.token` isn't set, the Alertmanager and Monit receivers are disabled")
+	} else if err != nil {
+		bot.Logging.Logger.WithError(err).Error("Hooker: alerts are disabled")
+	} else {
+		pubRouter.HandleFunc("/public/alertmanager", alerts.onAlertmanager)
+		pubRouter.HandleFunc("/public/monit", alerts.onMonit)
+		bot.Events.OnReaction(alerts.onReaction)
+		go alerts.pruneLoop()
+	}
 
-	})
+	privRouter.HandleFunc("/plugins/hooker", hooker.handleWebLog)
+	privRouter.HandleFunc("/plugins/hooker.json", hooker.handleWebLogJSON)
 }
 
-func (hooker *Hooker) updatedbawtRepo(w http.ResponseWriter, r *http.Request) {
+// onHook serves the hooks of the configuration, and records the outcome
+// in their delivery log
+func (hooker *Hooker) onHook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := hooker.hooks[mux.Vars(r)["path"]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
 	if r.Method != "POST" {
 		http.Error(w, "Method not accepted", 405)
 		return
 	}
 
-	bodyBytes, err := ioutil.ReadAll(r.Body)
+	d := hooker.handleHook(hook, r)
 
-	body := ""
+	if err := hooker.log.Add(hook.Name, d); err != nil {
+		log.Println("Hooker: unable to record delivery: ", err)
+	}
+
+	if d.Status >= 400 {
+		http.Error(w, d.Error, d.Status)
+		return
+	}
+	w.WriteHeader(d.Status)
+}
+
+// handleHook verifies, filters, renders and posts a payload
+func (hooker *Hooker) handleHook(hook *Hook, r *http.Request) Delivery {
+	d := Delivery{Time: time.Now(), RemoteAddr: r.RemoteAddr}
+
+	fail := func(result string, status int, err error) Delivery {
+		d.Result = result
+		d.Status = status
+		d.Error = err.Error()
+		return d
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
 	if err != nil {
-		body = "[Error reading body]"
-	} else {
-		body = string(bodyBytes)
+		return fail(ResultRejected, http.StatusBadRequest, fmt.Errorf("unable to read the body: %s", err))
+	}
+
+	if !hook.verify(r, body) {
+		return fail(ResultRejected, http.StatusUnauthorized, fmt.Errorf("invalid signature or token"))
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fail(ResultRejected, http.StatusBadRequest, fmt.Errorf("invalid JSON: %s", err))
+	}
+
+	ok, err := hook.accept(data)
+	if err != nil {
+		return fail(ResultError, http.StatusInternalServerError, fmt.Errorf("filter failed: %s", err))
+	}
+	if !ok {
+		d.Result = ResultFiltered
+		d.Status = http.StatusAccepted
+		return d
 	}
 
-	// TODO: unmarshal the JSON, and check "hooker.config.GitHubSecret"
+	text, err := hook.render(data)
+	if err != nil {
+		return fail(ResultError, http.StatusInternalServerError, fmt.Errorf("template failed: %s", err))
+	}
+	d.Message = text
+
+	var blocks []slack.Block
+	if hook.Blocks {
+		var parsed slack.Blocks
+		if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+			return fail(ResultError, http.StatusInternalServerError, fmt.Errorf("template didn't render blocks: %s", err))
+		}
+		blocks = parsed.BlockSet
+
+		if text, err = hook.renderFallback(data); err != nil {
+			return fail(ResultError, http.StatusInternalServerError, fmt.Errorf("fallback template failed: %s", err))
+		}
+	}
 
-	text := fmt.Sprintf("/code Got a webhook from Github:\n%s", body)
-	fmt.Println("TEST: ", text)
-	//bot.SendToRoom("123823_devops", )
+	if err := hooker.post(hook.Workspace, hook.Channel, text, blocks); err != nil {
+		return fail(ResultError, http.StatusBadGateway, err)
+	}
+
+	d.Result = ResultPosted
+	d.Status = http.StatusOK
+	return d
+}
+
+// postToSlack sends a message to a channel, by name
+func (hooker *Hooker) postToSlack(workspace, channelName, text string, blocks []slack.Block) error {
+	bot := hooker.bot.Workspace(workspace)
+	if bot == nil {
+		return fmt.Errorf("unknown workspace %q", workspace)
+	}
+
+	channel := bot.GetChannelByName(channelName)
+	if channel == nil {
+		return fmt.Errorf("unknown channel %q", channelName)
+	}
+
+	if blocks == nil {
+		bot.SendOutgoingMessage(text, channel.ID)
+		return nil
+	}
+
+	_, _, err := bot.Slack.PostMessage(channel.ID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionAsUser(true),
+	)
+	return err
 }
 
 func (hooker *Hooker) onPayingUser(w http.ResponseWriter, r *http.Request) {
@@ -106,32 +247,3 @@ func (hooker *Hooker) onPayingUser(w http.ResponseWriter, r *http.Request) {
 				stripeEvent.Request))
 	}
 }
-
-func (hooker *Hooker) onMonit(w http.ResponseWriter, r *http.Request) {
-
-	var alert MonitAlert
-
-	if r.Method != "POST" {
-		http.Error(w, "Method not accepted", 405)
-		return
-	}
-
-	defer r.Body.Close()
-
-	bodyBytes, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		log.Println("Hooker: unable to read incoming Zapier request: ", err)
-		return
-	}
-
-	err = json.Unmarshal(bodyBytes, &alert)
-
-	if err != nil {
-		log.Println("Hooker: unable to decode incoming Alert JSON: ", err)
-		return
-	}
-
-	fmt.Println("TEST: ", alert)
-
-	//bot.SendToRoom("123823_devops", )
-}
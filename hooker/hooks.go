@@ -0,0 +1,215 @@
+package hooker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+/*
+Hook is an incoming webhook defined in the `hooker` section of the
+configuration, like:
+
+	hooker:
+	  hooks:
+	    - name: deploys
+	      path: deploys
+	      secret: s3cr3t
+	      verify: hmac
+	      channel: "#ops"
+	      filter: '{{ eq .status "success" }}'
+	      template: "{{ .service }} {{ .version }} was deployed by {{ .user }}"
+
+It is served on `/public/hooks/<path>` and expects a JSON body, which is
+rendered with the Go `text/template` into a message posted to the
+channel. With `blocks`, the template renders a JSON array of Block Kit
+blocks instead, and `fallback` the notification text.
+*/
+type Hook struct {
+	Name      string `json:"name" mapstructure:"name"`
+	Path      string `json:"path" mapstructure:"path"`
+	Secret    string `json:"-" mapstructure:"secret"`
+	Verify    string `json:"verify" mapstructure:"verify"`       // `token` (the default) compares the secret with the `token` query parameter or the X-Hook-Token header, `hmac` checks the hex HMAC-SHA256 of the body sent in Header
+	Header    string `json:"header" mapstructure:"header"`       // Header holding the HMAC signature, defaults to X-Signature. A `sha256=` prefix is accepted.
+	Channel   string `json:"channel" mapstructure:"channel"`     // Name of the channel to post to
+	Workspace string `json:"workspace" mapstructure:"workspace"` // Team ID or domain of the channel's workspace, for multi-workspace bots
+	Template  string `json:"template" mapstructure:"template"`   // Renders the JSON body into the message
+	Blocks    bool   `json:"blocks" mapstructure:"blocks"`       // The template renders Block Kit blocks
+	Fallback  string `json:"fallback" mapstructure:"fallback"`   // Template of the notification text of Block Kit messages
+	Filter    string `json:"filter" mapstructure:"filter"`       // Template that must render `true` for the message to be posted
+
+	tmpl     *template.Template
+	fallback *template.Template
+	filter   *template.Template
+}
+
+// Verification modes
+const (
+	verifyToken = "token"
+	verifyHMAC  = "hmac"
+
+	defaultSignatureHeader = "X-Signature"
+	tokenHeader            = "X-Hook-Token"
+)
+
+// templateFuncs are available to the templates, on top of the builtins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join": func(sep string, list []interface{}) string {
+		out := make([]string, 0, len(list))
+		for _, v := range list {
+			out = append(out, fmt.Sprint(v))
+		}
+		return strings.Join(out, sep)
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		return string(r[:n]) + "…"
+	},
+}
+
+// compile checks the hook and parses its templates
+func (h *Hook) compile() error {
+	if h.Name == "" {
+		return fmt.Errorf("hook without a name")
+	}
+	if h.Path == "" {
+		h.Path = h.Name
+	}
+	h.Path = strings.Trim(h.Path, "/")
+
+	if h.Channel == "" {
+		return fmt.Errorf("hook %q has no channel", h.Name)
+	}
+	if h.Template == "" {
+		return fmt.Errorf("hook %q has no template", h.Name)
+	}
+	if h.Secret == "" {
+		return fmt.Errorf("hook %q has no secret", h.Name)
+	}
+
+	switch h.Verify {
+	case "":
+		h.Verify = verifyToken
+	case verifyToken, verifyHMAC:
+	default:
+		return fmt.Errorf("hook %q: unknown verify mode %q, use `token` or `hmac`", h.Name, h.Verify)
+	}
+	if h.Header == "" {
+		h.Header = defaultSignatureHeader
+	}
+
+	var err error
+	if h.tmpl, err = parse(h.Name, h.Template); err != nil {
+		return err
+	}
+	if h.Fallback != "" {
+		if h.fallback, err = parse(h.Name+":fallback", h.Fallback); err != nil {
+			return err
+		}
+	}
+	if h.Filter != "" {
+		if h.filter, err = parse(h.Name+":filter", h.Filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("hook %q: invalid template: %s", name, err)
+	}
+	return t, nil
+}
+
+// verify authenticates a request. Hooks without a secret, which compile
+// refuses, accept nothing.
+func (h *Hook) verify(r *http.Request, body []byte) bool {
+	if h.Secret == "" {
+		return false
+	}
+
+	if h.Verify == verifyHMAC {
+		return validHMAC(h.Secret, body, r.Header.Get(h.Header))
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get(tokenHeader)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.Secret)) == 1
+}
+
+// validHMAC checks a hex HMAC-SHA256 signature of a body, with or
+// without a `sha256=` prefix
+func validHMAC(secret string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	sig := strings.ToLower(strings.TrimPrefix(signature, "sha256="))
+	return hmac.Equal([]byte(sig), []byte(expected))
+}
+
+// accept runs the filter on the payload
+func (h *Hook) accept(data interface{}) (bool, error) {
+	if h.filter == nil {
+		return true, nil
+	}
+
+	out, err := execute(h.filter, data)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(out) == "true", nil
+}
+
+// render turns the payload into the text of the message
+func (h *Hook) render(data interface{}) (string, error) {
+	out, err := execute(h.tmpl, data)
+	return strings.TrimSpace(out), err
+}
+
+// renderFallback returns the notification text of a Block Kit message
+func (h *Hook) renderFallback(data interface{}) (string, error) {
+	if h.fallback == nil {
+		return h.Name, nil
+	}
+
+	out, err := execute(h.fallback, data)
+	return strings.TrimSpace(out), err
+}
+
+func execute(t *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,169 @@
+package hooker
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/gopherworks/bawt"
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/gorilla/mux"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+type posted struct {
+	channel string
+	text    string
+	blocks  []slack.Block
+}
+
+func newTestHooker(t *testing.T, hooks ...Hook) (*Hooker, *mux.Router, *[]posted) {
+	db := bawttest.NewDB(t, createBuckets, func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bawt.Groups))
+		return err
+	})
+	bot := bawttest.NewBot(db)
+
+	var out []posted
+	hooker := &Hooker{
+		bot:   bot,
+		hooks: make(map[string]*Hook),
+		log:   &boltLog{db: db},
+		post: func(workspace, channel, text string, blocks []slack.Block) error {
+			out = append(out, posted{channel, text, blocks})
+			return nil
+		},
+	}
+	for i := range hooks {
+		if err := hooks[i].compile(); err != nil {
+			t.Fatal(err)
+		}
+		hooker.hooks[hooks[i].Path] = &hooks[i]
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/public/hooks/{path}", hooker.onHook)
+	router.HandleFunc("/public/github", hooker.onGitHub)
+
+	return hooker, router, &out
+}
+
+func hmacHex(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestOnHook(t *testing.T) {
+	hooks := []Hook{
+		{
+			Name:     "deploys",
+			Secret:   "s3cr3t",
+			Channel:  "#ops",
+			Filter:   `{{ eq .status "success" }}`,
+			Template: `{{ .service }} {{ .version }} deployed by {{ .user | default "someone" }}`,
+		},
+		{
+			Name:     "signed",
+			Path:     "/ci/",
+			Secret:   "s3cr3t",
+			Verify:   "hmac",
+			Header:   "X-Hub-Signature-256",
+			Channel:  "#ci",
+			Template: `Tags: {{ join ", " .tags }}`,
+		},
+		{
+			Name:     "blocks",
+			Secret:   "s3cr3t",
+			Channel:  "#ops",
+			Blocks:   true,
+			Template: `[{"type": "section", "text": {"type": "mrkdwn", "text": {{ json .text }}}}]`,
+			Fallback: `{{ .text }}`,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		hook    string
+		path    string
+		headers map[string]string
+		body    string
+		status  int
+		result  string
+		want    string
+		blocks  int
+	}{
+		{name: "posted", hook: "deploys", path: "deploys?token=s3cr3t", body: `{"status": "success", "service": "api", "version": "1.2"}`, status: 200, result: ResultPosted, want: "api 1.2 deployed by someone"},
+		{name: "token header", hook: "deploys", path: "deploys", headers: map[string]string{"X-Hook-Token": "s3cr3t"}, body: `{"status": "success", "service": "api", "version": "1.3", "user": "jane"}`, status: 200, result: ResultPosted, want: "api 1.3 deployed by jane"},
+		{name: "bad token", hook: "deploys", path: "deploys?token=nope", body: `{"status": "success"}`, status: 401, result: ResultRejected},
+		{name: "filtered", hook: "deploys", path: "deploys?token=s3cr3t", body: `{"status": "failure"}`, status: 202, result: ResultFiltered},
+		{name: "invalid JSON", hook: "deploys", path: "deploys?token=s3cr3t", body: `{`, status: 400, result: ResultRejected},
+		{name: "hmac", hook: "signed", path: "ci", headers: map[string]string{"X-Hub-Signature-256": "sha256=" + hmacHex("s3cr3t", `{"tags": ["a", "b"]}`)}, body: `{"tags": ["a", "b"]}`, status: 200, result: ResultPosted, want: "Tags: a, b"},
+		{name: "bad hmac", hook: "signed", path: "ci", headers: map[string]string{"X-Hub-Signature-256": "sha256=" + hmacHex("other", `{}`)}, body: `{}`, status: 401, result: ResultRejected},
+		{name: "blocks", hook: "blocks", path: "blocks?token=s3cr3t", body: `{"text": "*hello*"}`, status: 200, result: ResultPosted, want: "*hello*", blocks: 1},
+		{name: "unknown hook", path: "nope", body: `{}`, status: 404},
+	}
+
+	hooker, router, out := newTestHooker(t, hooks...)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*out = nil
+
+			req := httptest.NewRequest("POST", "/public/hooks/"+test.path, strings.NewReader(test.body))
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+
+			if test.want == "" {
+				assert.Empty(t, *out)
+			} else if assert.Len(t, *out, 1) {
+				assert.Equal(t, test.want, (*out)[0].text)
+				assert.Len(t, (*out)[0].blocks, test.blocks)
+			}
+
+			if test.hook == "" {
+				return
+			}
+
+			log, err := hooker.log.List(test.hook)
+			assert.NoError(t, err)
+			if assert.NotEmpty(t, log) {
+				assert.Equal(t, test.result, log[0].Result)
+				assert.Equal(t, test.status, log[0].Status)
+			}
+		})
+	}
+}
+
+func TestCompileWithoutSecret(t *testing.T) {
+	hook := Hook{Name: "open", Channel: "#c", Template: "x"}
+	assert.EqualError(t, hook.compile(), `hook "open" has no secret`)
+}
+
+func TestDeliveryLogCap(t *testing.T) {
+	hooker, router, _ := newTestHooker(t, Hook{Name: "h", Secret: "s3cr3t", Channel: "#c", Template: "x"})
+
+	for i := 0; i < maxLogEntries+5; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/public/hooks/h?token=s3cr3t", strings.NewReader(`{}`)))
+	}
+
+	log, err := hooker.log.List("h")
+	assert.NoError(t, err)
+	assert.Len(t, log, maxLogEntries)
+	assert.False(t, log[0].Time.Before(log[len(log)-1].Time), "newest first")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/public/hooks/h", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,115 @@
+package hooker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Results of a Delivery
+const (
+	ResultPosted   = "posted"
+	ResultFiltered = "filtered"
+	ResultRejected = "rejected"
+	ResultError    = "error"
+)
+
+// maxLogEntries is the number of deliveries kept per hook
+const maxLogEntries = 100
+
+// Delivery is an entry of the delivery log of a hook
+type Delivery struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	Result     string    `json:"result"`
+	Status     int       `json:"status"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// DeliveryLog keeps the latest deliveries of every hook
+type DeliveryLog interface {
+	Add(hook string, d Delivery) error
+	List(hook string) ([]Delivery, error)
+}
+
+type boltLog struct {
+	db *bolt.DB
+}
+
+var bucketName = []byte("hooker") // [hook][time] = Delivery
+
+func createBuckets(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(bucketName)
+	return err
+}
+
+// Add records a delivery and drops the oldest ones past maxLogEntries
+func (l *boltLog) Add(hook string, d Delivery) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(bucketName).CreateBucketIfNotExists([]byte(hook))
+		if err != nil {
+			return err
+		}
+
+		cnt, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+
+		// Deliveries within the same nanosecond go right after
+		key := make([]byte, 8)
+		for n := uint64(d.Time.UnixNano()); ; n++ {
+			binary.BigEndian.PutUint64(key, n)
+			if b.Get(key) == nil {
+				break
+			}
+		}
+		if err := b.Put(key, cnt); err != nil {
+			return err
+		}
+
+		// Keys sort chronologically: skip the newest, drop the rest
+		var drop [][]byte
+		c := b.Cursor()
+		n := 0
+		for k, _ := c.Last(); k != nil; k, _ = c.Prev() {
+			n++
+			if n > maxLogEntries {
+				drop = append(drop, append([]byte{}, k...))
+			}
+		}
+		for _, k := range drop {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// List returns the deliveries of a hook, newest first
+func (l *boltLog) List(hook string) (out []Delivery, err error) {
+	err = l.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName).Bucket([]byte(hook))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var d Delivery
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			out = append(out, d)
+		}
+
+		return nil
+	})
+
+	return
+}
@@ -0,0 +1,105 @@
+package hooker
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+)
+
+type webHook struct {
+	*Hook
+	URL        string     `json:"url"`
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+// webHooks returns the hooks along with their delivery log, by name
+func (hooker *Hooker) webHooks() ([]webHook, error) {
+	out := make([]webHook, 0, len(hooker.hooks))
+
+	for _, hook := range hooker.hooks {
+		deliveries, err := hooker.log.List(hook.Name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, webHook{Hook: hook, URL: "/public/hooks/" + hook.Path, Deliveries: deliveries})
+	}
+
+	if hooker.config.GitHubSecret != "" {
+		deliveries, err := hooker.log.List(gitHubHook)
+		if err != nil {
+			return nil, err
+		}
+		github := &Hook{Name: gitHubHook, Channel: hooker.config.GitHub.Channel, Workspace: hooker.config.GitHub.Workspace, Verify: verifyHMAC}
+		out = append(out, webHook{Hook: github, URL: "/public/github", Deliveries: deliveries})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out, nil
+}
+
+// handleWebLog renders the delivery log of the hooks
+func (hooker *Hooker) handleWebLog(w http.ResponseWriter, r *http.Request) {
+	hooks, err := hooker.webHooks()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := logTemplate.Execute(w, hooks); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// handleWebLogJSON serves the delivery log of the hooks as JSON
+func (hooker *Hooker) handleWebLogJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not accepted", 405)
+		return
+	}
+
+	hooks, err := hooker.webHooks()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Hooks []webHook `json:"hooks"`
+	}{hooks})
+}
+
+var logTemplate = template.Must(template.New("hooker").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Incoming webhooks</title>
+</head>
+<body>
+  <h1>Incoming webhooks</h1>
+  {{range .}}
+  <h2>{{.Name}}</h2>
+  <p><code>POST {{.URL}}</code> → {{.Channel}}{{if .Workspace}} ({{.Workspace}}){{end}}, verified by {{.Verify}}</p>
+  {{if .Deliveries}}
+  <table>
+    <tr><th>Time</th><th>From</th><th>Result</th><th>Status</th><th>Message or error</th></tr>
+    {{range .Deliveries}}
+    <tr>
+      <td>{{.Time.Format "2006-01-02 15:04:05"}}</td>
+      <td>{{.RemoteAddr}}</td>
+      <td>{{.Result}}</td>
+      <td>{{.Status}}</td>
+      <td>{{if .Error}}{{.Error}}{{else}}<pre>{{.Message}}</pre>{{end}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{else}}
+  <p>No deliveries yet.</p>
+  {{end}}
+  {{else}}
+  <p>No incoming webhooks are configured in the <code>hooker</code> section.</p>
+  {{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,247 @@
+package bawt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nlopes/slack"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultLocale is the locale plugins register their strings in, and
+// the fallback for missing translations
+const DefaultLocale = "en"
+
+/*
+Translation is a localized string with its plural forms. Strings are
+formatted like `Reply`, with `fmt` verbs; indexed verbs (`%[2]s`) let
+translations reorder the arguments. The plural form is picked from the
+first integer argument, and Other is used for the missing forms. A form
+may skip the arguments entirely, like "No tasks".
+
+In translation files, a plain string is the same as setting Other only.
+*/
+type Translation struct {
+	Zero  string `json:"zero,omitempty" yaml:"zero,omitempty"`
+	One   string `json:"one,omitempty" yaml:"one,omitempty"`
+	Other string `json:"other" yaml:"other"`
+}
+
+// UnmarshalJSON accepts a plain string as well as the plural forms
+func (t *Translation) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Translation{Other: s}
+		return nil
+	}
+
+	type forms Translation
+	return json.Unmarshal(data, (*forms)(t))
+}
+
+// UnmarshalYAML accepts a plain string as well as the plural forms
+func (t *Translation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*t = Translation{Other: s}
+		return nil
+	}
+
+	type forms Translation
+	return unmarshal((*forms)(t))
+}
+
+func (t Translation) form(args []interface{}) string {
+	n, ok := pluralCount(args)
+	switch {
+	case ok && n == 0 && t.Zero != "":
+		return t.Zero
+	case ok && n == 1 && t.One != "":
+		return t.One
+	}
+	return t.Other
+}
+
+func pluralCount(args []interface{}) (int64, bool) {
+	for _, arg := range args {
+		switch n := arg.(type) {
+		case int:
+			return int64(n), true
+		case int32:
+			return int64(n), true
+		case int64:
+			return n, true
+		case uint:
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// Catalog holds the translations, by locale and key. It is safe for
+// concurrent use.
+type Catalog struct {
+	lock     sync.RWMutex
+	messages map[string]map[string]Translation
+}
+
+// NewCatalog returns an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[string]Translation)}
+}
+
+var messages = NewCatalog()
+
+// Messages returns the catalog shared by the bot and the plugins
+func Messages() *Catalog {
+	return messages
+}
+
+/*
+RegisterMessages adds translations to the shared catalog. Plugins call
+it from their `init()` with their English strings, which translation
+files loaded from `config.locales_path` can then override or translate.
+*/
+func RegisterMessages(locale string, translations map[string]Translation) {
+	messages.Add(locale, translations)
+}
+
+// Add merges translations for a locale into the catalog
+func (c *Catalog) Add(locale string, translations map[string]Translation) {
+	locale = normalizeLocale(locale)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]Translation)
+	}
+	for key, t := range translations {
+		c.messages[locale][key] = t
+	}
+}
+
+/*
+Load reads the translation files of a directory. Each file is named after
+its locale, like `fr.yaml` or `pt-BR.json`, and maps keys to either a
+string or an object with `zero`, `one` and `other` forms.
+*/
+func (c *Catalog) Load(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+
+		translations := make(map[string]Translation)
+		if ext == ".json" {
+			err = json.Unmarshal(content, &translations)
+		} else {
+			err = yaml.Unmarshal(content, &translations)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", f.Name(), err)
+		}
+
+		c.Add(strings.TrimSuffix(f.Name(), ext), translations)
+	}
+
+	return nil
+}
+
+// Locales returns the locales having at least one translation
+func (c *Catalog) Locales() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var out []string
+	for locale := range c.messages {
+		out = append(out, locale)
+	}
+	return out
+}
+
+/*
+Translate formats the string `key` in the given locale. It falls back
+from a regional locale (`fr-ca`) to its language (`fr`), then to
+DefaultLocale, and finally to the key itself.
+*/
+func (c *Catalog) Translate(locale, key string, args ...interface{}) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, l := range localeChain(locale) {
+		if t, ok := c.messages[l][key]; ok {
+			text := t.form(args)
+			// Plural forms like "No tasks" may not use the arguments
+			if !strings.Contains(text, "%") {
+				return text
+			}
+			return Format(text, args...)
+		}
+	}
+
+	return key
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+func localeChain(locale string) []string {
+	locale = normalizeLocale(locale)
+
+	var chain []string
+	if locale != "" {
+		chain = append(chain, locale)
+		if idx := strings.Index(locale, "-"); idx > 0 {
+			chain = append(chain, locale[:idx])
+		}
+	}
+
+	return append(chain, DefaultLocale)
+}
+
+/*
+LocaleFor picks the locale to talk in: the one configured for the
+channel in `config.channel_locales`, else the user's Slack locale, else
+`config.locale`. Both arguments can be nil.
+*/
+func (bot *Bot) LocaleFor(user *slack.User, channel *Channel) string {
+	if channel != nil {
+		for name, locale := range bot.Config.ChannelLocales {
+			if strings.TrimLeft(name, "#") == channel.Name || name == channel.ID {
+				return locale
+			}
+		}
+	}
+
+	if user != nil && user.Locale != "" {
+		return user.Locale
+	}
+
+	if bot.Config.Locale != "" {
+		return bot.Config.Locale
+	}
+
+	return DefaultLocale
+}
+
+// T translates `key` in the given locale, see `Catalog.Translate`
+func (bot *Bot) T(locale, key string, args ...interface{}) string {
+	return messages.Translate(locale, key, args...)
+}
@@ -0,0 +1,86 @@
+package bawt
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_Translate(t *testing.T) {
+	c := NewCatalog()
+	c.Add("en", map[string]Translation{
+		"hello": {Other: "Hello %s"},
+		"tasks": {Zero: "No tasks", One: "%d task", Other: "%d tasks"},
+		"only":  {Other: "English only"},
+	})
+	c.Add("fr", map[string]Translation{
+		"hello": {Other: "Bonjour %s"},
+		"tasks": {One: "%d tâche", Other: "%d tâches"},
+	})
+	c.Add("fr_CA", map[string]Translation{
+		"hello": {Other: "Allô %s"},
+	})
+
+	tests := []struct {
+		locale string
+		key    string
+		args   []interface{}
+		want   string
+	}{
+		{"en", "hello", []interface{}{"bob"}, "Hello bob"},
+		{"fr", "hello", []interface{}{"bob"}, "Bonjour bob"},
+		{"fr-FR", "hello", []interface{}{"bob"}, "Bonjour bob"},
+		{"fr-CA", "hello", []interface{}{"bob"}, "Allô bob"},
+		{"de", "hello", []interface{}{"bob"}, "Hello bob"},
+		{"", "hello", []interface{}{"bob"}, "Hello bob"},
+		{"fr", "only", nil, "English only"},
+		{"fr", "missing", nil, "missing"},
+		{"en", "tasks", []interface{}{0}, "No tasks"},
+		{"en", "tasks", []interface{}{1}, "1 task"},
+		{"en", "tasks", []interface{}{3}, "3 tasks"},
+		{"fr", "tasks", []interface{}{0}, "0 tâches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Translate(tt.locale, tt.key, tt.args...))
+		})
+	}
+}
+
+func TestCatalog_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	ioutil.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("hello: Bonjour %s\nvotes:\n  one: \"%d vote\"\n  other: \"%d votes\"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "pt-BR.json"), []byte(`{"hello": "Olá %s", "votes": {"one": "%d voto", "other": "%d votos"}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644)
+
+	c := NewCatalog()
+	assert.NoError(t, c.Load(dir))
+	assert.ElementsMatch(t, []string{"fr", "pt-br"}, c.Locales())
+
+	assert.Equal(t, "Bonjour bob", c.Translate("fr", "hello", "bob"))
+	assert.Equal(t, "2 votes", c.Translate("fr", "votes", 2))
+	assert.Equal(t, "1 voto", c.Translate("pt-BR", "votes", 1))
+
+	ioutil.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"hello": `), 0644)
+	assert.Error(t, c.Load(dir))
+}
+
+func TestBot_LocaleFor(t *testing.T) {
+	bot := New("")
+	assert.Equal(t, DefaultLocale, bot.LocaleFor(nil, nil))
+
+	bot.Config.Locale = "de"
+	assert.Equal(t, "de", bot.LocaleFor(nil, nil))
+
+	user := &slack.User{ID: "U1", Locale: "fr-FR"}
+	assert.Equal(t, "fr-FR", bot.LocaleFor(user, nil))
+
+	bot.Config.ChannelLocales = map[string]string{"#general": "es"}
+	assert.Equal(t, "es", bot.LocaleFor(user, &Channel{ID: "C1", Name: "general"}))
+	assert.Equal(t, "fr-FR", bot.LocaleFor(user, &Channel{ID: "C2", Name: "random"}))
+}
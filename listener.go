@@ -92,6 +92,11 @@ type Listener struct {
 	// itself sent.
 	MatchMyMessages bool
 
+	// MainWorkspaceOnly filters out messages and events of the additional
+	// workspaces, for plugins that store data or look users up with the
+	// main workspace's Bot.
+	MainWorkspaceOnly bool
+
 	// MessageHandlerFunc is a handling function provided by the user, and
 	// called when a relevant message comes in.
 	MessageHandlerFunc func(*Listener, *Message)
@@ -222,8 +227,6 @@ func (listen *Listener) filterAndDispatchMessage(msg *Message) {
 
 // filterMessage applies checks from a Listener against a Message.
 func (listen *Listener) filterMessage(msg *Message) bool {
-	log := listen.Bot.Logging.Logger
-
 	if msg.Msg.SubType == "message_deleted" {
 		return false
 	}
@@ -263,27 +266,60 @@ func (listen *Listener) filterMessage(msg *Message) bool {
 		}
 	}
 
-	// If there is no msg.FromUser, the message is filtered out.
-	if listen.FromUser != nil && (msg.FromUser == nil || msg.FromUser.ID != listen.FromUser.ID) {
+	if !listen.AllowsUser(msg.FromUser) {
+		return false
+	}
+
+	if listen.FromChannel != nil {
+		if msg.FromChannel == nil {
+			return false
+		}
+		if msg.FromChannel.ID != listen.FromChannel.ID {
+			return false
+		}
+	}
+
+	if !listen.MatchMyMessages && msg.FromMe {
+		return false
+	}
+
+	return true
+}
+
+// AllowsUser reports whether the permission filters of the listener
+// (`FromUser`, `FromAdmin` and `FromInternalGroup`) let `user` through.
+// Without a user, only a listener without such filters allows it.
+func (listen *Listener) AllowsUser(user *slack.User) bool {
+	restricted := listen.FromUser != nil || listen.FromAdmin || len(listen.FromInternalGroup) > 0
+	if !restricted {
+		return true
+	}
+
+	if user == nil {
+		return false
+	}
+
+	if listen.FromUser != nil && user.ID != listen.FromUser.ID {
 		return false
 	}
 
 	// Both need to be true
-	if listen.FromAdmin && !msg.FromUser.IsAdmin {
+	if listen.FromAdmin && !user.IsAdmin {
 		return false
 	}
 
 	if len(listen.FromInternalGroup) > 0 {
+		log := listen.Bot.Logging.Logger
 		member := false
 
 		for _, g := range listen.FromInternalGroup {
-			log.WithField("user", msg.FromUser.ID).WithField("group", g).Debug("Evaluating Access")
+			log.WithField("user", user.ID).WithField("group", g).Debug("Evaluating Access")
 
 			grp := InternalGroup{
 				Name: g,
 			}
 
-			m, err := grp.IsUserMember(listen.Bot.DB, msg.FromUser.ID)
+			m, err := grp.IsUserMember(listen.Bot.DB, user.ID)
 			if err != nil {
 				log.WithError(err).Error("Error determining if user is a member of group")
 
@@ -292,7 +328,7 @@ func (listen *Listener) filterMessage(msg *Message) bool {
 
 			// If user is a member
 			if m {
-				log.WithField("user", msg.FromUser.ID).WithField("group", g).Debug("Access Granted")
+				log.WithField("user", user.ID).WithField("group", g).Debug("Access Granted")
 				member = true
 
 				break
@@ -300,23 +336,10 @@ func (listen *Listener) filterMessage(msg *Message) bool {
 		}
 
 		if !member {
-			log.WithField("user", msg.FromUser.ID).Debug("Access Denied")
-			return false
-		}
-	}
-
-	if listen.FromChannel != nil {
-		if msg.FromChannel == nil {
-			return false
-		}
-		if msg.FromChannel.ID != listen.FromChannel.ID {
+			log.WithField("user", user.ID).Debug("Access Denied")
 			return false
 		}
 	}
 
-	if !listen.MatchMyMessages && msg.FromMe {
-		return false
-	}
-
 	return true
 }
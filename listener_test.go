@@ -100,3 +100,29 @@ func TestMatchesMessage(t *testing.T) {
 		t.Error("didn't find 'this'")
 	}
 }
+
+func TestAllowsUser(t *testing.T) {
+	admin := &slack.User{ID: "an_admin", IsAdmin: true}
+	user := &slack.User{ID: "a_user"}
+
+	type El struct {
+		c    *Listener
+		user *slack.User
+		r    bool
+	}
+	tests := []El{
+		{&Listener{}, nil, true},
+		{&Listener{}, user, true},
+		{&Listener{FromAdmin: true}, nil, false},
+		{&Listener{FromAdmin: true}, user, false},
+		{&Listener{FromAdmin: true}, admin, true},
+		{&Listener{FromUser: user}, admin, false},
+		{&Listener{FromUser: user}, user, true},
+	}
+
+	for i, el := range tests {
+		if el.c.AllowsUser(el.user) != el.r {
+			t.Errorf("test %d: AllowsUser should be %t", i, el.r)
+		}
+	}
+}
@@ -38,5 +38,9 @@ func (bot *Bot) setupLogging() error {
 	log.SetFormatter(formatter)
 	log.SetLevel(level)
 
+	if bot.Events != nil {
+		bot.Events.log = log
+	}
+
 	return nil
 }
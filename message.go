@@ -24,6 +24,9 @@ type Message struct {
 	FromUser    *slack.User
 	FromChannel *Channel
 
+	// Workspace is the team ID of the workspace the message comes from
+	Workspace string
+
 	// Match contains the result of
 	// Listener.Matches.FindStringSubmatch(msg.Text), when `Matches`
 	// is set on the `Listener`.
@@ -33,6 +36,12 @@ type Message struct {
 // FileUploadParameters are all the parameters needed to upload a file
 type FileUploadParameters slack.FileUploadParameters
 
+// Bot returns the Bot of the workspace the message comes from, to talk
+// back to the right workspace from plugins serving several of them
+func (msg *Message) Bot() *Bot {
+	return msg.bot
+}
+
 // IsPrivate determines if a message is private or not
 func (msg *Message) IsPrivate() bool {
 	return strings.HasPrefix(msg.Channel, "D")
@@ -139,6 +148,76 @@ func (msg *Message) ReplyMention(text string, v ...interface{}) *Reply {
 	return msg.Reply(fmt.Sprintf("%s%s", prefix, text), v...)
 }
 
+// Locale returns the locale to reply in, see `Bot.LocaleFor`
+func (msg *Message) Locale() string {
+	return msg.bot.LocaleFor(msg.FromUser, msg.FromChannel)
+}
+
+// T translates `key` in the locale of the message
+func (msg *Message) T(key string, v ...interface{}) string {
+	return msg.bot.T(msg.Locale(), key, v...)
+}
+
+// ReplyT is like Reply, with the translation of `key`
+func (msg *Message) ReplyT(key string, v ...interface{}) *Reply {
+	return msg.Reply(msg.T(key, v...))
+}
+
+// ReplyMentionT is like ReplyMention, with the translation of `key`
+func (msg *Message) ReplyMentionT(key string, v ...interface{}) *Reply {
+	return msg.ReplyMention(msg.T(key, v...))
+}
+
+// ReplyPrivatelyT is like ReplyPrivately, with the translation of `key`
+// in the user's own locale
+func (msg *Message) ReplyPrivatelyT(key string, v ...interface{}) *Reply {
+	return msg.ReplyPrivately(msg.bot.T(msg.bot.LocaleFor(msg.FromUser, nil), key, v...))
+}
+
+// RandomResponse picks a response of a category, rendered with the
+// user, channel and match groups of the message, see `Responses.Pick`
+func (msg *Message) RandomResponse(category string) string {
+	ctx := &ResponseContext{Match: msg.Match}
+	if msg.FromUser != nil {
+		ctx.User = msg.FromUser.Name
+		ctx.UserID = msg.FromUser.ID
+		ctx.Mention = fmt.Sprintf("<@%s>", msg.FromUser.ID)
+	}
+	if msg.FromChannel != nil {
+		ctx.Channel = msg.FromChannel.Name
+	}
+
+	return ResponseStore().Pick(category, ctx)
+}
+
+// ReplyRandom replies with a RandomResponse of the category. Nothing is
+// sent, and nil is returned, when the category has no responses.
+func (msg *Message) ReplyRandom(category string) *Reply {
+	text := msg.RandomResponse(category)
+	if text == "" {
+		msg.bot.Logging.Logger.WithField("category", category).Warn("No response to reply with, unknown category")
+		return nil
+	}
+	return msg.Reply(text)
+}
+
+// Mood returns the mood of the bot in the message's channel
+func (msg *Message) Mood() Mood {
+	return msg.bot.Moods.In(msg.FromChannel)
+}
+
+// WithMood returns a different response depending on the mood of the
+// bot in the message's channel
+func (msg *Message) WithMood(happy, hyper string) string {
+	return msg.WithMoodMap(map[Mood]string{Happy: happy, Hyper: hyper})
+}
+
+// WithMoodMap returns the response for the mood of the bot in the
+// message's channel
+func (msg *Message) WithMoodMap(variants map[Mood]string) string {
+	return pickMood(msg.Mood(), variants)
+}
+
 // ReplyWithFile replies with a snippet or an attached file
 func (msg *Message) ReplyWithFile(p FileUploadParameters) *ReplyWithFile {
 	/*
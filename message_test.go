@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -145,3 +146,12 @@ func TestShouldReturnTheStringConcatenatedWithAnotherContentType(t *testing.T) {
 	fs := Format(s1, i)
 	assert.Equal(t, fs, fmt.Sprintf(s1, i))
 }
+
+func TestShouldNotReplyRandomWithUnknownCategory(t *testing.T) {
+	bot := New("")
+	bot.Logging.Logger = logrus.New()
+	msg := &Message{Msg: &slack.Msg{Channel: "C1"}, bot: bot}
+
+	assert.Nil(t, msg.ReplyRandom("no such category"))
+	assert.Len(t, bot.outgoingMsgCh, 0)
+}
@@ -1,20 +1,274 @@
 package bawt
 
-// Mood is an enum
-type Mood int
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cskr/pubsub"
+)
+
+// Mood is the name of a mood. Happy and Hyper are always known, other
+// moods come from the configuration, see `MoodEngine.Register`.
+type Mood string
 
 const (
 	// Happy indicates a happy bot
-	Happy Mood = iota
+	Happy Mood = "happy"
 	// Hyper indicates a hyper bot
-	Hyper
+	Hyper Mood = "hyper"
 )
 
+// TopicMoodChanged is published on PubSub with a MoodChange payload
+// every time the mood of the bot, or of a channel, changes
+const TopicMoodChanged = "bawt:mood:changed"
+
+// MoodChange describes a change of mood. Channel is empty for the
+// global mood.
+type MoodChange struct {
+	Channel string
+	From    Mood
+	To      Mood
+	Reason  string
+}
+
+/*
+MoodEngine holds the mood of the bot. The global mood is set by plugins
+like mooder with Set, and can be forced by admins for a while with Force.
+Channels can override it with SetChannel. It is safe for concurrent use.
+*/
+type MoodEngine struct {
+	lock        sync.RWMutex
+	known       map[Mood]bool
+	current     Mood
+	forced      Mood
+	forcedUntil time.Time
+	channels    map[string]Mood // by lowercased channel ID or name
+
+	pubsub *pubsub.PubSub
+}
+
+// NewMoodEngine returns a happy engine publishing its changes on `ps`,
+// which can be nil
+func NewMoodEngine(ps *pubsub.PubSub) *MoodEngine {
+	return &MoodEngine{
+		known:    map[Mood]bool{Happy: true, Hyper: true},
+		current:  Happy,
+		channels: make(map[string]Mood),
+		pubsub:   ps,
+	}
+}
+
+// Register adds moods to the known ones
+func (e *MoodEngine) Register(moods ...Mood) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, m := range moods {
+		e.known[normalizeMood(m)] = true
+	}
+}
+
+// Known returns the known moods, sorted by name
+func (e *MoodEngine) Known() []Mood {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	out := make([]Mood, 0, len(e.known))
+	for m := range e.known {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+
+	return out
+}
+
+// Current returns the global mood
+func (e *MoodEngine) Current() Mood {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.effective(time.Now())
+}
+
+// In returns the mood of a channel, which is its override if any, or the
+// global mood. `channel` can be nil.
+func (e *MoodEngine) In(channel *Channel) Mood {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	if channel != nil {
+		for _, key := range []string{channel.ID, channel.Name} {
+			if m, ok := e.channels[channelKey(key)]; ok && key != "" {
+				return m
+			}
+		}
+	}
+
+	return e.effective(time.Now())
+}
+
+// Forced returns the mood forced by Force and until when, with a zero
+// time meaning "until reset"
+func (e *MoodEngine) Forced() (Mood, time.Time) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	if e.forced == "" || (!e.forcedUntil.IsZero() && time.Now().After(e.forcedUntil)) {
+		return "", time.Time{}
+	}
+
+	return e.forced, e.forcedUntil
+}
+
+// Overrides returns the channel overrides, by channel ID or name
+func (e *MoodEngine) Overrides() map[string]Mood {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	out := make(map[string]Mood, len(e.channels))
+	for k, m := range e.channels {
+		out[k] = m
+	}
+
+	return out
+}
+
+// Set changes the global mood. It has no visible effect while a mood is
+// forced.
+func (e *MoodEngine) Set(m Mood, reason string) error {
+	m = normalizeMood(m)
+
+	e.lock.Lock()
+	if !e.known[m] {
+		e.lock.Unlock()
+		return fmt.Errorf("unknown mood %q", m)
+	}
+
+	before := e.effective(time.Now())
+	e.current = m
+	after := e.effective(time.Now())
+	e.lock.Unlock()
+
+	e.publish("", before, after, reason)
+
+	return nil
+}
+
+// Force sets the global mood until the given time, or until reset when
+// `until` is zero. An empty mood lifts the forced mood.
+func (e *MoodEngine) Force(m Mood, until time.Time, reason string) error {
+	m = normalizeMood(m)
+
+	e.lock.Lock()
+	if m != "" && !e.known[m] {
+		e.lock.Unlock()
+		return fmt.Errorf("unknown mood %q", m)
+	}
+
+	before := e.effective(time.Now())
+	e.forced = m
+	e.forcedUntil = until
+	after := e.effective(time.Now())
+	e.lock.Unlock()
+
+	e.publish("", before, after, reason)
+
+	return nil
+}
+
+// SetChannel overrides the mood of a channel, given by ID or name. An
+// empty mood removes the override.
+func (e *MoodEngine) SetChannel(channel string, m Mood, reason string) error {
+	m = normalizeMood(m)
+	key := channelKey(channel)
+
+	e.lock.Lock()
+	if m != "" && !e.known[m] {
+		e.lock.Unlock()
+		return fmt.Errorf("unknown mood %q", m)
+	}
+
+	before, ok := e.channels[key]
+	if !ok {
+		before = e.effective(time.Now())
+	}
+
+	after := m
+	if m == "" {
+		delete(e.channels, key)
+		after = e.effective(time.Now())
+	} else {
+		e.channels[key] = m
+	}
+	e.lock.Unlock()
+
+	e.publish(key, before, after, reason)
+
+	return nil
+}
+
+// effective returns the global mood, the lock must be held
+func (e *MoodEngine) effective(now time.Time) Mood {
+	if e.forced != "" && (e.forcedUntil.IsZero() || now.Before(e.forcedUntil)) {
+		return e.forced
+	}
+
+	return e.current
+}
+
+func (e *MoodEngine) publish(channel string, from, to Mood, reason string) {
+	if e.pubsub == nil || from == to {
+		return
+	}
+
+	e.pubsub.TryPub(MoodChange{Channel: channel, From: from, To: to, Reason: reason}, TopicMoodChanged)
+}
+
+func normalizeMood(m Mood) Mood {
+	return Mood(strings.ToLower(strings.TrimSpace(string(m))))
+}
+
+func channelKey(channel string) string {
+	return strings.ToLower(strings.TrimLeft(channel, "#"))
+}
+
+/*
+pickMood returns the variant for a mood. Moods without a variant fall
+back to the Happy one, and then to the first variant by mood name, so
+that a map written for two moods still works with configured ones.
+*/
+func pickMood(m Mood, variants map[Mood]string) string {
+	if text, ok := variants[m]; ok {
+		return text
+	}
+
+	if text, ok := variants[Happy]; ok {
+		return text
+	}
+
+	keys := make([]string, 0, len(variants))
+	for k := range variants {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	return variants[Mood(keys[0])]
+}
+
 // WithMood returns a different response depending on the mood
 func (bot *Bot) WithMood(happy, hyper string) string {
-	if bot.Mood == Happy {
-		return happy
-	}
+	return bot.WithMoodMap(map[Mood]string{Happy: happy, Hyper: hyper})
+}
 
-	return hyper
+// WithMoodMap returns the response for the current global mood, see
+// `Message.WithMoodMap` to follow the channel overrides
+func (bot *Bot) WithMoodMap(variants map[Mood]string) string {
+	return pickMood(bot.Moods.Current(), variants)
 }
@@ -0,0 +1,83 @@
+package bawt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cskr/pubsub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoodEngine(t *testing.T) {
+	ps := pubsub.New(10)
+	changes := ps.Sub(TopicMoodChanged)
+
+	e := NewMoodEngine(ps)
+	assert.Equal(t, Happy, e.Current())
+
+	assert.Error(t, e.Set("grumpy", "test"))
+	e.Register("Grumpy")
+	assert.Equal(t, []Mood{"grumpy", Happy, Hyper}, e.Known())
+
+	assert.NoError(t, e.Set("grumpy", "test"))
+	assert.Equal(t, Mood("grumpy"), e.Current())
+	assert.Equal(t, MoodChange{From: Happy, To: "grumpy", Reason: "test"}, <-changes)
+
+	// Forced moods win over Set until they expire or are reset
+	assert.NoError(t, e.Force(Hyper, time.Time{}, "admin"))
+	assert.NoError(t, e.Set(Happy, "roll"))
+	assert.Equal(t, Hyper, e.Current())
+	forced, until := e.Forced()
+	assert.Equal(t, Hyper, forced)
+	assert.True(t, until.IsZero())
+
+	assert.NoError(t, e.Force("", time.Time{}, "admin"))
+	assert.Equal(t, Happy, e.Current())
+
+	assert.NoError(t, e.Force(Hyper, time.Now().Add(-time.Minute), "admin"))
+	assert.Equal(t, Happy, e.Current())
+	forced, _ = e.Forced()
+	assert.Equal(t, Mood(""), forced)
+}
+
+func TestMoodEngine_Channels(t *testing.T) {
+	e := NewMoodEngine(nil)
+
+	general := &Channel{}
+	general.ID = "C1"
+	general.Name = "general"
+	random := &Channel{}
+	random.ID = "C2"
+	random.Name = "random"
+
+	assert.NoError(t, e.SetChannel("#random", Hyper, "config"))
+	assert.NoError(t, e.SetChannel("C1", Hyper, "config"))
+	assert.Error(t, e.SetChannel("C1", "sleepy", "config"))
+
+	assert.Equal(t, Hyper, e.In(general))
+	assert.Equal(t, Hyper, e.In(random))
+	assert.Equal(t, Happy, e.In(nil))
+	assert.Equal(t, map[string]Mood{"random": Hyper, "c1": Hyper}, e.Overrides())
+
+	assert.NoError(t, e.SetChannel("C1", "", "reset"))
+	assert.Equal(t, Happy, e.In(general))
+}
+
+func TestPickMood(t *testing.T) {
+	tests := []struct {
+		mood     Mood
+		variants map[Mood]string
+		expected string
+	}{
+		{Happy, map[Mood]string{Happy: "a", Hyper: "b"}, "a"},
+		{Hyper, map[Mood]string{Happy: "a", Hyper: "b"}, "b"},
+		{"grumpy", map[Mood]string{Happy: "a", Hyper: "b", "grumpy": "c"}, "c"},
+		{"grumpy", map[Mood]string{Happy: "a", Hyper: "b"}, "a"},
+		{"grumpy", map[Mood]string{"sleepy": "z", Hyper: "b"}, "b"},
+		{Happy, map[Mood]string{}, ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, pickMood(test.mood, test.variants))
+	}
+}
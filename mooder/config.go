@@ -0,0 +1,158 @@
+package mooder
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+/*
+Config is the `mood` section of the configuration, like:
+
+	mood:
+	  moods:
+	    happy: 0.6
+	    hyper: 0.3
+	    grumpy: 0.1
+	  schedules:
+	    - mood: grumpy
+	      days: [monday]
+	      from: "08:00"
+	      to: "12:00"
+	  channels:
+	    "#random": hyper
+	  triggers:
+	    - topic: "recognition:recognized"
+	      mood: hyper
+	      duration: 1h
+	    - topic: "bawt:health:changed"
+	      state: failing
+	      mood: grumpy
+
+Moods are rolled every weekday at noon with the given probabilities.
+Schedules win over the roll, and triggers win over schedules for their
+duration.
+*/
+type Config struct {
+	Moods     map[string]float64
+	Schedules []Schedule
+	Channels  map[string]string
+	Triggers  []Trigger
+}
+
+// Schedule sets a mood on some days, between two times of the day. No
+// days means every day.
+type Schedule struct {
+	Mood string
+	Days []string
+	From string
+	To   string
+}
+
+// Trigger sets a mood for a while when something is published on a
+// PubSub topic. For `bawt:health:changed`, State only triggers on
+// components reaching that state.
+type Trigger struct {
+	Topic    string
+	Mood     string
+	Duration string
+	State    string
+}
+
+// defaultMoods are the odds of the historical happy/hyper roll
+var defaultMoods = map[string]float64{
+	string(bawt.Happy): 0.7,
+	string(bawt.Hyper): 0.3,
+}
+
+// defaultTriggerDuration applies to triggers without a duration
+const defaultTriggerDuration = time.Hour
+
+// Active tells whether the schedule applies at the given time
+func (s Schedule) Active(t time.Time) (bool, error) {
+	if len(s.Days) > 0 {
+		found := false
+		for _, d := range s.Days {
+			if strings.EqualFold(d, t.Weekday().String()) || strings.EqualFold(d, t.Weekday().String()[:3]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	from, err := minuteOfDay(s.From, 0)
+	if err != nil {
+		return false, err
+	}
+	to, err := minuteOfDay(s.To, 24*60)
+	if err != nil {
+		return false, err
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return now >= from && now < to, nil
+	}
+
+	// Overnight, like 22:00 to 06:00
+	return now >= from || now < to, nil
+}
+
+// minuteOfDay parses "15:04" into minutes since midnight
+func minuteOfDay(s string, empty int) (int, error) {
+	if s == "" {
+		return empty, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, use HH:MM", s)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// duration returns how long the trigger lasts
+func (t Trigger) duration() (time.Duration, error) {
+	if t.Duration == "" {
+		return defaultTriggerDuration, nil
+	}
+
+	return time.ParseDuration(t.Duration)
+}
+
+// roll picks a mood with the configured probabilities
+func roll(moods map[string]float64, r *rand.Rand) bawt.Mood {
+	names := make([]string, 0, len(moods))
+	total := 0.0
+	for name, p := range moods {
+		if p > 0 {
+			names = append(names, name)
+			total += p
+		}
+	}
+
+	if len(names) == 0 {
+		return bawt.Happy
+	}
+
+	// Sorted, so that a seeded roll is reproducible
+	sort.Strings(names)
+
+	n := r.Float64() * total
+	for _, name := range names {
+		n -= moods[name]
+		if n < 0 {
+			return bawt.Mood(name)
+		}
+	}
+
+	return bawt.Mood(names[len(names)-1])
+}
@@ -0,0 +1,65 @@
+package mooder
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+func TestScheduleActive(t *testing.T) {
+	// 2019-04-01 is a Monday
+	monday9 := time.Date(2019, 4, 1, 9, 0, 0, 0, time.UTC)
+	monday13 := time.Date(2019, 4, 1, 13, 0, 0, 0, time.UTC)
+	tuesday9 := time.Date(2019, 4, 2, 9, 0, 0, 0, time.UTC)
+	tuesday23 := time.Date(2019, 4, 2, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		schedule Schedule
+		at       time.Time
+		expected bool
+	}{
+		{Schedule{Days: []string{"monday"}, From: "08:00", To: "12:00"}, monday9, true},
+		{Schedule{Days: []string{"Mon"}, From: "08:00", To: "12:00"}, monday13, false},
+		{Schedule{Days: []string{"monday"}, From: "08:00", To: "12:00"}, tuesday9, false},
+		{Schedule{From: "08:00"}, tuesday9, true},
+		{Schedule{}, monday13, true},
+		{Schedule{From: "22:00", To: "06:00"}, tuesday23, true},
+		{Schedule{From: "22:00", To: "06:00"}, tuesday9, false},
+	}
+
+	for i, test := range tests {
+		active, err := test.schedule.Active(test.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active != test.expected {
+			t.Errorf("%d: expected %v, got %v", i, test.expected, active)
+		}
+	}
+
+	if _, err := (Schedule{From: "8am"}).Active(monday9); err == nil {
+		t.Error("Should fail on invalid times")
+	}
+}
+
+func TestRoll(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	if m := roll(map[string]float64{}, r); m != bawt.Happy {
+		t.Errorf("Should default to happy, got %s", m)
+	}
+
+	if m := roll(map[string]float64{"grumpy": 1, "happy": 0}, r); m != "grumpy" {
+		t.Errorf("Should be grumpy, got %s", m)
+	}
+
+	counts := map[bawt.Mood]int{}
+	for i := 0; i < 1000; i++ {
+		counts[roll(defaultMoods, r)]++
+	}
+	if counts[bawt.Happy] < counts[bawt.Hyper] {
+		t.Errorf("Should be happy more often than hyper, got %v", counts)
+	}
+}
@@ -2,40 +2,81 @@ package mooder
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/gopherworks/bawt"
 )
 
+// Mooder changes the mood of the bot, see Config
 type Mooder struct {
-	bot *bawt.Bot
+	bot    *bawt.Bot
+	config Config
+	rand   *rand.Rand
+
+	lock         sync.Mutex
+	rolled       bawt.Mood
+	trigger      bawt.Mood
+	triggerUntil time.Time
+	reason       string
 }
 
 func init() {
 	bawt.RegisterPlugin(&Mooder{})
 }
 
+// InitPlugin loads the moods and starts changing them
 func (mooder *Mooder) InitPlugin(bot *bawt.Bot) {
 	mooder.bot = bot
+	mooder.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var conf struct {
+		Mood Config
+	}
+	bot.LoadConfig(&conf)
+	mooder.config = conf.Mood
+
+	if len(mooder.config.Moods) == 0 {
+		mooder.config.Moods = defaultMoods
+	}
+
+	log := bot.Logging.Logger
+
+	for name := range mooder.config.Moods {
+		bot.Moods.Register(bawt.Mood(name))
+	}
+	for _, s := range mooder.config.Schedules {
+		bot.Moods.Register(bawt.Mood(s.Mood))
+	}
+	for _, t := range mooder.config.Triggers {
+		bot.Moods.Register(bawt.Mood(t.Mood))
+	}
+
+	for channel, mood := range mooder.config.Channels {
+		bot.Moods.Register(bawt.Mood(mood))
+		if err := bot.Moods.SetChannel(channel, bawt.Mood(mood), "configuration"); err != nil {
+			log.WithError(err).Errorf("Invalid mood for channel %s", channel)
+		}
+	}
+
+	for _, t := range mooder.config.Triggers {
+		mooder.listenTrigger(t)
+	}
+
 	go mooder.SetupMoodChanger()
+	go mooder.applyLoop()
 }
 
+// SetupMoodChanger rolls a new mood now, then every weekday at noon
 func (mooder *Mooder) SetupMoodChanger() {
-	bot := mooder.bot
 	for {
-		time.Sleep(10 * time.Second)
-		newMood := bawt.Happy
-
-		rand.Seed(time.Now().UTC().UnixNano())
+		mood := roll(mooder.config.Moods, mooder.rand)
 
-		happyChances := rand.Int() % 10
-		if happyChances > 6 {
-			newMood = bawt.Hyper
-		}
+		mooder.lock.Lock()
+		mooder.rolled = mood
+		mooder.lock.Unlock()
 
-		bot.Mood = newMood
-
-		//bot.SendToChannel(bot.Config.GeneralChannel, bot.WithMood("I'm quite happy today.", "I can haz!! It's going to be a great one today!!"))
+		mooder.apply(time.Now())
 
 		select {
 		case <-bawt.AfterNextWeekdayTime(time.Now(), time.Monday, 12, 0):
@@ -46,3 +87,73 @@ func (mooder *Mooder) SetupMoodChanger() {
 		}
 	}
 }
+
+// applyLoop follows the schedules and the end of triggers
+func (mooder *Mooder) applyLoop() {
+	for {
+		time.Sleep(time.Minute)
+		mooder.apply(time.Now())
+	}
+}
+
+// apply sets the mood that wins at the given time
+func (mooder *Mooder) apply(now time.Time) {
+	mood, reason := mooder.resolve(now)
+	if mood == "" {
+		return
+	}
+
+	if err := mooder.bot.Moods.Set(mood, reason); err != nil {
+		mooder.bot.Logging.Logger.WithError(err).Error("Couldn't change the mood")
+	}
+}
+
+// resolve returns the active trigger's mood, else the first active
+// schedule's, else the rolled one
+func (mooder *Mooder) resolve(now time.Time) (bawt.Mood, string) {
+	mooder.lock.Lock()
+	defer mooder.lock.Unlock()
+
+	if mooder.trigger != "" && now.Before(mooder.triggerUntil) {
+		return mooder.trigger, mooder.reason
+	}
+
+	for _, s := range mooder.config.Schedules {
+		active, err := s.Active(now)
+		if err != nil {
+			mooder.bot.Logging.Logger.WithError(err).Errorf("Invalid schedule for mood %s", s.Mood)
+			continue
+		}
+		if active {
+			return bawt.Mood(s.Mood), "schedule"
+		}
+	}
+
+	return mooder.rolled, "daily roll"
+}
+
+// listenTrigger changes the mood when something is published on the
+// trigger's topic
+func (mooder *Mooder) listenTrigger(t Trigger) {
+	log := mooder.bot.Logging.Logger
+
+	d, err := t.duration()
+	if err != nil {
+		log.WithError(err).Errorf("Invalid duration for the %s trigger", t.Topic)
+		return
+	}
+
+	mooder.bot.Events.Subscribe(func(ev interface{}) {
+		if comp, ok := ev.(bawt.ComponentStatus); ok && t.State != "" && comp.State.String() != t.State {
+			return
+		}
+
+		mooder.lock.Lock()
+		mooder.trigger = bawt.Mood(t.Mood)
+		mooder.triggerUntil = time.Now().Add(d)
+		mooder.reason = t.Topic
+		mooder.lock.Unlock()
+
+		mooder.apply(time.Now())
+	}, t.Topic)
+}
@@ -3,7 +3,9 @@ package bawt
 import (
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
@@ -68,6 +70,54 @@ func RegisteredPlugins() []Plugin {
 	return registeredPlugins
 }
 
+/*
+AdminCommand is a `!bawt <Name>` subcommand provided by a plugin. It is
+run by the `!bawt` listener of the help plugin, and so is restricted to
+GlobalAdmins. Commands documents its usage.
+*/
+type AdminCommand struct {
+	Name     string
+	Commands []Command
+	Handler  func(*Listener, *Message)
+}
+
+var (
+	adminCommandsLock sync.RWMutex
+	adminCommands     = make(map[string]AdminCommand)
+)
+
+// RegisterAdminCommand adds or replaces a `!bawt` subcommand
+func RegisterAdminCommand(cmd AdminCommand) {
+	adminCommandsLock.Lock()
+	defer adminCommandsLock.Unlock()
+
+	adminCommands[cmd.Name] = cmd
+}
+
+// FindAdminCommand returns the `!bawt` subcommand with the given name
+func FindAdminCommand(name string) (AdminCommand, bool) {
+	adminCommandsLock.RLock()
+	defer adminCommandsLock.RUnlock()
+
+	cmd, ok := adminCommands[name]
+	return cmd, ok
+}
+
+// AdminCommands returns the `!bawt` subcommands provided by plugins,
+// sorted by name
+func AdminCommands() []AdminCommand {
+	adminCommandsLock.RLock()
+	defer adminCommandsLock.RUnlock()
+
+	out := make([]AdminCommand, 0, len(adminCommands))
+	for _, cmd := range adminCommands {
+		out = append(out, cmd)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
 func initPlugins(bot *Bot) {
 	var enabledPlugins []string
 
@@ -94,7 +144,7 @@ func initPlugins(bot *Bot) {
 
 		log.Infof("Plugin %s implements %s", pluginType.String(),
 			strings.Join(typeList, ", "))
-		enabledPlugins = append(enabledPlugins, strings.Replace(pluginType.String(), ".", "_", -1))
+		enabledPlugins = append(enabledPlugins, pluginName(plugin))
 	}
 
 	initWebServer(bot, enabledPlugins)
@@ -107,6 +157,11 @@ func initPlugins(bot *Bot) {
 	initChatPlugins(bot)
 }
 
+// pluginName identifies a plugin for the web UI, `*help.Help` becomes `*help_Help`
+func pluginName(plugin Plugin) string {
+	return strings.Replace(reflect.TypeOf(plugin).String(), ".", "_", -1)
+}
+
 func initChatPlugins(bot *Bot) {
 	for _, plugin := range registeredPlugins {
 		chatPlugin, ok := plugin.(PluginInitializer)
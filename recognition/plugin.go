@@ -13,8 +13,13 @@ type Plugin struct {
 	store  Store
 }
 
+// TopicRecognized is published with the new *Recognition when someone
+// is recognized
+const TopicRecognized = "recognition:recognized"
+
 func init() {
 	bawt.RegisterPlugin(&Plugin{})
+	bawt.RegisterEvent(TopicRecognized, &Recognition{})
 }
 
 func (p *Plugin) InitPlugin(bot *bawt.Bot) {
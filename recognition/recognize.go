@@ -16,6 +16,7 @@ func (p *Plugin) listenRecognize() {
 	p.bot.Listen(&bawt.Listener{
 		Matches:            regexp.MustCompile(`!recognize ((<@U[A-Z0-9]+(|[a-zA-Z0-9_-])?>(, ?| and )?)+) for (.*)`),
 		MessageHandlerFunc: p.handleRecognize,
+		MainWorkspaceOnly:  true,
 		Name:               "Recognition",
 		Description:        "A fun game that encourages team mates to learn each others faces",
 	})
@@ -67,7 +68,9 @@ func (p *Plugin) handleRecognize(listen *bawt.Listener, msg *bawt.Message) {
 		}
 		p.store.Put(recog)
 
-		p.bot.PubSub.Pub(recog, "recognition:recognized")
+		if err := p.bot.Events.Publish(TopicRecognized, recog); err != nil {
+			p.bot.ReportError("recognition", err)
+		}
 
 		//fmt.Println("Timestamp for the message:", ts)
 	})
@@ -10,8 +10,9 @@ import (
 
 func (p *Plugin) listenUpvotes() {
 	p.bot.Listen(&bawt.Listener{
-		Name:        "Recognition",
-		Description: "A fun game that encourages team mates to learn each others faces",
+		Name:              "Recognition",
+		Description:       "A fun game that encourages team mates to learn each others faces",
+		MainWorkspaceOnly: true,
 		EventHandlerFunc: func(_ *bawt.Listener, event interface{}) {
 			react := bawt.ParseReactionEvent(event)
 			if react == nil {
@@ -24,7 +25,7 @@ func (p *Plugin) listenUpvotes() {
 				return
 			}
 
-			user := p.bot.Users[react.User]
+			user, _ := p.bot.Users.Get(react.User)
 			if user.IsBot {
 				log.Println("Not taking votes from bots")
 				return
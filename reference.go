@@ -0,0 +1,333 @@
+package bawt
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	"github.com/nlopes/slack"
+)
+
+// Reference documents the commands and web routes of a bot, as generated
+// from the metadata of its listeners
+type Reference struct {
+	Plugins []PluginReference `json:"plugins"`
+	Routes  []RouteReference  `json:"routes"`
+}
+
+// PluginReference gathers the commands of the listeners sharing a slug
+type PluginReference struct {
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	Description string             `json:"description"`
+	Commands    []CommandReference `json:"commands"`
+}
+
+// CommandReference is a command, along with who can run it and where
+type CommandReference struct {
+	Usage       string   `json:"usage"`
+	HelpText    string   `json:"help_text"`
+	Permissions []string `json:"permissions"`
+	Scope       string   `json:"scope"`
+}
+
+// RouteReference is a route of the web server. Private routes sit
+// behind the WebServerAuth plugin, if any.
+type RouteReference struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+	Private bool     `json:"private"`
+}
+
+// TopicSlug returns the slug grouping the listener in the help and the
+// command reference: `Slug` if set, or the name, lower-cased with only its
+// letters, digits and dashes (`To Do` becomes `todo`).
+func (listen *Listener) TopicSlug() string {
+	if listen.Slug != "" {
+		return listen.Slug
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(listen.Name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Permissions describes the permission filters of the listener
+func (listen *Listener) Permissions() []string {
+	var perms []string
+	if listen.FromUser != nil {
+		perms = append(perms, "user "+listen.FromUser.ID)
+	}
+	if listen.FromAdmin {
+		perms = append(perms, "Slack admins")
+	}
+	for _, g := range listen.FromInternalGroup {
+		perms = append(perms, "group "+g)
+	}
+	if listen.FromChannel != nil {
+		perms = append(perms, "channel #"+listen.FromChannel.Name)
+	}
+	if len(perms) == 0 {
+		perms = append(perms, "everyone")
+	}
+	return perms
+}
+
+// Scope describes where the listener answers: `public`, `private` or
+// `public, private`, followed by `mention only` when it must be mentioned
+func (listen *Listener) Scope() string {
+	scope := "public, private"
+	switch {
+	case listen.PrivateOnly:
+		scope = "private"
+	case listen.PublicOnly:
+		scope = "public"
+	}
+	if listen.MentionsMeOnly {
+		scope += ", mention only"
+	}
+	return scope
+}
+
+// CommandReference builds the reference of the listeners, the `!bawt`
+// subcommands and the web routes of the bot
+func (bot *Bot) CommandReference() *Reference {
+	ref := &Reference{
+		Plugins: PluginReferences(bot.Listeners(), AdminCommands()),
+	}
+
+	if bot.WebServer != nil {
+		ref.Routes = append(ref.Routes, walkRoutes(bot.WebServer.PublicRouter(), false)...)
+		ref.Routes = append(ref.Routes, walkRoutes(bot.WebServer.PrivateRouter(), true)...)
+	}
+
+	return ref
+}
+
+// PluginReferences groups the commands of the listeners by slug, sorted by
+// slug. The `!bawt` subcommands are folded into the `bawt` plugin, when its
+// listener is among them.
+func PluginReferences(listeners []*Listener, admin []AdminCommand) []PluginReference {
+	bySlug := map[string]*PluginReference{}
+	seen := map[string]bool{}
+
+	for _, l := range listeners {
+		slug := l.TopicSlug()
+		if len(l.Commands) == 0 || slug == "" {
+			continue
+		}
+
+		p, ok := bySlug[slug]
+		if !ok {
+			p = &PluginReference{Name: l.Name, Slug: slug, Description: l.Description}
+			bySlug[slug] = p
+		}
+
+		cmds := l.Commands
+		if slug == "bawt" {
+			for _, a := range admin {
+				cmds = append(cmds, a.Commands...)
+			}
+		}
+
+		// Plugins often register the same commands on several listeners
+		for _, c := range cmds {
+			if seen[slug+"\x00"+c.Usage] {
+				continue
+			}
+			seen[slug+"\x00"+c.Usage] = true
+
+			p.Commands = append(p.Commands, CommandReference{
+				Usage:       c.Usage,
+				HelpText:    c.HelpText,
+				Permissions: l.Permissions(),
+				Scope:       l.Scope(),
+			})
+		}
+	}
+
+	plugins := make([]PluginReference, 0, len(bySlug))
+	for _, p := range bySlug {
+		plugins = append(plugins, *p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Slug < plugins[j].Slug })
+
+	return plugins
+}
+
+func walkRoutes(router *mux.Router, private bool) []RouteReference {
+	var routes []RouteReference
+	if router == nil {
+		return routes
+	}
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		routes = append(routes, RouteReference{Path: path, Methods: methods, Private: private})
+		return nil
+	})
+
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+
+	return routes
+}
+
+/*
+InitOffline loads the config and initializes the plugins, chat and web,
+without connecting to Slack nor serving HTTP, so that the listeners and
+routes they register can be inspected, e.g. with CommandReference. The
+plugins write to a throwaway database rather than `db_path`, which the
+running bot may hold.
+*/
+func (bot *Bot) InitOffline() error {
+	if err := bot.LoadConfig(bot); err != nil {
+		return err
+	}
+
+	if err := bot.setupLogging(); err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "bawt")
+	if err != nil {
+		return err
+	}
+	// Unlinked right away, the open database remains usable
+	defer os.RemoveAll(dir)
+
+	db, err := bolt.Open(dir+"/bawt.db", 0600, nil)
+	if err != nil {
+		return fmt.Errorf("Could not initialize BoltDB key/value store: %s", err)
+	}
+	bot.DB = db
+
+	if err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(Groups)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists([]byte(AuditBucket))
+		return err
+	}); err != nil {
+		return err
+	}
+
+	bot.Slack = slack.New(bot.Config.APIToken)
+
+	var enabledPlugins []string
+	for _, plugin := range registeredPlugins {
+		enabledPlugins = append(enabledPlugins, pluginName(plugin))
+	}
+
+	initWebServer(bot, enabledPlugins)
+	initWebPlugins(bot)
+	initChatPlugins(bot)
+
+	// The message handler isn't running, collect the listeners ourselves
+	for {
+		select {
+		case listen := <-bot.addListenerCh:
+			bot.listeners = append(bot.listeners, listen)
+		default:
+			return nil
+		}
+	}
+}
+
+// WriteMarkdown writes the reference as a Markdown document
+func (ref *Reference) WriteMarkdown(w io.Writer) error {
+	return referenceMarkdown.Execute(w, ref)
+}
+
+// WriteHTML writes the reference as an HTML page
+func (ref *Reference) WriteHTML(w io.Writer) error {
+	return referenceHTML.Execute(w, ref)
+}
+
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
+
+var referenceFuncs = template.FuncMap{
+	"cell": markdownCell,
+	"join": strings.Join,
+	"access": func(private bool) string {
+		if private {
+			return "private"
+		}
+		return "public"
+	},
+}
+
+var referenceMarkdown = texttemplate.Must(texttemplate.New("reference.md").Funcs(texttemplate.FuncMap(referenceFuncs)).Parse(`# Command reference
+{{range .Plugins}}
+## {{.Name}} (` + "`{{.Slug}}`" + `)
+
+{{.Description}}
+
+| Command | Description | Permissions | Scope |
+| ------- | ----------- | ----------- | ----- |
+{{range .Commands}}| ` + "`{{cell .Usage}}`" + ` | {{cell .HelpText}} | {{join .Permissions ", "}} | {{.Scope}} |
+{{end}}{{end}}{{if .Routes}}
+## Web routes
+
+| Path | Methods | Access |
+| ---- | ------- | ------ |
+{{range .Routes}}| ` + "`{{.Path}}`" + ` | {{if .Methods}}{{join .Methods ", "}}{{else}}any{{end}} | {{access .Private}} |
+{{end}}{{end}}`))
+
+var referenceHTML = template.Must(template.New("reference").Funcs(referenceFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Command reference</title>
+</head>
+<body>
+  <h1>Command reference</h1>
+  {{range .Plugins}}
+  <h2 id="{{.Slug}}">{{.Name}} <code>{{.Slug}}</code></h2>
+  <p>{{.Description}}</p>
+  <table>
+    <tr><th>Command</th><th>Description</th><th>Permissions</th><th>Scope</th></tr>
+    {{range .Commands}}
+    <tr>
+      <td><code>{{.Usage}}</code></td>
+      <td>{{.HelpText}}</td>
+      <td>{{join .Permissions ", "}}</td>
+      <td>{{.Scope}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{end}}
+  {{if .Routes}}
+  <h2 id="routes">Web routes</h2>
+  <table>
+    <tr><th>Path</th><th>Methods</th><th>Access</th></tr>
+    {{range .Routes}}
+    <tr>
+      <td><code>{{.Path}}</code></td>
+      <td>{{if .Methods}}{{join .Methods ", "}}{{else}}any{{end}}</td>
+      <td>{{access .Private}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{end}}
+</body>
+</html>
+`))
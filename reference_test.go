@@ -0,0 +1,91 @@
+package bawt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListener_TopicSlug(t *testing.T) {
+	tests := []struct {
+		listen *Listener
+		want   string
+	}{
+		{&Listener{Name: "To Do"}, "todo"},
+		{&Listener{Name: "Faceoff"}, "faceoff"},
+		{&Listener{Name: "Help", Slug: "bawt"}, "bawt"},
+		{&Listener{Name: "Stand-up #1"}, "stand-up1"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.listen.TopicSlug())
+	}
+}
+
+func TestListener_PermissionsAndScope(t *testing.T) {
+	tests := []struct {
+		listen *Listener
+		perms  []string
+		scope  string
+	}{
+		{&Listener{}, []string{"everyone"}, "public, private"},
+		{&Listener{FromAdmin: true, PrivateOnly: true}, []string{"Slack admins"}, "private"},
+		{&Listener{FromInternalGroup: []string{"GlobalAdmins"}, PublicOnly: true, MentionsMeOnly: true}, []string{"group GlobalAdmins"}, "public, mention only"},
+		{&Listener{FromUser: &slack.User{ID: "U1"}, FromChannel: &Channel{Name: "ops"}}, []string{"user U1", "channel #ops"}, "public, private"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.perms, test.listen.Permissions())
+		assert.Equal(t, test.scope, test.listen.Scope())
+	}
+}
+
+func TestPluginReferences(t *testing.T) {
+	listeners := []*Listener{
+		{Name: "To Do", Commands: []Command{{Usage: "!todo"}, {Usage: "!todo add <task>"}}},
+		{Name: "To Do", Commands: []Command{{Usage: "!todo add <task>"}, {Usage: "!todo close <id>"}}},
+		{Name: "Admin", Slug: "bawt", FromInternalGroup: []string{"GlobalAdmins"}, Commands: []Command{{Usage: "!bawt status"}}},
+		{Name: "Silent"},
+	}
+	admin := []AdminCommand{{Name: "webhooks", Commands: []Command{{Usage: "!bawt webhooks"}}}}
+
+	plugins := PluginReferences(listeners, admin)
+
+	if assert.Len(t, plugins, 2) {
+		assert.Equal(t, "bawt", plugins[0].Slug)
+		assert.Len(t, plugins[0].Commands, 2)
+		assert.Equal(t, []string{"group GlobalAdmins"}, plugins[0].Commands[1].Permissions)
+		assert.Equal(t, "todo", plugins[1].Slug)
+		assert.Len(t, plugins[1].Commands, 3)
+	}
+
+	assert.Len(t, PluginReferences(listeners[:2], admin), 1)
+}
+
+func TestReference_Write(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/bawt/commands", nil).Methods("GET")
+
+	ref := &Reference{
+		Plugins: PluginReferences([]*Listener{{
+			Name:        "Search",
+			Description: "Searches <things>",
+			Commands:    []Command{{Usage: "!search a|b", HelpText: "Searches\nmessages"}},
+		}}, nil),
+		Routes: walkRoutes(router, true),
+	}
+
+	var md bytes.Buffer
+	assert.NoError(t, ref.WriteMarkdown(&md))
+	assert.Contains(t, md.String(), "## Search (`search`)\n\nSearches <things>\n")
+	assert.Contains(t, md.String(), "| `!search a\\|b` | Searches messages | everyone | public, private |\n")
+	assert.Contains(t, md.String(), "| `/bawt/commands` | GET | private |\n")
+
+	var html bytes.Buffer
+	assert.NoError(t, ref.WriteHTML(&html))
+	assert.Contains(t, html.String(), "<p>Searches &lt;things&gt;</p>")
+	assert.Contains(t, html.String(), "<td><code>/bawt/commands</code></td>")
+}
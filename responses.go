@@ -0,0 +1,349 @@
+package bawt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+/*
+Response is one entry of a response pack. Weight defaults to 1; an entry
+with a weight of 2 comes up twice as often. Text can be a `text/template`
+rendered with a ResponseContext, like "Nice one {{.Mention}}!".
+
+In pack files, a plain string is the same as a Response with only Text.
+*/
+type Response struct {
+	Text   string  `json:"text" yaml:"text"`
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// UnmarshalJSON accepts a plain string as well as an object
+func (r *Response) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = Response{Text: s}
+		return nil
+	}
+
+	type entry Response
+	return json.Unmarshal(data, (*entry)(r))
+}
+
+// UnmarshalYAML accepts a plain string as well as a mapping
+func (r *Response) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*r = Response{Text: s}
+		return nil
+	}
+
+	type entry Response
+	return unmarshal((*entry)(r))
+}
+
+func (r Response) weight() float64 {
+	if r.Weight <= 0 {
+		return 1
+	}
+	return r.Weight
+}
+
+// ResponseContext is the data available to response templates
+type ResponseContext struct {
+	User    string   // Name of the user we answer to
+	UserID  string   // ID of the user we answer to
+	Mention string   // `<@ID>` of the user we answer to
+	Channel string   // Name of the channel
+	Match   []string // Match groups of the Listener's `Matches`
+}
+
+// ResponsePack describes a category of responses, see `Responses.Packs`
+type ResponsePack struct {
+	Category string
+	Count    int
+	Source   string // "builtin", or the files it comes from
+}
+
+/*
+Responses holds categories of responses, registered from Go with
+RegisterStringList or loaded from pack files. Categories found in files
+replace the registered ones. It is safe for concurrent use.
+*/
+type Responses struct {
+	lock    sync.Mutex
+	builtin map[string][]Response
+	files   map[string][]Response
+	sources map[string][]string
+	last    map[string]string // last text picked, per category
+	rand    *rand.Rand
+
+	dir       string
+	signature string
+}
+
+// NewResponses returns an empty set of responses
+func NewResponses() *Responses {
+	return &Responses{
+		builtin: make(map[string][]Response),
+		files:   make(map[string][]Response),
+		sources: make(map[string][]string),
+		last:    make(map[string]string),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// responsesWatchInterval is how often pack files are checked for changes
+const responsesWatchInterval = 30 * time.Second
+
+var responses = NewResponses()
+
+// ResponseStore returns the responses shared by the bot and the plugins
+func ResponseStore() *Responses {
+	return responses
+}
+
+// RegisterStringList takes an array of strings and stores them in a category
+func RegisterStringList(category string, list []string) {
+	entries := make([]Response, 0, len(list))
+	for _, s := range list {
+		entries = append(entries, Response{Text: s})
+	}
+	responses.Register(category, entries)
+}
+
+// RandomString returns a random string from the array stored at category
+func RandomString(category string) string {
+	return responses.Pick(category, nil)
+}
+
+// Register sets the builtin responses of a category
+func (r *Responses) Register(category string, entries []Response) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.builtin[category] = entries
+}
+
+/*
+Pick returns a weighted random response of a category, rendered with
+`ctx` when it is a template. The previous pick is skipped, so the same
+response never comes twice in a row when there are others. It returns an
+empty string for unknown categories.
+*/
+func (r *Responses) Pick(category string, ctx *ResponseContext) string {
+	r.lock.Lock()
+	entries, ok := r.files[category]
+	if !ok {
+		entries = r.builtin[category]
+	}
+
+	if len(entries) == 0 {
+		r.lock.Unlock()
+		return ""
+	}
+
+	candidates := entries
+	if len(entries) > 1 {
+		candidates = make([]Response, 0, len(entries))
+		for _, e := range entries {
+			if e.Text != r.last[category] {
+				candidates = append(candidates, e)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = entries
+		}
+	}
+
+	total := 0.0
+	for _, e := range candidates {
+		total += e.weight()
+	}
+
+	picked := candidates[len(candidates)-1]
+	n := r.rand.Float64() * total
+	for _, e := range candidates {
+		n -= e.weight()
+		if n < 0 {
+			picked = e
+			break
+		}
+	}
+
+	r.last[category] = picked.Text
+	r.lock.Unlock()
+
+	return renderResponse(picked.Text, ctx)
+}
+
+func renderResponse(text string, ctx *ResponseContext) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	if ctx == nil {
+		ctx = &ResponseContext{}
+	}
+
+	tmpl, err := template.New("response").Parse(text)
+	if err != nil {
+		return text
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return text
+	}
+
+	return buf.String()
+}
+
+/*
+Load reads the response packs of a directory, replacing the ones read
+before. Each `.yaml`, `.yml` or `.json` file maps categories to lists of
+responses; files adding to the same category are merged. On error, the
+packs read before are kept, and the directory is still watched.
+*/
+func (r *Responses) Load(dir string) error {
+	files, signature, err := packFiles(dir)
+
+	r.lock.Lock()
+	r.dir = dir
+	r.signature = signature
+	r.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string][]Response)
+	sources := make(map[string][]string)
+
+	for _, name := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		pack := make(map[string][]Response)
+		if filepath.Ext(name) == ".json" {
+			err = json.Unmarshal(content, &pack)
+		} else {
+			err = yaml.Unmarshal(content, &pack)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+
+		for category, entries := range pack {
+			loaded[category] = append(loaded[category], entries...)
+			sources[category] = append(sources[category], name)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.files = loaded
+	r.sources = sources
+
+	return nil
+}
+
+// Reload reads again the directory given to Load
+func (r *Responses) Reload() error {
+	r.lock.Lock()
+	dir := r.dir
+	r.lock.Unlock()
+
+	if dir == "" {
+		return fmt.Errorf("no responses_path configured")
+	}
+
+	return r.Load(dir)
+}
+
+// Changed tells whether the pack files changed since they were loaded
+func (r *Responses) Changed() bool {
+	r.lock.Lock()
+	dir, signature := r.dir, r.signature
+	r.lock.Unlock()
+
+	if dir == "" {
+		return false
+	}
+
+	_, current, err := packFiles(dir)
+	return err == nil && current != signature
+}
+
+// Watch reloads the pack files when they change, until `stop` is closed
+func (r *Responses) Watch(interval time.Duration, stop <-chan struct{}, onReload func(error)) {
+	for {
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			return
+		}
+
+		if r.Changed() {
+			err := r.Reload()
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}
+}
+
+// Packs describes the known categories, sorted by name
+func (r *Responses) Packs() []ResponsePack {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var out []ResponsePack
+	for category, entries := range r.files {
+		out = append(out, ResponsePack{Category: category, Count: len(entries), Source: strings.Join(r.sources[category], ", ")})
+	}
+	for category, entries := range r.builtin {
+		if _, ok := r.files[category]; !ok {
+			out = append(out, ResponsePack{Category: category, Count: len(entries), Source: "builtin"})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Category < out[j].Category })
+
+	return out
+}
+
+// packFiles lists the pack files of a directory, along with a signature
+// of their names, sizes and modification times
+func packFiles(dir string) ([]string, string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var files []string
+	var signature []string
+	for _, f := range infos {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		files = append(files, f.Name())
+		signature = append(signature, fmt.Sprintf("%s:%d:%d", f.Name(), f.Size(), f.ModTime().UnixNano()))
+	}
+
+	return files, strings.Join(signature, "|"), nil
+}
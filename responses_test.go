@@ -0,0 +1,106 @@
+package bawt
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponses_Pick(t *testing.T) {
+	r := NewResponses()
+	assert.Equal(t, "", r.Pick("unknown", nil))
+
+	r.Register("single", []Response{{Text: "only"}})
+	assert.Equal(t, "only", r.Pick("single", nil))
+	assert.Equal(t, "only", r.Pick("single", nil))
+
+	r.Register("pair", []Response{{Text: "a"}, {Text: "b", Weight: 10}})
+	last := r.Pick("pair", nil)
+	for i := 0; i < 20; i++ {
+		next := r.Pick("pair", nil)
+		assert.NotEqual(t, last, next, "no immediate repeats")
+		last = next
+	}
+}
+
+func TestResponses_Weights(t *testing.T) {
+	r := NewResponses()
+	r.Register("weighted", []Response{{Text: "rare", Weight: 1}, {Text: "common", Weight: 50}, {Text: "other", Weight: 50}})
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[r.Pick("weighted", nil)]++
+	}
+	assert.True(t, counts["rare"] < counts["common"])
+	assert.True(t, counts["rare"] < counts["other"])
+}
+
+func TestResponses_Template(t *testing.T) {
+	r := NewResponses()
+	r.Register("greet", []Response{{Text: "Hi {{.Mention}} in #{{.Channel}}, you said {{index .Match 1}}"}})
+
+	ctx := &ResponseContext{Mention: "<@U1>", Channel: "general", Match: []string{"!say hello", "hello"}}
+	assert.Equal(t, "Hi <@U1> in #general, you said hello", r.Pick("greet", ctx))
+
+	// Broken templates are returned as is
+	r.Register("broken", []Response{{Text: "Hi {{.Nope"}})
+	assert.Equal(t, "Hi {{.Nope", r.Pick("broken", nil))
+}
+
+func TestResponses_Load(t *testing.T) {
+	dir := t.TempDir()
+
+	r := NewResponses()
+	r.Register("jokes", []Response{{Text: "builtin"}})
+	r.Register("dishes", []Response{{Text: "builtin dish"}})
+
+	ioutil.WriteFile(filepath.Join(dir, "jokes.yaml"), []byte("jokes:\n  - first\n  - text: second\n    weight: 2\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "more.json"), []byte(`{"jokes": ["third"], "quotes": [{"text": "quote"}]}`), 0644)
+
+	assert.NoError(t, r.Load(dir))
+	assert.False(t, r.Changed())
+
+	packs := r.Packs()
+	assert.Equal(t, []ResponsePack{
+		{Category: "dishes", Count: 1, Source: "builtin"},
+		{Category: "jokes", Count: 3, Source: "jokes.yaml, more.json"},
+		{Category: "quotes", Count: 1, Source: "more.json"},
+	}, packs)
+
+	assert.NotEqual(t, "builtin", r.Pick("jokes", nil))
+
+	// Hot reload
+	time.Sleep(10 * time.Millisecond)
+	ioutil.WriteFile(filepath.Join(dir, "more.json"), []byte(`{"jokes": ["third", "fourth"]}`), 0644)
+	assert.True(t, r.Changed())
+	assert.NoError(t, r.Reload())
+	assert.Len(t, r.Packs(), 2)
+
+	// Broken files keep the previous packs
+	ioutil.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("jokes: [unclosed"), 0644)
+	assert.Error(t, r.Reload())
+	assert.Len(t, r.Packs(), 2)
+	assert.False(t, r.Changed())
+}
+
+func TestResponses_Concurrency(t *testing.T) {
+	r := NewResponses()
+	r.Register("c", []Response{{Text: "a"}, {Text: "b"}, {Text: "c"}})
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Pick("c", nil)
+				r.Packs()
+			}
+		}()
+	}
+	wg.Wait()
+}
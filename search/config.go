@@ -0,0 +1,10 @@
+package search
+
+// Config is the `search` section of the configuration
+type Config struct {
+	Channels      []string `json:"channels" mapstructure:"channels"`               // Names of the channels indexed from the start, unless disabled with `!search disable`, on top of those enabled with `!search enable`.
+	RetentionDays int      `json:"retention_days" mapstructure:"retention_days"`   // Messages older than this are dropped from the index. Defaults to 90, -1 keeps them forever.
+	MaxPerChannel int      `json:"max_per_channel" mapstructure:"max_per_channel"` // Maximum number of messages kept per channel, 0 for no limit.
+}
+
+const defaultRetentionDays = 90
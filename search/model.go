@@ -0,0 +1,62 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Entry is an indexed message
+type Entry struct {
+	Channel string    `json:"channel"`
+	User    string    `json:"user"`
+	TS      string    `json:"ts"`
+	Time    time.Time `json:"time"`
+	Text    string    `json:"text"`
+	Edited  bool      `json:"edited,omitempty"`
+}
+
+// key sorts the entries by channel, then chronologically
+func (e *Entry) key() []byte {
+	return entryKey(e.Channel, e.TS)
+}
+
+func entryKey(channel, ts string) []byte {
+	return []byte(channel + "/" + ts)
+}
+
+// timeFromTS converts a Slack message timestamp, like
+// "1503435956.000247", to a time
+func timeFromTS(ts string) time.Time {
+	parts := strings.SplitN(ts, ".", 2)
+	sec, _ := strconv.ParseInt(parts[0], 10, 64)
+
+	var nsec int64
+	if len(parts) == 2 {
+		usec, _ := strconv.ParseInt(parts[1], 10, 64)
+		nsec = usec * int64(time.Microsecond)
+	}
+
+	return time.Unix(sec, nsec)
+}
+
+// tokenize splits a text into unique, lower-cased terms of two letters
+// or more
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	var out []string
+	for _, f := range fields {
+		if len([]rune(f)) < 2 || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+
+	return out
+}
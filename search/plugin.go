@@ -0,0 +1,89 @@
+// Package search is a plugin for bawt that indexes the messages of
+// opted-in channels and makes them searchable in chat and on the web
+package search
+
+import (
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Plugin indexes messages and answers `!search`
+type Plugin struct {
+	bot    *bawt.Bot
+	config Config
+	store  Store
+}
+
+func init() {
+	bawt.RegisterPlugin(&Plugin{})
+}
+
+// InitPlugin creates the index buckets and starts listening
+func (p *Plugin) InitPlugin(bot *bawt.Bot) {
+	p.bot = bot
+
+	err := bot.DB.Update(createBuckets)
+	if err != nil {
+		bot.Logging.Logger.Fatalln("Couldn't create the `search` bucket")
+	}
+
+	var conf struct {
+		Search Config
+	}
+	bot.LoadConfig(&conf)
+	p.config = conf.Search
+
+	if p.config.RetentionDays == 0 {
+		p.config.RetentionDays = defaultRetentionDays
+	}
+
+	p.store = &boltStore{db: bot.DB}
+
+	p.listenIndex()
+	p.listenSearch()
+
+	go p.pruneLoop()
+}
+
+// indexed tells whether messages from a channel go to the index. The
+// chat toggles override the channels of the config.
+func (p *Plugin) indexed(channelID string) bool {
+	if enabled, set := p.store.Enabled(channelID); set {
+		return enabled
+	}
+
+	channel, ok := p.bot.Channels.Get(channelID)
+	if !ok {
+		return false
+	}
+
+	for _, name := range p.config.Channels {
+		if channel.Name == name || "#"+channel.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pruneLoop enforces the retention limits every hour
+func (p *Plugin) pruneLoop() {
+	log := p.bot.Logging.Logger
+
+	for {
+		var before time.Time
+		if p.config.RetentionDays > 0 {
+			before = time.Now().AddDate(0, 0, -p.config.RetentionDays)
+		}
+
+		removed, err := p.store.Prune(before, p.config.MaxPerChannel)
+		if err != nil {
+			log.WithError(err).Error("Error pruning the search index")
+		} else if removed > 0 {
+			log.Infof("Pruned %d messages from the search index", removed)
+		}
+
+		time.Sleep(time.Hour)
+	}
+}
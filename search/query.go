@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Query is a parsed `!search` request. Channel and User hold either a
+// Slack ID or a name, as typed.
+type Query struct {
+	Terms   []string
+	Channel string
+	User    string
+	After   time.Time
+	Before  time.Time
+}
+
+// Empty tells whether the query has neither terms nor filters
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0 && q.Channel == "" && q.User == "" && q.After.IsZero() && q.Before.IsZero()
+}
+
+// ParseQuery understands free terms along with the `in:#channel`,
+// `from:@user`, `after:date` and `before:date` filters. Dates are
+// `2006-01-02`, a number of days (`7d`) or a duration (`12h`) ago.
+func ParseQuery(input string, now time.Time) (Query, error) {
+	var q Query
+	var terms []string
+
+	for _, field := range strings.Fields(input) {
+		idx := strings.Index(field, ":")
+		if idx < 0 {
+			terms = append(terms, field)
+			continue
+		}
+
+		value := field[idx+1:]
+		switch strings.ToLower(field[:idx]) {
+		case "in":
+			q.Channel = cleanChannel(value)
+		case "from":
+			q.User = bawt.CleanUser(value)
+		case "after":
+			t, err := bawt.ParseSince(value, now)
+			if err != nil {
+				return q, err
+			}
+			q.After = t
+		case "before":
+			t, err := bawt.ParseSince(value, now)
+			if err != nil {
+				return q, err
+			}
+			q.Before = t
+		default:
+			terms = append(terms, field)
+		}
+	}
+
+	q.Terms = tokenize(strings.Join(terms, " "))
+
+	return q, nil
+}
+
+// cleanChannel turns `<#C1234|general>` into `C1234` and `#general`
+// into `general`
+func cleanChannel(value string) string {
+	if strings.HasPrefix(value, "<#") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">")
+		return strings.SplitN(value, "|", 2)[0]
+	}
+	return strings.TrimPrefix(value, "#")
+}
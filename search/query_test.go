@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  Query
+	}{
+		{"deploy failed", Query{Terms: []string{"deploy", "failed"}}},
+		{"Deploy in:#ops", Query{Terms: []string{"deploy"}, Channel: "ops"}},
+		{"in:<#C123|ops> from:<@U1>", Query{Channel: "C123", User: "U1"}},
+		{"from:@bob after:2019-08-01", Query{User: "bob", After: time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)}},
+		{"outage before:7d", Query{Terms: []string{"outage"}, Before: now.AddDate(0, 0, -7)}},
+		{"http://example.com a", Query{Terms: []string{"http", "example", "com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := ParseQuery(tt.input, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, q)
+		})
+	}
+
+	_, err := ParseQuery("after:yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestTimeFromTS(t *testing.T) {
+	ts := timeFromTS("1503435956.000247")
+	assert.Equal(t, int64(1503435956), ts.Unix())
+	assert.Equal(t, 247*time.Microsecond, time.Duration(ts.Nanosecond()))
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// maxResults caps the number of messages replied in chat
+const maxResults = 10
+
+func (p *Plugin) listenIndex() {
+	p.bot.Listen(&bawt.Listener{
+		EventHandlerFunc: p.handleEvent,
+		Name:             "Search",
+		Description:      "Indexes and searches the messages of opted-in channels",
+	})
+}
+
+func (p *Plugin) listenSearch() {
+	p.bot.Listen(&bawt.Listener{
+		Matches:            regexp.MustCompile(`^!search\b.*`),
+		MessageHandlerFunc: p.handleSearch,
+		Name:               "Search",
+		Description:        "Indexes and searches the messages of opted-in channels",
+		Commands: []bawt.Command{
+			{
+				Usage:    "!search <terms> [in:#channel] [from:@user] [after:date] [before:date]",
+				HelpText: "Searches the indexed messages; dates are YYYY-MM-DD or like 7d",
+			},
+			{
+				Usage:    "!search status",
+				HelpText: "Tells whether this channel is indexed",
+			},
+			{
+				Usage:    "!search enable",
+				HelpText: "Starts indexing this channel (GlobalAdmins only)",
+			},
+			{
+				Usage:    "!search disable",
+				HelpText: "Stops indexing this channel and forgets its messages (GlobalAdmins only)",
+			},
+		},
+	})
+}
+
+// handleEvent keeps the index in sync with new, edited and deleted
+// messages
+func (p *Plugin) handleEvent(listen *bawt.Listener, event interface{}) {
+	log := p.bot.Logging.Logger
+
+	msg, ok := event.(*bawt.Message)
+	if !ok || msg.FromMe || !p.indexed(msg.Channel) {
+		return
+	}
+
+	var err error
+	switch msg.Msg.SubType {
+	case "message_deleted":
+		err = p.store.Remove(msg.Channel, msg.DeletedTimestamp)
+
+	case "message_changed":
+		if msg.SubMessage == nil {
+			return
+		}
+		err = p.store.Index(Entry{
+			Channel: msg.Channel,
+			User:    msg.SubMessage.User,
+			TS:      msg.SubMessage.Timestamp,
+			Time:    timeFromTS(msg.SubMessage.Timestamp),
+			Text:    msg.Text,
+			Edited:  true,
+		})
+
+	case "", "me_message", "thread_broadcast", "file_share":
+		if msg.User == "" || strings.HasPrefix(msg.Text, "!search") {
+			return
+		}
+		err = p.store.Index(Entry{
+			Channel: msg.Channel,
+			User:    msg.User,
+			TS:      msg.Timestamp,
+			Time:    timeFromTS(msg.Timestamp),
+			Text:    msg.Text,
+		})
+	}
+
+	if err != nil {
+		log.WithError(err).WithField("channel", msg.Channel).Error("Error updating the search index")
+	}
+}
+
+func (p *Plugin) handleSearch(listen *bawt.Listener, msg *bawt.Message) {
+	input := strings.TrimSpace(strings.TrimPrefix(msg.Match[0], "!search"))
+
+	switch input {
+	case "status":
+		p.replyStatus(msg)
+		return
+	case "enable", "disable":
+		p.toggle(msg, input == "enable")
+		return
+	case "", "help":
+		msg.Reply("Search with `!search <terms> [in:#channel] [from:@user] [after:YYYY-MM-DD] [before:YYYY-MM-DD]`")
+		return
+	}
+
+	q, err := p.resolve(input)
+	if err != nil {
+		msg.ReplyMention(err.Error())
+		return
+	}
+
+	// Messages from private channels and IMs only show up where they
+	// were said
+	visible := func(channelID string) bool {
+		if channelID == msg.Channel {
+			return true
+		}
+		channel, ok := p.bot.Channels.Get(channelID)
+		return ok && channel.IsChannel
+	}
+
+	entries, total, err := p.store.Search(q, visible, maxResults)
+	if err != nil {
+		p.bot.Logging.Logger.WithError(err).Error("Error searching the index")
+		msg.ReplyMention("I couldn't search the index.")
+		return
+	}
+
+	if total == 0 {
+		msg.ReplyMention("No messages found.")
+		return
+	}
+
+	lines := []string{fmt.Sprintf("%d message(s) found:", total)}
+	if total > len(entries) {
+		lines[0] = fmt.Sprintf("%d message(s) found, here are the %d most recent:", total, len(entries))
+	}
+	for _, e := range entries {
+		lines = append(lines, p.formatEntry(e))
+	}
+
+	msg.Reply(strings.Join(lines, "\n"))
+}
+
+// resolve parses a query and turns its channel and user names into IDs
+func (p *Plugin) resolve(input string) (Query, error) {
+	q, err := ParseQuery(input, time.Now())
+	if err != nil {
+		return q, err
+	}
+
+	if q.Empty() {
+		return q, fmt.Errorf("what should I search for?")
+	}
+
+	if q.Channel != "" {
+		if _, ok := p.bot.Channels.Get(q.Channel); !ok {
+			channel := p.bot.GetChannelByName(q.Channel)
+			if channel == nil {
+				return q, fmt.Errorf("I don't know the channel #%s", q.Channel)
+			}
+			q.Channel = channel.ID
+		}
+	}
+
+	if q.User != "" {
+		user := p.bot.GetUser(q.User)
+		if user == nil {
+			return q, fmt.Errorf("I don't know the user @%s", q.User)
+		}
+		q.User = user.ID
+	}
+
+	return q, nil
+}
+
+func (p *Plugin) formatEntry(e Entry) string {
+	text := strings.Replace(e.Text, "\n", " ", -1)
+	if len([]rune(text)) > 200 {
+		text = string([]rune(text)[:200]) + "…"
+	}
+
+	when := e.Time.Format("2006-01-02 15:04")
+	if link := p.permalink(e); link != "" {
+		when = fmt.Sprintf("<%s|%s>", link, when)
+	}
+
+	return fmt.Sprintf("> %s\n— <@%s> in <#%s>, %s", text, e.User, e.Channel, when)
+}
+
+func (p *Plugin) permalink(e Entry) string {
+	if p.bot.Config.TeamDomain == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s.slack.com/archives/%s/p%s", p.bot.Config.TeamDomain, e.Channel, strings.Replace(e.TS, ".", "", 1))
+}
+
+func (p *Plugin) replyStatus(msg *bawt.Message) {
+	if !p.indexed(msg.Channel) {
+		msg.Reply("This channel isn't indexed. A GlobalAdmin can turn it on with `!search enable`.")
+		return
+	}
+
+	retention := "forever"
+	if p.config.RetentionDays > 0 {
+		retention = fmt.Sprintf("for %d days", p.config.RetentionDays)
+	}
+	msg.Reply("This channel is indexed, messages are kept %s.", retention)
+}
+
+// toggle opts the current channel in or out, for GlobalAdmins
+func (p *Plugin) toggle(msg *bawt.Message, enable bool) {
+	action := "search:disable"
+	if enable {
+		action = "search:enable"
+	}
+
+	entry := bawt.AuditEntry{
+		Actor:  msg.FromUser.ID,
+		Action: action,
+		Target: msg.Channel,
+	}
+
+	admins := bawt.InternalGroup{Name: "GlobalAdmins"}
+	admin, err := admins.IsUserMember(p.bot.DB, msg.FromUser.ID)
+	if err != nil || !admin {
+		entry.Result = bawt.AuditDenied
+		p.bot.Audit(entry)
+		msg.ReplyMention("only GlobalAdmins can change what is indexed.")
+		return
+	}
+
+	err = p.store.SetEnabled(msg.Channel, enable)
+	if err == nil && !enable {
+		_, err = p.store.Purge(msg.Channel)
+	}
+	if err != nil {
+		entry.Result = bawt.AuditFailure
+		entry.Error = err.Error()
+		p.bot.Audit(entry)
+		msg.ReplyMention("I couldn't update the index: %s", err)
+		return
+	}
+
+	p.bot.Audit(entry)
+
+	if enable {
+		msg.Reply("From now on, messages in this channel are indexed for `!search`.")
+	} else {
+		msg.Reply("Messages in this channel are no longer indexed, and were removed from the index.")
+	}
+}
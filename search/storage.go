@@ -0,0 +1,320 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Store is the full-text index of the messages
+type Store interface {
+	Index(e Entry) error
+	Remove(channel, ts string) error
+	Search(q Query, visible func(channel string) bool, limit int) ([]Entry, int, error)
+	Prune(before time.Time, maxPerChannel int) (int, error)
+	Purge(channel string) (int, error)
+	SetEnabled(channel string, enabled bool) error
+	Enabled(channel string) (enabled, set bool)
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+var (
+	bucketName     = []byte("search")
+	messagesBucket = []byte("messages") // [channel/ts] = Entry
+	termsBucket    = []byte("terms")    // [term\x00channel/ts] = nil
+	channelsBucket = []byte("channels") // [channel ID] = opt-in or opt-out
+)
+
+func createBuckets(tx *bolt.Tx) error {
+	b, err := tx.CreateBucketIfNotExists(bucketName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range [][]byte{messagesBucket, termsBucket, channelsBucket} {
+		if _, err := b.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func termKey(term string, key []byte) []byte {
+	return append([]byte(term+"\x00"), key...)
+}
+
+// Index adds or replaces a message
+func (s *boltStore) Index(e Entry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		key := e.key()
+
+		if err := unindex(b, key); err != nil {
+			return err
+		}
+
+		cnt, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Bucket(messagesBucket).Put(key, cnt); err != nil {
+			return err
+		}
+
+		terms := b.Bucket(termsBucket)
+		for _, term := range tokenize(e.Text) {
+			if err := terms.Put(termKey(term, key), nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Remove drops a message from the index
+func (s *boltStore) Remove(channel, ts string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return unindex(tx.Bucket(bucketName), entryKey(channel, ts))
+	})
+}
+
+// unindex removes a message and its terms, if it exists
+func unindex(b *bolt.Bucket, key []byte) error {
+	messages := b.Bucket(messagesBucket)
+
+	v := messages.Get(key)
+	if v == nil {
+		return nil
+	}
+
+	var old Entry
+	if err := json.Unmarshal(v, &old); err != nil {
+		return err
+	}
+
+	terms := b.Bucket(termsBucket)
+	for _, term := range tokenize(old.Text) {
+		if err := terms.Delete(termKey(term, key)); err != nil {
+			return err
+		}
+	}
+
+	return messages.Delete(key)
+}
+
+/*
+Search returns the most recent messages matching all the terms and
+filters of the query, in channels for which `visible` is true, along with
+the total number of matches. Channel and User must be IDs at this point.
+*/
+func (s *boltStore) Search(q Query, visible func(channel string) bool, limit int) (out []Entry, total int, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		messages := b.Bucket(messagesBucket)
+
+		var keys [][]byte
+		if len(q.Terms) > 0 {
+			keys = matchTerms(b.Bucket(termsBucket), q.Terms)
+		} else {
+			prefix := []byte{}
+			if q.Channel != "" {
+				prefix = []byte(q.Channel + "/")
+			}
+			c := messages.Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				keys = append(keys, append([]byte{}, k...))
+			}
+		}
+
+		for _, key := range keys {
+			var e Entry
+			if err := json.Unmarshal(messages.Get(key), &e); err != nil {
+				return err
+			}
+
+			if !q.matches(e) || (visible != nil && !visible(e.Channel)) {
+				continue
+			}
+			out = append(out, e)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+
+	total = len(out)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+
+	return out, total, nil
+}
+
+// matchTerms returns the keys of the messages containing all the terms
+func matchTerms(terms *bolt.Bucket, query []string) [][]byte {
+	var keys [][]byte
+
+	for i, term := range query {
+		prefix := []byte(term + "\x00")
+		found := make(map[string]bool)
+
+		c := terms.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			found[string(k[len(prefix):])] = true
+		}
+
+		if i == 0 {
+			for key := range found {
+				keys = append(keys, []byte(key))
+			}
+			continue
+		}
+
+		kept := keys[:0]
+		for _, key := range keys {
+			if found[string(key)] {
+				kept = append(kept, key)
+			}
+		}
+		keys = kept
+	}
+
+	return keys
+}
+
+func (q Query) matches(e Entry) bool {
+	if q.Channel != "" && e.Channel != q.Channel {
+		return false
+	}
+	if q.User != "" && e.User != q.User {
+		return false
+	}
+	if !q.After.IsZero() && e.Time.Before(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && !e.Time.Before(q.Before) {
+		return false
+	}
+	return true
+}
+
+/*
+Prune drops the messages older than `before`, when set, and the oldest
+messages of the channels holding more than `maxPerChannel`, when positive.
+It returns the number of messages removed.
+*/
+func (s *boltStore) Prune(before time.Time, maxPerChannel int) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+
+		// Keys are sorted by channel, then chronologically
+		var drop [][]byte
+		perChannel := make(map[string][][]byte)
+
+		err := b.Bucket(messagesBucket).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+
+			key := append([]byte{}, k...)
+			if !before.IsZero() && e.Time.Before(before) {
+				drop = append(drop, key)
+				return nil
+			}
+			perChannel[e.Channel] = append(perChannel[e.Channel], key)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if maxPerChannel > 0 {
+			for _, keys := range perChannel {
+				if len(keys) > maxPerChannel {
+					drop = append(drop, keys[:len(keys)-maxPerChannel]...)
+				}
+			}
+		}
+
+		for _, key := range drop {
+			if err := unindex(b, key); err != nil {
+				return err
+			}
+		}
+		removed = len(drop)
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// Purge drops all the messages of a channel
+func (s *boltStore) Purge(channel string) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		prefix := []byte(channel + "/")
+
+		var drop [][]byte
+		c := b.Bucket(messagesBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			drop = append(drop, append([]byte{}, k...))
+		}
+
+		for _, key := range drop {
+			if err := unindex(b, key); err != nil {
+				return err
+			}
+		}
+		removed = len(drop)
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// optOut prefixes the time a channel opted out, opt-ins only hold the time
+const optOut = "off "
+
+// SetEnabled opts a channel in or out of indexing
+func (s *boltStore) SetEnabled(channel string, enabled bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		value := time.Now().Format(time.RFC3339)
+		if !enabled {
+			value = optOut + value
+		}
+		return tx.Bucket(bucketName).Bucket(channelsBucket).Put([]byte(channel), []byte(value))
+	})
+}
+
+// Enabled tells whether a channel opted in or out with `!search enable`
+// or `!search disable`, and if it did at all
+func (s *boltStore) Enabled(channel string) (enabled, set bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(bucketName).Bucket(channelsBucket).Get([]byte(channel))
+		set = value != nil
+		enabled = set && !bytes.HasPrefix(value, []byte(optOut))
+		return nil
+	})
+	return
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) *boltStore {
+	return &boltStore{db: bawttest.NewDB(t, createBuckets)}
+}
+
+func TestBoltStore(t *testing.T) {
+	s := newTestStore(t)
+
+	now := time.Now()
+	entries := []Entry{
+		{Channel: "C1", User: "U1", TS: "1.1", Time: now.Add(-3 * time.Hour), Text: "The deploy failed again"},
+		{Channel: "C1", User: "U2", TS: "2.1", Time: now.Add(-2 * time.Hour), Text: "deploy is green"},
+		{Channel: "C2", User: "U1", TS: "3.1", Time: now.Add(-1 * time.Hour), Text: "secret deploy plans"},
+	}
+	for _, e := range entries {
+		assert.NoError(t, s.Index(e))
+	}
+
+	res, total, err := s.Search(Query{Terms: []string{"deploy"}}, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "3.1", res[0].TS, "newest first")
+
+	res, _, err = s.Search(Query{Terms: []string{"deploy", "failed"}}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+
+	res, _, err = s.Search(Query{Terms: []string{"deploy"}}, func(c string) bool { return c == "C1" }, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	res, _, err = s.Search(Query{User: "U1", Channel: "C1"}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+
+	// Edits replace the indexed terms
+	edit := entries[0]
+	edit.Text = "The deploy worked"
+	edit.Edited = true
+	assert.NoError(t, s.Index(edit))
+
+	res, _, err = s.Search(Query{Terms: []string{"failed"}}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	assert.NoError(t, s.Remove("C1", "2.1"))
+	res, _, err = s.Search(Query{Terms: []string{"green"}}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	// Retention
+	removed, err := s.Prune(now.Add(-150*time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	removed, err = s.Purge("C2")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	res, total, err = s.Search(Query{Terms: []string{"deploy"}}, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestBoltStore_PruneMaxPerChannel(t *testing.T) {
+	s := newTestStore(t)
+
+	now := time.Now()
+	for i, ts := range []string{"1.1", "2.1", "3.1"} {
+		assert.NoError(t, s.Index(Entry{Channel: "C1", TS: ts, Time: now.Add(time.Duration(i) * time.Minute), Text: "hello"}))
+	}
+
+	removed, err := s.Prune(time.Time{}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	res, _, err := s.Search(Query{Terms: []string{"hello"}}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "2.1", res[1].TS)
+}
+
+func TestBoltStore_Enabled(t *testing.T) {
+	s := newTestStore(t)
+
+	enabled, set := s.Enabled("C1")
+	assert.False(t, enabled)
+	assert.False(t, set)
+
+	assert.NoError(t, s.SetEnabled("C1", true))
+	enabled, set = s.Enabled("C1")
+	assert.True(t, enabled)
+	assert.True(t, set)
+
+	assert.NoError(t, s.SetEnabled("C1", false))
+	enabled, set = s.Enabled("C1")
+	assert.False(t, enabled)
+	assert.True(t, set)
+}
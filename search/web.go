@@ -0,0 +1,103 @@
+package search
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gorilla/mux"
+)
+
+// maxWebResults caps the number of messages shown on the search page
+const maxWebResults = 100
+
+// InitWebPlugin serves the search page
+func (p *Plugin) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, pubRouter *mux.Router) {
+	privRouter.HandleFunc("/search", p.handleWebSearch)
+}
+
+type webResult struct {
+	Entry
+	UserName    string
+	ChannelName string
+	Permalink   string
+}
+
+// handleWebSearch renders the search form and its results. Only public
+// channels are searched from the web.
+func (p *Plugin) handleWebSearch(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Query   string
+		Error   string
+		Total   int
+		Results []webResult
+	}{
+		Query: r.FormValue("q"),
+	}
+
+	if data.Query != "" {
+		q, err := p.resolve(data.Query)
+		if err != nil {
+			data.Error = err.Error()
+		} else {
+			visible := func(channelID string) bool {
+				channel, ok := p.bot.Channels.Get(channelID)
+				return ok && channel.IsChannel
+			}
+
+			entries, total, err := p.store.Search(q, visible, maxWebResults)
+			if err != nil {
+				p.bot.Logging.Logger.WithError(err).Error("Error searching the index")
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			data.Total = total
+			for _, e := range entries {
+				res := webResult{Entry: e, UserName: e.User, ChannelName: e.Channel, Permalink: p.permalink(e)}
+				if user, ok := p.bot.Users.Get(e.User); ok {
+					res.UserName = user.Name
+				}
+				if channel, ok := p.bot.Channels.Get(e.Channel); ok {
+					res.ChannelName = channel.Name
+				}
+				data.Results = append(data.Results, res)
+			}
+		}
+	}
+
+	if err := searchTemplate.Execute(w, data); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+var searchTemplate = template.Must(template.New("search").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Search</title>
+</head>
+<body>
+  <h1>Search</h1>
+  <form method="GET">
+    <input name="q" size="60" value="{{.Query}}" placeholder="terms in:#channel from:@user after:2019-01-01">
+    <input type="submit" value="Search">
+  </form>
+  {{if .Error}}<p>{{.Error}}</p>{{end}}
+  {{if .Query}}{{if not .Error}}
+  <p>{{.Total}} message(s) found{{if gt .Total (len .Results)}}, showing the {{len .Results}} most recent{{end}}.</p>
+  <table>
+    <tr><th>Time</th><th>Channel</th><th>User</th><th>Message</th></tr>
+    {{range .Results}}
+    <tr>
+      <td>{{if .Permalink}}<a href="{{.Permalink}}">{{.Time.Format "2006-01-02 15:04"}}</a>{{else}}{{.Time.Format "2006-01-02 15:04"}}{{end}}</td>
+      <td>#{{.ChannelName}}</td>
+      <td>@{{.UserName}}</td>
+      <td>{{.Text}}{{if .Edited}} <em>(edited)</em>{{end}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{end}}{{end}}
+</body>
+</html>
+`))
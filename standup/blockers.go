@@ -0,0 +1,528 @@
+package standup
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Blocker is what blocks a user, given under `!blocking` in chat or as the
+// answer to the `blocking` question, tracked until resolved
+type Blocker struct {
+	ID    int
+	Owner string
+	Text  string
+	// Team is the team of the standup it was given in, if any: the team of
+	// the session, or the one of the channel for the chat standups
+	Team string `json:",omitempty"`
+	// Date is the day of the standup it was given in
+	Date      string
+	CreatedAt time.Time
+	// Source is the message or the session it was given in, to follow the
+	// edits
+	Source string `json:",omitempty"`
+	// Messages are the messages about it as `channel:ts`, reacting or
+	// replying to which resolves it
+	Messages []string `json:",omitempty"`
+	// AskedOn is the last day the owner was asked if still blocked
+	AskedOn     string `json:",omitempty"`
+	EscalatedAt time.Time
+	ResolvedAt  time.Time
+	ResolvedBy  string `json:",omitempty"`
+	Note        string `json:",omitempty"`
+}
+
+// Open tells whether the blocker is still there
+func (b *Blocker) Open() bool {
+	return b.ResolvedAt.IsZero()
+}
+
+func (b *Blocker) addMessage(ref string) {
+	if ref == "" {
+		return
+	}
+	for _, m := range b.Messages {
+		if m == ref {
+			return
+		}
+	}
+	b.Messages = append(b.Messages, ref)
+}
+
+// noBlocker are the usual answers of those who aren't blocked
+var noBlocker = map[string]bool{"": true, "no": true, "none": true, "nothing": true, "nope": true, "n/a": true, "-": true}
+
+// isBlocker tells whether the answer to `blocking` is a blocker
+func isBlocker(text string) bool {
+	return !noBlocker[strings.ToLower(strings.Trim(text, " .!"))]
+}
+
+// resolvedReply is a reply resolving the blocker of the thread
+var resolvedReply = regexp.MustCompile(`(?i)^(resolved|fixed|solved|sorted|unblocked|done)\b`)
+
+// messageRef is how the messages about a blocker are kept
+func messageRef(channel, ts string) string {
+	return channel + ":" + ts
+}
+
+// sessionRef is the source of the blockers answered in private
+func sessionRef(s *Session) string {
+	return "session:" + s.Team + ":" + s.Date + ":" + s.User
+}
+
+// blockers returns the blockers matching keep
+func (standup *Standup) blockers(keep func(*Blocker) bool) ([]*Blocker, error) {
+	all, err := standup.store.Blockers()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Blocker
+	for _, b := range all {
+		if keep(b) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// trackBlocker follows the answer of a user to `blocking`: a new blocker,
+// unless the same is open already, or the change of the blocker given in
+// source when it's edited. The caller holds the lock.
+func (standup *Standup) trackBlocker(owner, team, text, date, source, ref string, now time.Time) (*Blocker, error) {
+	all, err := standup.store.Blockers()
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	for _, b := range all {
+		if source == "" || b.Source != source || !b.Open() {
+			continue
+		}
+		// Edited away
+		if !isBlocker(text) {
+			return b, standup.resolveBlocker(b, owner, "", now)
+		}
+		b.Text = text
+		return b, standup.store.PutBlocker(b)
+	}
+
+	if !isBlocker(text) {
+		return nil, nil
+	}
+
+	for _, b := range all {
+		if b.Open() && b.Owner == owner && strings.EqualFold(b.Text, text) {
+			b.addMessage(ref)
+			return b, standup.store.PutBlocker(b)
+		}
+	}
+
+	b := &Blocker{Owner: owner, Team: team, Text: text, Date: date, CreatedAt: now, Source: source}
+	b.addMessage(ref)
+	return b, standup.store.PutBlocker(b)
+}
+
+func (standup *Standup) resolveBlocker(b *Blocker, user, note string, now time.Time) error {
+	b.ResolvedAt, b.ResolvedBy, b.Note = now, user, note
+	return standup.store.PutBlocker(b)
+}
+
+// blockerByMessage returns the open blocker a message is about
+func (standup *Standup) blockerByMessage(channel, ts string) (*Blocker, error) {
+	ref := messageRef(channel, ts)
+	found, err := standup.blockers(func(b *Blocker) bool {
+		if !b.Open() {
+			return false
+		}
+		for _, m := range b.Messages {
+			if m == ref {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+// blockerPosted keeps a message posted about a blocker, called with the
+// lock held
+func (standup *Standup) blockerPosted(id int) func(channel, ts string) {
+	return func(channel, ts string) {
+		found, err := standup.blockers(func(b *Blocker) bool { return b.ID == id })
+		if err != nil || len(found) == 0 {
+			return
+		}
+		found[0].addMessage(messageRef(channel, ts))
+		if err := standup.store.PutBlocker(found[0]); err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+	}
+}
+
+// carryForward asks the owner of the blockers of the previous standups
+// whether they're still blocked, once a day, in the channel or privately.
+// The caller holds the lock.
+func (standup *Standup) carryForward(owner, date, to string, private bool) error {
+	open, err := standup.blockers(func(b *Blocker) bool {
+		return b.Open() && b.Owner == owner && b.Date < date && b.AskedOn != date
+	})
+	if err != nil {
+		return err
+	}
+
+	locale := standup.channelLocale(to)
+	if private {
+		locale = standup.userLocale(owner)
+	}
+	emoji := standup.resolveEmoji()
+
+	for _, b := range open {
+		b.AskedOn = date
+		if err := standup.store.PutBlocker(b); err != nil {
+			return err
+		}
+		text := standup.bot.T(locale, "standup.blocker.still", owner, b.ID, b.Text, b.Date, emoji)
+		standup.post(to, text, private, standup.blockerPosted(b.ID))
+	}
+	return nil
+}
+
+// channelTeam is the name of the first team standing up in a channel, if
+// any. The caller holds the lock.
+func (standup *Standup) channelTeam(channel string) string {
+	for _, team := range standup.teams() {
+		if team.Channel == channel {
+			return team.Name
+		}
+	}
+	return ""
+}
+
+// blockerChannels returns where to post about each blocker: the channel
+// of its team, else the channel of the blockers. The caller holds the
+// lock.
+func (standup *Standup) blockerChannels(settings *blockerSettings) func(*Blocker) string {
+	channels := map[string]string{}
+	for _, team := range standup.teams() {
+		channels[team.Name] = team.Channel
+	}
+	return func(b *Blocker) string {
+		if channel, ok := channels[b.Team]; ok && b.Team != "" {
+			return channel
+		}
+		return settings.Channel
+	}
+}
+
+// blockerVisible tells whether a blocker may be listed in a channel: it's
+// about the team of the channel, or was given there. In private, users see
+// their own blockers and those of their teams. The caller holds the lock.
+func (standup *Standup) blockerVisible(msg *bawt.Message) func(*Blocker) bool {
+	teams := map[string]*Team{}
+	for _, team := range standup.teams() {
+		teams[team.Name] = team
+	}
+	return func(b *Blocker) bool {
+		team := teams[b.Team]
+		if msg.IsPrivate() {
+			return b.Owner == msg.FromUser.ID || (team != nil && team.isMember(msg.FromUser.ID))
+		}
+		if team != nil {
+			return team.Channel == msg.Channel
+		}
+		return strings.HasPrefix(b.Source, msg.Channel+":")
+	}
+}
+
+// blockerSettings checks the blockers section of the config, logging the
+// error once until it changes. The caller holds the lock.
+func (standup *Standup) blockerSettings() *blockerSettings {
+	settings, err := newBlockerSettings(standup.bot, standup.conf)
+	if err != nil {
+		if standup.blockerError != err.Error() {
+			standup.bot.Logging.Logger.WithError(err).Error("Standup: blockers are neither escalated nor summarized")
+			standup.blockerError = err.Error()
+		}
+		return nil
+	}
+	standup.blockerError = ""
+	return settings
+}
+
+// resolveEmoji is the reaction resolving a blocker. The caller holds the
+// lock.
+func (standup *Standup) resolveEmoji() string {
+	if settings := standup.blockerSettings(); settings != nil {
+		return settings.ResolveEmoji
+	}
+	return defaultResolveEmoji
+}
+
+// escalateBlockers tells the lead and the channel of the team, or of the
+// blockers, of the blockers open for too long, once. The caller holds the
+// lock.
+func (standup *Standup) escalateBlockers(settings *blockerSettings, now time.Time) error {
+	if settings.Lead == "" && settings.Channel == "" {
+		return nil
+	}
+
+	after := time.Duration(settings.EscalateAfter) * 24 * time.Hour
+	late, err := standup.blockers(func(b *Blocker) bool {
+		return b.Open() && b.EscalatedAt.IsZero() && now.Sub(b.CreatedAt) >= after
+	})
+	if err != nil {
+		return err
+	}
+
+	channelOf := standup.blockerChannels(settings)
+	for _, b := range late {
+		b.EscalatedAt = now
+		if err := standup.store.PutBlocker(b); err != nil {
+			return err
+		}
+
+		days := int(now.Sub(b.CreatedAt).Hours() / 24)
+		if settings.Channel != "" {
+			channel := channelOf(b)
+			text := standup.bot.T(standup.channelLocale(channel), "standup.blocker.escalated", days, b.Owner, b.ID, b.Text, settings.ResolveEmoji)
+			standup.post(channel, text, false, standup.blockerPosted(b.ID))
+		}
+		if settings.Lead != "" {
+			text := standup.bot.T(standup.userLocale(settings.Lead), "standup.blocker.escalated", days, b.Owner, b.ID, b.Text, settings.ResolveEmoji)
+			standup.post(settings.Lead, text, true, standup.blockerPosted(b.ID))
+		}
+	}
+	return nil
+}
+
+// summarizeBlockers posts the open blockers and those resolved during the
+// week, once a week: those of each team to its channel and the others to
+// the channel of the blockers, or all of them to the lead. The caller
+// holds the lock.
+func (standup *Standup) summarizeBlockers(settings *blockerSettings, now time.Time) error {
+	if settings.Lead == "" && settings.Channel == "" {
+		return nil
+	}
+
+	local := now.In(settings.Location)
+	at := time.Date(local.Year(), local.Month(), local.Day(), settings.SummaryHour, settings.SummaryMin, 0, 0, local.Location())
+	if local.Weekday() != settings.SummaryDay || local.Before(at) {
+		return nil
+	}
+	// The weekly summaries are kept along those of the teams, which all
+	// have a name
+	date := local.Format(dateFormat)
+	if standup.store.Summarized("", date) {
+		return nil
+	}
+
+	weekAgo := now.AddDate(0, 0, -7)
+	all, err := standup.blockers(func(b *Blocker) bool { return b.Open() || b.ResolvedAt.After(weekAgo) })
+	if err != nil {
+		return err
+	}
+
+	tr := func(locale string) func(string, ...interface{}) string {
+		return func(key string, args ...interface{}) string { return standup.bot.T(locale, key, args...) }
+	}
+
+	if settings.Channel == "" {
+		standup.sendPrivate(settings.Lead, blockerSummary(tr(standup.userLocale(settings.Lead)), all))
+		return standup.store.SetSummarized("", date)
+	}
+
+	channelOf := standup.blockerChannels(settings)
+	byChannel := map[string][]*Blocker{}
+	var channels []string
+	for _, b := range all {
+		channel := channelOf(b)
+		if _, ok := byChannel[channel]; !ok {
+			channels = append(channels, channel)
+		}
+		byChannel[channel] = append(byChannel[channel], b)
+	}
+	for _, channel := range channels {
+		standup.send(channel, blockerSummary(tr(standup.channelLocale(channel)), byChannel[channel]))
+	}
+	return standup.store.SetSummarized("", date)
+}
+
+// blockerSummary is the weekly summary of some blockers
+func blockerSummary(t func(string, ...interface{}) string, blockers []*Blocker) string {
+	var open []string
+	resolved := 0
+	for _, b := range blockers {
+		if !b.Open() {
+			resolved++
+			continue
+		}
+		open = append(open, blockerLine(t, b))
+	}
+
+	lines := []string{t("standup.blocker.summary", len(open))}
+	lines = append(lines, open...)
+	lines = append(lines, t("standup.blocker.summary.resolved", resolved))
+	return strings.Join(lines, "\n")
+}
+
+func blockerLine(t func(string, ...interface{}) string, b *Blocker) string {
+	key := "standup.blocker.line"
+	if !b.EscalatedAt.IsZero() {
+		key = "standup.blocker.line.escalated"
+	}
+	return t(key, b.ID, b.Owner, b.Text, b.Date)
+}
+
+// onReaction resolves the blocker of a message reacted to with the
+// resolve emoji
+func (standup *Standup) onReaction(re *bawt.ReactionEvent) {
+	if re.Type != bawt.ReactionAdded {
+		return
+	}
+
+	standup.lock.Lock()
+	defer standup.lock.Unlock()
+
+	if re.Emoji != standup.resolveEmoji() {
+		return
+	}
+	b, err := standup.blockerByMessage(re.Item.Channel, re.Item.Timestamp)
+	if err != nil {
+		standup.bot.ReportError("standup", err)
+	}
+	if b == nil {
+		return
+	}
+
+	if err := standup.resolveBlocker(b, re.User, "", time.Now()); err != nil {
+		standup.bot.ReportError("standup", err)
+		return
+	}
+	standup.send(re.Item.Channel, standup.bot.T(standup.channelLocale(re.Item.Channel), "standup.blocker.resolved", b.ID, b.Text, re.User))
+}
+
+// resolveFromReply resolves the blocker of the thread of a reply like
+// `resolved`, if any. The caller holds the lock.
+func (standup *Standup) resolveFromReply(msg *bawt.Message) (*Blocker, error) {
+	if msg.ThreadTimestamp == "" || msg.ThreadTimestamp == msg.Timestamp || !resolvedReply.MatchString(strings.TrimSpace(msg.Text)) {
+		return nil, nil
+	}
+
+	b, err := standup.blockerByMessage(msg.Channel, msg.ThreadTimestamp)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	note := strings.TrimSpace(resolvedReply.ReplaceAllString(strings.TrimSpace(msg.Text), ""))
+	return b, standup.resolveBlocker(b, msg.FromUser.ID, note, time.Now())
+}
+
+// trackChatBlocker tracks the blocker of a standup given in chat, or
+// edited, then carries forward the previous ones. The caller holds the
+// lock.
+func (standup *Standup) trackChatBlocker(msg *bawt.Message, sections []sectionMatch) {
+	ts := msg.Timestamp
+	if msg.IsEdit {
+		ts = msg.SubMessage.Timestamp
+	}
+	date := timestampToTime(ts).Format(dateFormat)
+	ref := messageRef(msg.Channel, ts)
+
+	text, given := "", false
+	for _, section := range sections {
+		if section.name == "blocking" {
+			text, given = section.text, true
+		}
+	}
+
+	if given || msg.IsEdit {
+		if _, err := standup.trackBlocker(msg.FromUser.ID, standup.channelTeam(msg.Channel), text, date, ref, ref, time.Now()); err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+	}
+	if msg.IsEdit {
+		return
+	}
+
+	if err := standup.carryForward(msg.FromUser.ID, date, msg.Channel, false); err != nil {
+		standup.bot.ReportError("standup", err)
+	}
+}
+
+// listBlockers replies with the open blockers, of everyone or of a user,
+// about the team of the channel, or in private those of the teams of the
+// user asking
+func (standup *Standup) listBlockers(msg *bawt.Message, args []string) {
+	owner := ""
+	if len(args) != 0 {
+		user, ok := standup.bot.Users.Find(bawt.CleanUser(args[0]))
+		if !ok {
+			msg.ReplyMentionT("standup.user_not_found", args[0])
+			return
+		}
+		owner = user.ID
+	}
+
+	standup.lock.Lock()
+	visible := standup.blockerVisible(msg)
+	standup.lock.Unlock()
+
+	open, err := standup.blockers(func(b *Blocker) bool { return b.Open() && (owner == "" || b.Owner == owner) && visible(b) })
+	if err != nil {
+		msg.ReplyMentionT("standup.error", err.Error())
+		return
+	}
+	if len(open) == 0 {
+		msg.ReplyMentionT("standup.blocker.none")
+		return
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].ID < open[j].ID })
+
+	lines := []string{msg.T("standup.blocker.list", len(open))}
+	for _, b := range open {
+		lines = append(lines, blockerLine(msg.T, b))
+	}
+	msg.Reply(strings.Join(lines, "\n"))
+}
+
+// resolveCommand runs `!standup resolve <id> [note]`
+func (standup *Standup) resolveCommand(msg *bawt.Message, args []string) {
+	if len(args) == 0 {
+		msg.ReplyMentionT("standup.resolve.usage")
+		return
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		msg.ReplyMentionT("standup.resolve.usage")
+		return
+	}
+
+	standup.lock.Lock()
+	defer standup.lock.Unlock()
+
+	found, err := standup.blockers(func(b *Blocker) bool { return b.ID == id && b.Open() })
+	if err != nil {
+		msg.ReplyMentionT("standup.error", err.Error())
+		return
+	}
+	if len(found) == 0 {
+		msg.ReplyMentionT("standup.blocker.not_found", id)
+		return
+	}
+
+	b := found[0]
+	if err := standup.resolveBlocker(b, msg.FromUser.ID, strings.Join(args[1:], " "), time.Now()); err != nil {
+		msg.ReplyMentionT("standup.error", err.Error())
+		return
+	}
+	msg.ReplyT("standup.blocker.resolved", b.ID, b.Text, msg.FromUser.ID)
+}
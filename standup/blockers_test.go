@@ -0,0 +1,231 @@
+package standup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/nlopes/slack"
+)
+
+func TestTrackBlocker(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	now := at("2026-10-19 10:00")
+
+	if b, err := standup.trackBlocker("U1", "", "Nothing.", "2026-10-19", "C1:1", "C1:1", now); err != nil || b != nil {
+		t.Fatalf("expected no blocker, got %v, %v", b, err)
+	}
+
+	b, err := standup.trackBlocker("U1", "", "the build", "2026-10-19", "C1:1", "C1:1", now)
+	if err != nil || b == nil || b.ID != 1 || !b.Open() {
+		t.Fatalf("expected a new blocker, got %v, %v", b, err)
+	}
+
+	// The same blocker given again is the same one
+	again, _ := standup.trackBlocker("U1", "", "The build", "2026-10-20", "C1:2", "C1:2", now)
+	if again.ID != 1 || strings.Join(again.Messages, " ") != "C1:1 C1:2" {
+		t.Errorf("expected the same blocker, got %+v", again)
+	}
+
+	// Edits change it, or resolve it
+	edited, _ := standup.trackBlocker("U1", "", "the tests", "2026-10-19", "C1:1", "C1:1", now)
+	if edited.ID != 1 || edited.Text != "the tests" {
+		t.Errorf("expected the blocker to be edited, got %+v", edited)
+	}
+	resolved, _ := standup.trackBlocker("U1", "", "", "2026-10-19", "C1:1", "C1:1", now)
+	if resolved.ID != 1 || resolved.Open() || resolved.ResolvedBy != "U1" {
+		t.Errorf("expected the blocker to be resolved, got %+v", resolved)
+	}
+
+	open, _ := standup.blockers(func(b *Blocker) bool { return b.Open() })
+	if len(open) != 0 {
+		t.Errorf("expected no open blocker, got %v", open)
+	}
+}
+
+func chatMessage(user *slack.User, text string, day time.Time) *bawt.Message {
+	return &bawt.Message{
+		Msg:      &slack.Msg{Channel: "C1", Text: text, Timestamp: strconv.FormatInt(day.Unix(), 10) + ".000100"},
+		FromUser: user,
+	}
+}
+
+func TestChatBlockers(t *testing.T) {
+	standup, out := newTestStandup(t)
+	standup.sectionUpdates = make(chan sectionUpdate, 15)
+	user, _ := standup.bot.Users.Get("U1")
+
+	monday := time.Date(2026, 10, 19, 10, 0, 0, 0, time.Local)
+	standup.ChatHandler(nil, chatMessage(&user, "!today code\n!blocking the build", monday))
+	if len(*out) != 0 {
+		t.Fatalf("expected nothing to be carried forward, got %v", *out)
+	}
+
+	// Carried forward on Tuesday, once
+	tuesday := monday.AddDate(0, 0, 1)
+	standup.ChatHandler(nil, chatMessage(&user, "!today more code", tuesday))
+	standup.ChatHandler(nil, chatMessage(&user, "!yesterday code", tuesday))
+	if len(*out) != 1 || (*out)[0].To != "C1" || !strings.Contains((*out)[0].Text, "still blocked by `#1` the build") {
+		t.Fatalf("expected to be asked once if still blocked, got %v", *out)
+	}
+
+	// Reacting to it resolves it
+	re := &bawt.ReactionEvent{Type: bawt.ReactionAdded, User: "U2", Emoji: "thumbsup"}
+	re.Item.Channel, re.Item.Timestamp = "C1", "1"
+	standup.onReaction(re)
+	re.Emoji = "white_check_mark"
+	standup.onReaction(re)
+
+	open, _ := standup.blockers(func(b *Blocker) bool { return b.Open() })
+	if len(open) != 0 {
+		t.Fatalf("expected the blocker to be resolved, got %v", open)
+	}
+	if len(*out) != 2 || !strings.Contains((*out)[1].Text, "Resolved `#1` the build, thanks <@U2>") {
+		t.Errorf("expected the blocker to be resolved once, got %v", *out)
+	}
+}
+
+func TestResolveFromReply(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	standup.trackBlocker("U1", "", "the build", "2026-10-19", "C1:100.1", "C1:100.1", at("2026-10-19 10:00"))
+
+	user, _ := standup.bot.Users.Get("U1")
+	reply := &bawt.Message{
+		Msg:      &slack.Msg{Channel: "C1", Text: "Fixed by reverting", Timestamp: "200.1", ThreadTimestamp: "100.1"},
+		FromUser: &user,
+	}
+	other := &bawt.Message{
+		Msg:      &slack.Msg{Channel: "C1", Text: "still broken", Timestamp: "150.1", ThreadTimestamp: "100.1"},
+		FromUser: &user,
+	}
+
+	if b, err := standup.resolveFromReply(other); b != nil || err != nil {
+		t.Errorf("expected a reply not saying it's resolved to be ignored, got %v, %v", b, err)
+	}
+	if b, err := standup.resolveFromReply(reply); b == nil || err != nil {
+		t.Fatalf("expected the blocker of the thread, got %v, %v", b, err)
+	}
+
+	all, _ := standup.blockers(func(b *Blocker) bool { return true })
+	if len(all) != 1 || all[0].Open() || all[0].Note != "by reverting" {
+		t.Errorf("expected the blocker to be resolved with a note, got %+v", all[0])
+	}
+}
+
+func TestScheduledBlockers(t *testing.T) {
+	standup, out := newTestStandup(t)
+	newTestTeam(standup)
+	standup.conf.Teams[0].Members = []string{"A"}
+	standup.conf.Blockers = BlockerConfig{Lead: "@B", Channel: "#backend", EscalateAfter: 2}
+
+	standup.tick(at("2026-10-21 09:30"))
+	for _, answer := range []string{"coded", "tested", "the VPN"} {
+		standup.answer("U1", answer, at("2026-10-21 09:35"))
+	}
+	open, _ := standup.blockers(func(b *Blocker) bool { return b.Open() })
+	if len(open) != 1 || open[0].Owner != "U1" || open[0].Text != "the VPN" || open[0].Date != "2026-10-21" {
+		t.Fatalf("expected the answer to be tracked, got %v", open)
+	}
+
+	// Carried forward privately before the questions of the next day
+	*out = nil
+	standup.tick(at("2026-10-22 09:30"))
+	if len(*out) != 2 || (*out)[0].To != "U1" || !strings.Contains((*out)[0].Text, "still blocked by `#1` the VPN (since 2026-10-21)") {
+		t.Fatalf("expected to be asked if still blocked, got %v", *out)
+	}
+
+	// Escalated once, to the channel and the lead
+	*out = nil
+	standup.tick(at("2026-10-23 09:34"))
+	standup.tick(at("2026-10-23 09:35"))
+	standup.tick(at("2026-10-23 09:36"))
+	var escalated []bawttest.Sent
+	for _, s := range *out {
+		if strings.Contains(s.Text, "blocked for 2 days") {
+			escalated = append(escalated, s)
+		}
+	}
+	if len(escalated) != 2 || escalated[0].To != "C1" || escalated[1].To != "U2" {
+		t.Errorf("expected the blocker to be escalated once, got %v", *out)
+	}
+
+	// Weekly summary on Friday at 16:00
+	*out = nil
+	standup.tick(at("2026-10-23 15:59"))
+	standup.tick(at("2026-10-23 16:00"))
+	standup.tick(at("2026-10-23 16:01"))
+	var summaries []bawttest.Sent
+	for _, s := range *out {
+		if strings.Contains(s.Text, "Blockers of the week") {
+			summaries = append(summaries, s)
+		}
+	}
+	if len(summaries) != 1 || summaries[0].To != "C1" || summaries[0].Text != strings.Join([]string{
+		"*Blockers of the week*: 1 open",
+		"`#1` <@U1> the VPN, since 2026-10-21 (escalated)",
+		"0 resolved this week",
+	}, "\n") {
+		t.Errorf("expected the weekly summary once, got %v", *out)
+	}
+}
+
+func TestBlockersOfTeams(t *testing.T) {
+	standup, out := newTestStandup(t)
+	standup.bot.Channels.Set(bawt.Channel{ID: "C2", Name: "design"})
+	standup.bot.Channels.Set(bawt.Channel{ID: "C3", Name: "leads"})
+	standup.conf = Config{
+		Timezone: "UTC",
+		Teams: []TeamConfig{
+			{Name: "backend", Channel: "#backend", Members: []string{"A"}},
+			{Name: "design", Channel: "#design", Members: []string{"B"}},
+		},
+		Blockers: BlockerConfig{Channel: "#leads"},
+	}
+
+	now := at("2026-10-19 10:00")
+	standup.trackBlocker("U1", "backend", "the VPN", "2026-10-19", "session:backend:2026-10-19:U1", "", now)
+	standup.trackBlocker("U2", "design", "the mockups", "2026-10-19", "session:design:2026-10-19:U2", "", now)
+	standup.trackBlocker("U1", "", "the build", "2026-10-19", "C3:1", "C3:1", now)
+
+	listed := func(channel, user string) []int {
+		visible := standup.blockerVisible(&bawt.Message{Msg: &slack.Msg{Channel: channel}, FromUser: &slack.User{ID: user}})
+		var ids []int
+		all, _ := standup.blockers(visible)
+		for _, b := range all {
+			ids = append(ids, b.ID)
+		}
+		return ids
+	}
+	for _, test := range []struct {
+		channel, user string
+		want          string
+	}{
+		{channel: "C1", user: "U2", want: "[1]"},
+		{channel: "C2", user: "U1", want: "[2]"},
+		{channel: "C3", user: "U2", want: "[3]"},
+		{channel: "D1", user: "U1", want: "[1 3]"},
+		{channel: "D2", user: "U2", want: "[2]"},
+	} {
+		if got := fmt.Sprint(listed(test.channel, test.user)); got != test.want {
+			t.Errorf("%s, %s: expected blockers %s, got %s", test.channel, test.user, test.want, got)
+		}
+	}
+
+	// Each team gets the summary of its blockers
+	settings := standup.blockerSettings()
+	if err := standup.summarizeBlockers(settings, at("2026-10-23 16:00")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*out) != 3 {
+		t.Fatalf("expected 3 summaries, got %v", *out)
+	}
+	for i, want := range []struct{ to, text string }{{"C1", "the VPN"}, {"C2", "the mockups"}, {"C3", "the build"}} {
+		if s := (*out)[i]; s.To != want.to || !strings.Contains(s.Text, "1 open") || !strings.Contains(s.Text, want.text) {
+			t.Errorf("expected the summary of %s in %s, got %v", want.text, want.to, s)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package standup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+/*
+Config is the `standup` section of the configuration, like:
+
+	standup:
+	  timezone: Europe/Paris
+	  holidays: ["2026-12-25", "2027-01-01"]
+	  teams:
+	    - name: backend
+	      channel: "#backend"
+	      members: [alice, bob]
+	      time: "09:30"
+	      remind_after: 1h
+	      reminders: 2
+	      summary_time: "11:00"
+	      holidays: ["2026-11-11"]
+	    - name: design
+	      channel: "#design"
+	      group: designers
+	      days: [monday, wednesday, friday]
+	      questions:
+	        - id: done
+	          text: What did you finish?
+	        - id: next
+	          text: What's next?
+	      summary_format: |
+	        {{range .Members}}<@{{.User}}>: {{range .Answers}}{{.Text}}. {{end}}
+	        {{end}}
+
+Each team's members are asked the standup questions privately on working
+days at `time`, in their own timezone when Slack knows it, else in the
+team's `timezone`, else the global one. Those who haven't answered are
+reminded every `remind_after` (1h by default), up to `reminders` times
+(2 by default). The answers are posted to the team `channel` at
+`summary_time` (3 hours after `time` by default) in the team's timezone.
+Only the `days` of the week are working days, Monday to Friday by
+default, and the `holidays` of the team and the global ones are skipped.
+
+Members can be listed, or be those of the internal `group`, or both. Each
+question of `questions` (yesterday, today and blocking by default) has an
+ID, which is also the `!<id>` section of the standups given in chat. The
+summary can be rendered with a Go template in `summary_format`, given a
+summaryData.
+
+Teams can also be defined in chat with `!standup team create`, and are
+then stored in BoltDB.
+
+The answers to `blocking`, in chat or in private, are tracked until
+resolved, see BlockerConfig.
+*/
+type Config struct {
+	Timezone string
+	Holidays []string
+	Teams    []TeamConfig
+	Blockers BlockerConfig
+}
+
+/*
+BlockerConfig is the `standup.blockers` section of the configuration, like:
+
+	standup:
+	  blockers:
+	    escalate_after: 3
+	    lead: alice
+	    channel: "#leads"
+	    resolve_emoji: white_check_mark
+	    summary_day: friday
+	    summary_time: "16:00"
+
+Blockers still open after `escalate_after` days (3 by default) are
+escalated to the `lead` privately and, when `channel` is set, to the
+channel of the team they were given in, or `channel` for the others. The
+channels get a summary of their blockers every week on `summary_day` at
+`summary_time`, in the global timezone, or the lead all of them without a
+channel. Without a lead nor a channel, blockers are only
+carried forward in the standups of their owner. Reacting with
+`resolve_emoji` to a message about a blocker resolves it.
+*/
+type BlockerConfig struct {
+	EscalateAfter int `mapstructure:"escalate_after"`
+	Lead          string
+	Channel       string
+	ResolveEmoji  string `mapstructure:"resolve_emoji"`
+	SummaryDay    string `mapstructure:"summary_day"`
+	SummaryTime   string `mapstructure:"summary_time"`
+}
+
+// TeamConfig defines a team running its standup over private messages
+type TeamConfig struct {
+	Name          string
+	Channel       string
+	Members       []string
+	Group         string
+	Questions     []Question
+	Time          string
+	Days          []string
+	Timezone      string
+	RemindAfter   string `mapstructure:"remind_after"`
+	Reminders     *int
+	SummaryTime   string `mapstructure:"summary_time"`
+	SummaryFormat string `mapstructure:"summary_format"`
+	Holidays      []string
+}
+
+const (
+	defaultStandupTime = "09:30"
+	defaultRemindAfter = time.Hour
+	defaultReminders   = 2
+	defaultSummaryWait = 3 * time.Hour
+
+	defaultEscalateAfter      = 3
+	defaultResolveEmoji       = "white_check_mark"
+	defaultBlockerSummaryDay  = time.Friday
+	defaultBlockerSummaryTime = "16:00"
+)
+
+// Team is a team ready to be scheduled, its channel and members resolved
+// into Slack IDs
+type Team struct {
+	Name        string
+	Channel     string
+	Members     []string
+	Questions   []Question
+	Hour, Min   int
+	Location    *time.Location
+	RemindAfter time.Duration
+	Reminders   int
+	// SummaryAfter is the delay between asking and posting the summary
+	SummaryAfter time.Duration
+	// Summary renders the summary, when the team has its own format
+	Summary  *template.Template
+	Days     map[time.Weekday]bool
+	Holidays map[string]bool
+}
+
+// Question is asked to each member of a team in turn. Its ID names the
+// section of the standup it answers.
+type Question struct {
+	ID   string
+	Text string
+}
+
+// defaultQuestions are the sections of the chat standups
+var defaultQuestions = []Question{{ID: "yesterday"}, {ID: "today"}, {ID: "blocking"}}
+
+// questionID is the format of the ID of a question, as in `!<id>`
+var questionID = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// defaultDays are the working days of a team
+var defaultDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// parseWeekday understands the days of the week, in full or abbreviated
+func parseWeekday(s string) (time.Weekday, bool) {
+	in := strings.ToLower(s)
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		name := strings.ToLower(w.String())
+		if in == name || in == name[:3] {
+			return w, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// location is the global timezone, the local one by default
+func (conf Config) location() (*time.Location, error) {
+	if conf.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(conf.Timezone)
+}
+
+// newTeam checks and resolves the definition of a team
+func newTeam(bot *bawt.Bot, global Config, conf TeamConfig) (*Team, error) {
+	if conf.Name == "" {
+		return nil, fmt.Errorf("a team needs a name")
+	}
+
+	team := &Team{
+		Name:        conf.Name,
+		Questions:   defaultQuestions,
+		RemindAfter: defaultRemindAfter,
+		Reminders:   defaultReminders,
+		Days:        map[time.Weekday]bool{},
+		Holidays:    map[string]bool{},
+	}
+
+	channel, ok := bot.Channels.ByName(strings.TrimPrefix(conf.Channel, "#"))
+	if !ok {
+		channel, ok = bot.Channels.Get(conf.Channel)
+	}
+	if !ok {
+		return nil, fmt.Errorf("team %s: unknown channel %q", conf.Name, conf.Channel)
+	}
+	team.Channel = channel.ID
+
+	for _, member := range conf.Members {
+		user, ok := bot.Users.Find(strings.TrimPrefix(member, "@"))
+		if !ok {
+			return nil, fmt.Errorf("team %s: unknown member %q", conf.Name, member)
+		}
+		team.addMember(user.ID)
+	}
+
+	if conf.Group != "" {
+		group := bawt.InternalGroup{Name: conf.Group}
+		if err := group.Get(bot.DB); err != nil {
+			return nil, fmt.Errorf("team %s: group %s: %s", conf.Name, conf.Group, err)
+		}
+		for _, member := range group.Members {
+			if member != "" {
+				team.addMember(member)
+			}
+		}
+	}
+
+	if len(conf.Questions) != 0 {
+		seen := map[string]bool{}
+		for _, q := range conf.Questions {
+			if !questionID.MatchString(q.ID) || q.ID == "standup" {
+				return nil, fmt.Errorf("team %s: invalid question ID %q, use lowercase letters, digits and _", conf.Name, q.ID)
+			}
+			if seen[q.ID] {
+				return nil, fmt.Errorf("team %s: question %q is there twice", conf.Name, q.ID)
+			}
+			seen[q.ID] = true
+		}
+		team.Questions = conf.Questions
+	}
+
+	days := defaultDays
+	if len(conf.Days) != 0 {
+		days = nil
+		for _, day := range conf.Days {
+			w, ok := parseWeekday(day)
+			if !ok {
+				return nil, fmt.Errorf("team %s: invalid day %q", conf.Name, day)
+			}
+			days = append(days, w)
+		}
+	}
+	for _, w := range days {
+		team.Days[w] = true
+	}
+
+	at := conf.Time
+	if at == "" {
+		at = defaultStandupTime
+	}
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("team %s: invalid time %q, use HH:MM", conf.Name, at)
+	}
+	team.Hour, team.Min = t.Hour(), t.Minute()
+
+	tz := conf.Timezone
+	if tz == "" {
+		tz = global.Timezone
+	}
+	team.Location = time.Local
+	if tz != "" {
+		if team.Location, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("team %s: %s", conf.Name, err)
+		}
+	}
+
+	if conf.RemindAfter != "" {
+		if team.RemindAfter, err = time.ParseDuration(conf.RemindAfter); err != nil {
+			return nil, fmt.Errorf("team %s: invalid remind_after: %s", conf.Name, err)
+		}
+	}
+	if conf.Reminders != nil {
+		team.Reminders = *conf.Reminders
+	}
+
+	team.SummaryAfter = defaultSummaryWait
+	if conf.SummaryTime != "" {
+		s, err := time.Parse("15:04", conf.SummaryTime)
+		if err != nil {
+			return nil, fmt.Errorf("team %s: invalid summary_time %q, use HH:MM", conf.Name, conf.SummaryTime)
+		}
+		team.SummaryAfter = s.Sub(t)
+		if team.SummaryAfter <= 0 {
+			return nil, fmt.Errorf("team %s: summary_time must come after time", conf.Name)
+		}
+	}
+
+	if conf.SummaryFormat != "" {
+		if team.Summary, err = template.New(conf.Name).Parse(conf.SummaryFormat); err != nil {
+			return nil, fmt.Errorf("team %s: invalid summary_format: %s", conf.Name, err)
+		}
+	}
+
+	for _, days := range [][]string{global.Holidays, conf.Holidays} {
+		for _, day := range days {
+			if _, err := time.Parse("2006-01-02", day); err != nil {
+				return nil, fmt.Errorf("team %s: invalid holiday %q, use YYYY-MM-DD", conf.Name, day)
+			}
+			team.Holidays[day] = true
+		}
+	}
+
+	return team, nil
+}
+
+// workday tells whether the team holds its standup on the day of t
+func (team *Team) workday(t time.Time) bool {
+	return team.Days[t.Weekday()] && !team.Holidays[t.Format("2006-01-02")]
+}
+
+// startOn returns when the standup starts on the day of t, in its
+// location
+func (team *Team) startOn(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), team.Hour, team.Min, 0, 0, t.Location())
+}
+
+// isMember tells whether a user is asked the standup questions
+func (team *Team) isMember(user string) bool {
+	for _, member := range team.Members {
+		if member == user {
+			return true
+		}
+	}
+	return false
+}
+
+func (team *Team) addMember(user string) {
+	if !team.isMember(user) {
+		team.Members = append(team.Members, user)
+	}
+}
+
+// blockerSettings are the settings of the blockers, checked and resolved
+// into Slack IDs
+type blockerSettings struct {
+	EscalateAfter int
+	Lead          string
+	Channel       string
+	ResolveEmoji  string
+	SummaryDay    time.Weekday
+	// SummaryHour and SummaryMin are when the weekly summary is posted
+	SummaryHour, SummaryMin int
+	Location                *time.Location
+}
+
+// newBlockerSettings checks the blockers section of the config
+func newBlockerSettings(bot *bawt.Bot, global Config) (*blockerSettings, error) {
+	conf := global.Blockers
+	settings := &blockerSettings{
+		EscalateAfter: defaultEscalateAfter,
+		ResolveEmoji:  strings.Trim(conf.ResolveEmoji, ":"),
+		SummaryDay:    defaultBlockerSummaryDay,
+	}
+	if conf.EscalateAfter != 0 {
+		settings.EscalateAfter = conf.EscalateAfter
+	}
+	if settings.ResolveEmoji == "" {
+		settings.ResolveEmoji = defaultResolveEmoji
+	}
+
+	if conf.Lead != "" {
+		user, ok := bot.Users.Find(strings.TrimPrefix(conf.Lead, "@"))
+		if !ok {
+			return nil, fmt.Errorf("blockers: unknown lead %q", conf.Lead)
+		}
+		settings.Lead = user.ID
+	}
+
+	if conf.Channel != "" {
+		channel, ok := bot.Channels.ByName(strings.TrimPrefix(conf.Channel, "#"))
+		if !ok {
+			channel, ok = bot.Channels.Get(conf.Channel)
+		}
+		if !ok {
+			return nil, fmt.Errorf("blockers: unknown channel %q", conf.Channel)
+		}
+		settings.Channel = channel.ID
+	}
+
+	if conf.SummaryDay != "" {
+		w, ok := parseWeekday(conf.SummaryDay)
+		if !ok {
+			return nil, fmt.Errorf("blockers: invalid summary_day %q", conf.SummaryDay)
+		}
+		settings.SummaryDay = w
+	}
+
+	at := conf.SummaryTime
+	if at == "" {
+		at = defaultBlockerSummaryTime
+	}
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("blockers: invalid summary_time %q, use HH:MM", at)
+	}
+	settings.SummaryHour, settings.SummaryMin = t.Hour(), t.Minute()
+
+	if settings.Location, err = global.location(); err != nil {
+		return nil, fmt.Errorf("blockers: %s", err)
+	}
+
+	return settings, nil
+}
@@ -11,6 +11,65 @@ import (
 
 var sectionRegexp = regexp.MustCompile(`(?mi)^!(yesterday|today|blocking)`)
 
+// sectionsRegexp matches the sections of the given question IDs
+func sectionsRegexp(ids []string) *regexp.Regexp {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = regexp.QuoteMeta(id)
+	}
+	return regexp.MustCompile(`(?mi)^!(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// parser returns the regexp of the sections of every team, which are the
+// default ones unless teams have their own questions. The caller holds
+// the lock.
+func (standup *Standup) parser() *regexp.Regexp {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, questions := range append([][]Question{defaultQuestions}, standup.questionSets()...) {
+		for _, q := range questions {
+			if !seen[q.ID] {
+				seen[q.ID] = true
+				ids = append(ids, q.ID)
+			}
+		}
+	}
+
+	key := strings.Join(ids, "|")
+	if standup.parserKey != key || standup.parserRegexp == nil {
+		standup.parserKey, standup.parserRegexp = key, sectionsRegexp(ids)
+	}
+	return standup.parserRegexp
+}
+
+// questionSets returns the questions of each team
+func (standup *Standup) questionSets() [][]Question {
+	var sets [][]Question
+	for _, team := range standup.teams() {
+		sets = append(sets, team.Questions)
+	}
+	return sets
+}
+
+// sectionsFor returns the sections expected from a user: those of the
+// first team they're a member of, else the default ones. The caller
+// holds the lock.
+func (standup *Standup) sectionsFor(user string) []string {
+	questions := defaultQuestions
+	for _, team := range standup.teams() {
+		if team.isMember(user) {
+			questions = team.Questions
+			break
+		}
+	}
+
+	ids := make([]string, len(questions))
+	for i, q := range questions {
+		ids[i] = q.ID
+	}
+	return ids
+}
+
 type sectionMatch struct {
 	name string
 	text string
@@ -24,7 +83,7 @@ func extractSectionAndText(input string, res [][]int) []sectionMatch {
 		el := res[i]
 
 		section := input[el[2]:el[3]] // (2,3) is second group's (start,end)
-		strings.ToLower(section)
+		section = strings.ToLower(section)
 
 		var endFullText = len(input)
 		if (i + 1) < len(res) {
@@ -40,7 +99,11 @@ func extractSectionAndText(input string, res [][]int) []sectionMatch {
 }
 
 func (standup *Standup) TriggerReminders(msg *bawt.Message, section string) {
-	standup.sectionUpdates <- sectionUpdate{section, msg}
+	standup.lock.Lock()
+	expected := standup.sectionsFor(msg.FromUser.ID)
+	standup.lock.Unlock()
+
+	standup.sectionUpdates <- sectionUpdate{section, msg, expected}
 }
 
 //
@@ -61,6 +124,7 @@ func (standup *Standup) manageUpdatesInteraction() {
 			if progress == nil {
 				progress = &userProgress{
 					sectionsDone: map[string]bool{},
+					expected:     update.expected,
 					cancelTimer:  make(chan bool),
 				}
 				userProgressMap[userEmail] = progress
@@ -72,7 +136,7 @@ func (standup *Standup) manageUpdatesInteraction() {
 
 				progress.sectionsDone[update.section] = true
 				numDone := len(progress.sectionsDone)
-				if numDone == 3 {
+				if numDone >= len(progress.expected) {
 					update.msg.ReplyMention("got it!")
 					delete(userProgressMap, update.msg.FromUser.Profile.Email)
 				} else {
@@ -97,15 +161,15 @@ func (standup *Standup) manageUpdatesInteraction() {
 				continue
 			}
 
-			remains := make([]string, 0, 3)
-			if userProgress.sectionsDone["today"] == false {
-				remains = append(remains, "today")
-			}
-			if userProgress.sectionsDone["yesterday"] == false {
-				remains = append(remains, "yesterday")
-			}
-			if userProgress.sectionsDone["blocking"] == false {
-				remains = append(remains, "blocking stuff")
+			remains := make([]string, 0, len(userProgress.expected))
+			for _, section := range userProgress.expected {
+				if userProgress.sectionsDone[section] {
+					continue
+				}
+				if section == "blocking" {
+					section = "blocking stuff"
+				}
+				remains = append(remains, section)
 			}
 
 			remain := strings.Join(remains, " or ")
@@ -118,14 +182,16 @@ func (standup *Standup) manageUpdatesInteraction() {
 }
 
 type sectionUpdate struct {
-	section string
-	msg     *bawt.Message
+	section  string
+	msg      *bawt.Message
+	expected []string
 }
 
 var userProgressMap = make(map[string]*userProgress)
 
 type userProgress struct {
 	sectionsDone map[string]bool
+	expected     []string
 	cancelTimer  chan bool
 }
 
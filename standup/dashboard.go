@@ -0,0 +1,256 @@
+package standup
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// webEntry is the standup of a user on a day, as shown by the dashboard
+// and exported
+type webEntry struct {
+	Date  string `json:"date"`
+	User  string `json:"user,omitempty"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Sections are the answers by section, like `today`
+	Sections  map[string]string `json:"sections"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// webParticipation tells how often a user gave their standup on the
+// working days of a period
+type webParticipation struct {
+	User     string  `json:"user,omitempty"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Days     int     `json:"days"`
+	Answered int     `json:"answered"`
+	Rate     float64 `json:"rate"`
+	// Streak is the number of working days in a row answered up to the
+	// end of the period, Longest the longest during the period
+	Streak  int `json:"streak"`
+	Longest int `json:"longest"`
+}
+
+// webBlocker is an open blocker
+type webBlocker struct {
+	ID    int    `json:"id"`
+	Date  string `json:"date"`
+	User  string `json:"user,omitempty"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Text  string `json:"text"`
+	// Asked is the last day the owner was asked if still blocked
+	Asked     string `json:"asked,omitempty"`
+	Escalated bool   `json:"escalated"`
+}
+
+// webFilter selects the entries of the dashboard and the exports
+type webFilter struct {
+	From, To time.Time
+	// Team restricts to its members, and sets the working days
+	Team *Team
+	// Email restricts to a user, by email or ID like the entries
+	Email string
+}
+
+// dashboardTeam is the team of the web views not asking for one: everyone
+// having given a standup, on the default working days
+func (standup *Standup) dashboardTeam() *Team {
+	team := &Team{Days: map[time.Weekday]bool{}, Holidays: map[string]bool{}}
+	for _, w := range defaultDays {
+		team.Days[w] = true
+	}
+	for _, day := range standup.conf.Holidays {
+		team.Holidays[day] = true
+	}
+	return team
+}
+
+// memberEmails returns the keys of the entries of the members of a team
+func (standup *Standup) memberEmails(team *Team) map[string]bool {
+	emails := map[string]bool{}
+	for _, member := range team.Members {
+		user, ok := standup.bot.Users.Get(member)
+		if !ok {
+			user.ID = member
+		}
+		emails[userKey(&user, standupDate{}).email] = true
+	}
+	return emails
+}
+
+// webEntries returns the entries matching a filter, by date then name
+func (standup *Standup) webEntries(f webFilter) ([]webEntry, error) {
+	entries, err := standup.store.Range(timeToStandupDate(f.From), timeToStandupDate(f.To))
+	if err != nil {
+		return nil, err
+	}
+
+	var emails map[string]bool
+	if f.Team != nil && f.Team.Name != "" {
+		emails = standup.memberEmails(f.Team)
+	}
+
+	out := []webEntry{}
+	for key, data := range entries {
+		if emails != nil && !emails[key.email] || f.Email != "" && !strings.EqualFold(f.Email, key.email) {
+			continue
+		}
+
+		user := standup.userByEmail(key.email)
+		out = append(out, webEntry{
+			Date:      key.date.format(),
+			User:      user.ID,
+			Name:      user.Name,
+			Email:     key.email,
+			Sections:  data.sections(),
+			UpdatedAt: data.LastUpdate,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// webMembers returns the members of a team, to list those who never
+// answered along with the others
+func (standup *Standup) webMembers(team *Team) []webParticipation {
+	var out []webParticipation
+	for email := range standup.memberEmails(team) {
+		user := standup.userByEmail(email)
+		out = append(out, webParticipation{User: user.ID, Name: user.Name, Email: email})
+	}
+	return out
+}
+
+// participation counts the working days of the team each user answered,
+// from the entries of a period, for the members given and those having
+// entries. The last day counts once answered, as it may not be over yet.
+func participation(team *Team, from, to time.Time, entries []webEntry, members []webParticipation) []webParticipation {
+	answered := map[string]map[string]bool{}
+	users := map[string]*webParticipation{}
+	for i := range members {
+		answered[members[i].Email] = map[string]bool{}
+		users[members[i].Email] = &members[i]
+	}
+	for _, e := range entries {
+		if answered[e.Email] == nil {
+			answered[e.Email] = map[string]bool{}
+			users[e.Email] = &webParticipation{User: e.User, Name: e.Name, Email: e.Email}
+		}
+		answered[e.Email][e.Date] = true
+	}
+
+	var days []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if team.workday(day) {
+			days = append(days, day.Format(dateFormat))
+		}
+	}
+
+	out := []webParticipation{}
+	for email, p := range users {
+		run := 0
+		for i, day := range days {
+			switch {
+			case answered[email][day]:
+				p.Days++
+				p.Answered++
+				run++
+			case i == len(days)-1:
+				// The last day may not be over
+			default:
+				p.Days++
+				run = 0
+			}
+			if run > p.Longest {
+				p.Longest = run
+			}
+		}
+		p.Streak = run
+		if p.Days != 0 {
+			p.Rate = float64(p.Answered) / float64(p.Days)
+		}
+		out = append(out, *p)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// webBlockers returns the blockers open at the end of the period, given
+// by the users of the filter
+func (standup *Standup) webBlockers(f webFilter) ([]webBlocker, error) {
+	var emails map[string]bool
+	if f.Team != nil && f.Team.Name != "" {
+		emails = standup.memberEmails(f.Team)
+	}
+	end := f.To.AddDate(0, 0, 1)
+
+	open, err := standup.blockers(func(b *Blocker) bool {
+		return b.CreatedAt.Before(end) && (b.Open() || !b.ResolvedAt.Before(end))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := []webBlocker{}
+	for _, b := range open {
+		user, ok := standup.bot.Users.Get(b.Owner)
+		if !ok {
+			user.ID = b.Owner
+		}
+		email := userKey(&user, standupDate{}).email
+		if emails != nil && !emails[email] || f.Email != "" && !strings.EqualFold(f.Email, email) {
+			continue
+		}
+
+		out = append(out, webBlocker{
+			ID:        b.ID,
+			Date:      b.Date,
+			User:      b.Owner,
+			Name:      standup.userByEmail(email).Name,
+			Email:     email,
+			Text:      b.Text,
+			Asked:     b.AskedOn,
+			Escalated: !b.EscalatedAt.IsZero(),
+		})
+	}
+	return out, nil
+}
+
+// sectionOrder returns the sections of the entries, those of the team's
+// questions first
+func sectionOrder(team *Team, entries []webEntry) []string {
+	questions := team.Questions
+	if len(questions) == 0 {
+		questions = defaultQuestions
+	}
+
+	var out []string
+	seen := map[string]bool{}
+	for _, q := range questions {
+		out = append(out, q.ID)
+		seen[q.ID] = true
+	}
+
+	var others []string
+	for _, e := range entries {
+		for section := range e.Sections {
+			if !seen[section] {
+				seen[section] = true
+				others = append(others, section)
+			}
+		}
+	}
+	sort.Strings(others)
+
+	return append(out, others...)
+}
@@ -0,0 +1,53 @@
+package standup
+
+import "github.com/gopherworks/bawt"
+
+func init() {
+	bawt.RegisterMessages(bawt.DefaultLocale, map[string]bawt.Translation{
+		"standup.usage":                    {Other: "Please use `!standup report [@user] [last N days]`, `!standup blockers [@user]`, `!standup resolve <id> [note]` or `!standup team ...`"},
+		"standup.error":                    {Other: "Couldn't read the standups: %s"},
+		"standup.user_not_found":           {Other: "I don't know any user called %s"},
+		"standup.dm.intro":                 {Other: "Hi! Time for the *%s* standup of this %s."},
+		"standup.dm.reminder":              {Other: "Still there? The *%s* standup is waiting for you."},
+		"standup.dm.thanks":                {Other: "Thanks, that's all! Your answers go to <#%s>."},
+		"standup.question.yesterday":       {Other: "What did you do yesterday?"},
+		"standup.question.today":           {Other: "What will you do today?"},
+		"standup.question.blocking":        {Other: "Is anything blocking you?"},
+		"standup.section.yesterday":        {Other: "Yesterday:"},
+		"standup.section.today":            {Other: "Today:"},
+		"standup.section.blocking":         {Other: "Blocking:"},
+		"standup.summary":                  {Other: "*%s standup*, %s"},
+		"standup.summary.missing":          {Other: "No answer from %[2]s"},
+		"standup.summary.nobody":           {Other: "Nobody answered."},
+		"standup.summary.late":             {Other: "<@%s> answered the *%s* standup late:"},
+		"standup.report.empty":             {One: "No standup today", Other: "No standup in the last %d days"},
+		"standup.blocker.still":            {Other: "<@%s>, still blocked by `#%d` %s (since %s)? React with :%s: or reply `resolved` in the thread once it's sorted."},
+		"standup.blocker.escalated":        {One: "<@%[2]s> has been blocked for a day by `#%[3]d` %[4]s. React with :%[5]s: once it's sorted.", Other: "<@%[2]s> has been blocked for %[1]d days by `#%[3]d` %[4]s. React with :%[5]s: once it's sorted."},
+		"standup.blocker.resolved":         {Other: "Resolved `#%d` %s, thanks <@%s>!"},
+		"standup.blocker.not_found":        {Other: "No open blocker `#%d`"},
+		"standup.blocker.none":             {Other: "Nobody is blocked."},
+		"standup.blocker.list":             {One: "1 open blocker:", Other: "%d open blockers:"},
+		"standup.blocker.line":             {Other: "`#%d` <@%s> %s, since %s"},
+		"standup.blocker.line.escalated":   {Other: "`#%d` <@%s> %s, since %s (escalated)"},
+		"standup.blocker.summary":          {One: "*Blockers of the week*: 1 open", Other: "*Blockers of the week*: %d open"},
+		"standup.blocker.summary.resolved": {One: "1 resolved this week", Other: "%d resolved this week"},
+		"standup.resolve.usage":            {Other: "Please resolve a blocker with `!standup resolve <id> [note]`"},
+		"standup.team.usage": {Other: "Please use:```\n" +
+			"!standup team list\n" +
+			"!standup team create [name] [#channel] [HH:MM] [timezone]\n" +
+			"!standup team delete [name]\n" +
+			"!standup team add [name] [@user...]\n" +
+			"!standup team remove [name] [@user...]\n" +
+			"!standup team questions [name] [id: question | id: question | default]\n" +
+			"!standup team set [name] [channel|time|timezone|days|remind_after|reminders|summary_time|summary_format|group|holidays] [value|none]\n" +
+			"```"},
+		"standup.team.error":   {Other: "Couldn't change team %s: %s"},
+		"standup.team.created": {Other: "Created team %s, add its members with `!standup team add`"},
+		"standup.team.deleted": {Other: "Deleted team %s"},
+		"standup.team.updated": {Other: "Updated team %s"},
+		"standup.team.none":    {Other: "No team yet, create one with `!standup team create`"},
+		"standup.team.list":    {One: "1 team:", Other: "%d teams:"},
+		"standup.team.line":    {Other: "*%s* in <#%s>, %d members at %s: %s"},
+		"standup.team.broken":  {Other: "*%s* is left out: %s"},
+	})
+}
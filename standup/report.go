@@ -0,0 +1,117 @@
+package standup
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+)
+
+var lastDaysRegexp = regexp.MustCompile(`(?i)^(?:last\s+)?(\d+)\s*(?:d|days?)$`)
+
+func (standup *Standup) handleStandup(listen *bawt.Listener, msg *bawt.Message) {
+	args := strings.Fields(msg.Match[0])[1:]
+	if len(args) == 0 {
+		msg.ReplyMentionT("standup.usage")
+		return
+	}
+
+	switch args[0] {
+	case "report":
+		standup.replyReport(msg, args[1:])
+	case "team":
+		standup.handleTeam(msg, args[1:])
+	case "blockers":
+		standup.listBlockers(msg, args[1:])
+	case "resolve":
+		standup.resolveCommand(msg, args[1:])
+	default:
+		msg.ReplyMentionT("standup.usage")
+	}
+}
+
+// parseReportArgs understands `[@user] [last N days]`, returning the
+// user as typed and the number of days, 7 by default
+func parseReportArgs(args []string) (user string, days int, ok bool) {
+	days = 1 - WEEKAGO
+	if len(args) != 0 && !lastDaysRegexp.MatchString(strings.Join(args, " ")) {
+		user, args = args[0], args[1:]
+	}
+
+	if len(args) != 0 {
+		match := lastDaysRegexp.FindStringSubmatch(strings.Join(args, " "))
+		if match == nil {
+			return "", 0, false
+		}
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 {
+			return "", 0, false
+		}
+		days = n
+	}
+
+	return user, days, true
+}
+
+func (standup *Standup) replyReport(msg *bawt.Message, args []string) {
+	name, days, ok := parseReportArgs(args)
+	if !ok {
+		msg.ReplyMentionT("standup.usage")
+		return
+	}
+
+	sm, err := standup.report(days)
+	if err != nil {
+		msg.ReplyMentionT("standup.error", err.Error())
+		return
+	}
+
+	if name != "" {
+		user, found := standup.bot.Users.Find(bawt.CleanUser(name))
+		if !found {
+			msg.ReplyMentionT("standup.user_not_found", name)
+			return
+		}
+		sm = sm.filterByEmail(userKey(&user, standupDate{}).email)
+	}
+
+	if len(sm) == 0 {
+		msg.ReplyMentionT("standup.report.empty", days)
+		return
+	}
+
+	msg.Reply("```\n" + sm.String() + "```")
+}
+
+// report gathers the standups of the last days, today included
+func (standup *Standup) report(days int) (standupMap, error) {
+	entries, err := standup.store.Range(getStandupDate(1-days), getStandupDate(TODAY))
+	if err != nil {
+		return nil, err
+	}
+
+	sm := make(standupMap)
+	for key, data := range entries {
+		sm[key.date] = append(sm[key.date], standupUser{standup.userByEmail(key.email), data})
+	}
+	for _, users := range sm {
+		sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	}
+
+	return sm, nil
+}
+
+// userByEmail finds the user of an entry, keeping the email or ID as a
+// name when they're gone
+func (standup *Standup) userByEmail(email string) *slack.User {
+	if user, ok := standup.bot.Users.Find(email); ok {
+		if user.Profile.Email == "" {
+			user.Profile.Email = user.ID
+		}
+		return &user
+	}
+	return &slack.User{Name: email, Profile: slack.UserProfile{Email: email}}
+}
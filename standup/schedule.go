@@ -0,0 +1,437 @@
+package standup
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// scheduleInterval is how often the standups are checked for questions,
+// reminders and summaries to send
+const scheduleInterval = time.Minute
+
+const dateFormat = "2006-01-02"
+
+func (standup *Standup) scheduleLoop() {
+	for {
+		standup.tick(time.Now())
+		time.Sleep(scheduleInterval)
+	}
+}
+
+// teamConfigs returns the definitions of the teams of the config, then
+// those defined in chat, unless they have the same name
+func (standup *Standup) teamConfigs() []TeamConfig {
+	confs := append([]TeamConfig(nil), standup.conf.Teams...)
+
+	stored, err := standup.store.Teams()
+	if err != nil {
+		standup.bot.ReportError("standup", err)
+	}
+	for _, conf := range stored {
+		if standup.configTeam(conf.Name) {
+			continue
+		}
+		confs = append(confs, conf)
+	}
+
+	return confs
+}
+
+// configTeam tells whether a team is defined in the config
+func (standup *Standup) configTeam(name string) bool {
+	for _, conf := range standup.conf.Teams {
+		if strings.EqualFold(conf.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// teams resolves the definitions of the teams. Those which can't be are
+// left out, and logged once until they change.
+func (standup *Standup) teams() []*Team {
+	var teams []*Team
+	for _, conf := range standup.teamConfigs() {
+		team, err := newTeam(standup.bot, standup.conf, conf)
+		if err != nil {
+			if standup.teamErrors[conf.Name] != err.Error() {
+				standup.bot.Logging.Logger.WithError(err).Error("Standup: team left out")
+				standup.teamErrors[conf.Name] = err.Error()
+			}
+			continue
+		}
+		delete(standup.teamErrors, conf.Name)
+		teams = append(teams, team)
+	}
+	return teams
+}
+
+// team returns the team with the given name, if it's still there
+func (standup *Standup) team(name string) *Team {
+	for _, team := range standup.teams() {
+		if team.Name == name {
+			return team
+		}
+	}
+	return nil
+}
+
+// tick starts the standups due, reminds the members late to answer and
+// posts the summaries due
+func (standup *Standup) tick(now time.Time) {
+	standup.lock.Lock()
+	defer standup.lock.Unlock()
+
+	for _, team := range standup.teams() {
+		for _, member := range team.Members {
+			if err := standup.askMember(team, member, now); err != nil {
+				standup.bot.ReportError("standup", err)
+			}
+		}
+
+		if err := standup.summarize(team, now); err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+	}
+
+	if settings := standup.blockerSettings(); settings != nil {
+		if err := standup.escalateBlockers(settings, now); err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+		if err := standup.summarizeBlockers(settings, now); err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+	}
+}
+
+// askMember starts the standup of a member once it's time in their
+// timezone, or reminds them of the question waiting for an answer
+func (standup *Standup) askMember(team *Team, member string, now time.Time) error {
+	local := now.In(standup.memberLocation(member, team.Location))
+	if !team.workday(local) {
+		return nil
+	}
+	start := team.startOn(local)
+	if local.Before(start) {
+		return nil
+	}
+	date := local.Format(dateFormat)
+
+	session, err := standup.store.Session(team.Name, date, member)
+	if err != nil {
+		return err
+	}
+	locale := standup.userLocale(member)
+
+	if session == nil {
+		// Too late for today, like after a restart
+		if local.Sub(start) >= team.SummaryAfter {
+			return nil
+		}
+
+		pending, err := standup.store.Pending(member)
+		if err != nil {
+			return err
+		}
+		for _, p := range pending {
+			// One standup at a time, for members of several teams
+			if p.Date == date {
+				return nil
+			}
+
+			p.Expired = true
+			if err := standup.store.PutSession(p); err != nil {
+				return err
+			}
+		}
+
+		session = &Session{Team: team.Name, Date: date, User: member, StartedAt: now}
+		if err := standup.store.PutSession(session); err != nil {
+			return err
+		}
+		if err := standup.carryForward(member, date, member, true); err != nil {
+			return err
+		}
+
+		text := standup.bot.T(locale, "standup.dm.intro", team.Name, local.Format("Monday"))
+		standup.sendPrivate(member, text+"\n"+standup.questionText(locale, team.Questions[0]))
+		return nil
+	}
+
+	if session.Done() {
+		return nil
+	}
+	// The team has fewer questions since, none is left to ask
+	if len(session.Answers) >= len(team.Questions) {
+		return standup.finish(team, session, now)
+	}
+	if session.Reminders >= team.Reminders {
+		return nil
+	}
+	last := session.StartedAt
+	if session.RemindedAt.After(last) {
+		last = session.RemindedAt
+	}
+	if now.Sub(last) < team.RemindAfter {
+		return nil
+	}
+
+	session.Reminders++
+	session.RemindedAt = now
+	if err := standup.store.PutSession(session); err != nil {
+		return err
+	}
+
+	text := standup.bot.T(locale, "standup.dm.reminder", team.Name)
+	standup.sendPrivate(member, text+"\n"+standup.questionText(locale, team.Questions[len(session.Answers)]))
+	return nil
+}
+
+// handleAnswer takes a private message as the answer to the question
+// waiting in the oldest unfinished standup of its author, if any
+func (standup *Standup) handleAnswer(listen *bawt.Listener, msg *bawt.Message) {
+	// Replies in threads are about blockers
+	if msg.FromUser == nil || msg.IsEdit || strings.HasPrefix(msg.Text, "!") || msg.ThreadTimestamp != "" {
+		return
+	}
+
+	standup.lock.Lock()
+	defer standup.lock.Unlock()
+
+	if err := standup.answer(msg.FromUser.ID, msg.Text, time.Now()); err != nil {
+		standup.bot.ReportError("standup", err)
+	}
+}
+
+func (standup *Standup) answer(user, text string, now time.Time) error {
+	pending, err := standup.store.Pending(user)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	session := pending[0]
+	team := standup.team(session.Team)
+	if team == nil {
+		return nil
+	}
+	locale := standup.userLocale(user)
+
+	session.Answers = append(session.Answers, strings.TrimSpace(text))
+	if len(session.Answers) < len(team.Questions) {
+		if err := standup.store.PutSession(session); err != nil {
+			return err
+		}
+		standup.sendPrivate(user, standup.questionText(locale, team.Questions[len(session.Answers)]))
+		return nil
+	}
+
+	return standup.finish(team, session, now)
+}
+
+// finish ends a standup once every question is answered, thanks the
+// member and posts their answers when the summary is gone already
+func (standup *Standup) finish(team *Team, session *Session, now time.Time) error {
+	user := session.User
+	locale := standup.userLocale(user)
+
+	session.DoneAt = now
+	if err := standup.store.PutSession(session); err != nil {
+		return err
+	}
+	if err := standup.storeSession(team, session); err != nil {
+		return err
+	}
+	standup.sendPrivate(user, standup.bot.T(locale, "standup.dm.thanks", team.Channel))
+
+	// The summary is gone already, this one follows it
+	if standup.store.Summarized(team.Name, session.Date) {
+		channelLocale := standup.channelLocale(team.Channel)
+		text := standup.bot.T(channelLocale, "standup.summary.late", user, team.Name)
+		standup.send(team.Channel, text+"\n"+formatAnswers(standup.memberAnswers(channelLocale, team, session)))
+	}
+
+	return nil
+}
+
+// storeSession saves the answers of a finished standup as the entry of
+// the member for the day, like the chat sections
+func (standup *Standup) storeSession(team *Team, session *Session) error {
+	day, err := time.ParseInLocation(dateFormat, session.Date, time.Local)
+	if err != nil {
+		return err
+	}
+
+	user, ok := standup.bot.Users.Get(session.User)
+	if !ok {
+		user.ID = session.User
+	}
+	key := userKey(&user, timeToStandupDate(day))
+
+	data, _ := standup.store.Get(key)
+	for i, question := range team.Questions {
+		data.set(question.ID, session.Answers[i], "")
+		if question.ID == "blocking" {
+			if _, err := standup.trackBlocker(session.User, session.Team, session.Answers[i], session.Date, sessionRef(session), "", session.DoneAt); err != nil {
+				return err
+			}
+		}
+	}
+	data.LastUpdate = session.DoneAt
+
+	return standup.store.Put(key, data)
+}
+
+// summarize posts the answers of the day to the team's channel, once
+func (standup *Standup) summarize(team *Team, now time.Time) error {
+	local := now.In(team.Location)
+	if !team.workday(local) || local.Before(team.startOn(local).Add(team.SummaryAfter)) {
+		return nil
+	}
+	date := local.Format(dateFormat)
+	if standup.store.Summarized(team.Name, date) {
+		return nil
+	}
+
+	locale := standup.channelLocale(team.Channel)
+	data := summaryData{Team: team.Name, Date: local.Format("Mon 2006-01-02")}
+
+	for _, member := range team.Members {
+		session, err := standup.store.Session(team.Name, date, member)
+		if err != nil {
+			return err
+		}
+		if session == nil || !session.Done() {
+			data.Missing = append(data.Missing, member)
+			continue
+		}
+		data.Members = append(data.Members, standup.memberAnswers(locale, team, session))
+	}
+
+	text, err := standup.renderSummary(locale, team, data)
+	if err != nil {
+		return err
+	}
+
+	standup.send(team.Channel, text)
+	return standup.store.SetSummarized(team.Name, date)
+}
+
+// summaryData is given to the `summary_format` templates of the teams
+type summaryData struct {
+	Team    string
+	Date    string
+	Members []memberAnswers
+	// Missing are the Slack IDs of those who didn't answer
+	Missing []string
+}
+
+type memberAnswers struct {
+	// User is the Slack ID of the member
+	User    string
+	Answers []answer
+}
+
+type answer struct {
+	ID    string
+	Title string
+	Text  string
+}
+
+func (standup *Standup) memberAnswers(locale string, team *Team, session *Session) memberAnswers {
+	m := memberAnswers{User: session.User}
+	for i, question := range team.Questions {
+		if i < len(session.Answers) {
+			m.Answers = append(m.Answers, answer{
+				ID:    question.ID,
+				Title: standup.sectionTitle(locale, question),
+				Text:  session.Answers[i],
+			})
+		}
+	}
+	return m
+}
+
+// renderSummary renders the summary with the format of the team, or the
+// default one
+func (standup *Standup) renderSummary(locale string, team *Team, data summaryData) (string, error) {
+	if team.Summary != nil {
+		var buf bytes.Buffer
+		err := team.Summary.Execute(&buf, data)
+		return strings.TrimSpace(buf.String()), err
+	}
+
+	lines := []string{standup.bot.T(locale, "standup.summary", data.Team, data.Date)}
+	for _, m := range data.Members {
+		lines = append(lines, "*<@"+m.User+">*", formatAnswers(m))
+	}
+
+	var missing []string
+	for _, member := range data.Missing {
+		missing = append(missing, "<@"+member+">")
+	}
+	switch {
+	case len(data.Members) == 0:
+		lines = append(lines, standup.bot.T(locale, "standup.summary.nobody"))
+	case len(missing) != 0:
+		lines = append(lines, standup.bot.T(locale, "standup.summary.missing", len(missing), strings.Join(missing, ", ")))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatAnswers quotes the answers of a member, after their section
+func formatAnswers(m memberAnswers) string {
+	var lines []string
+	for _, a := range m.Answers {
+		lines = append(lines, "> *"+a.Title+"* "+a.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (standup *Standup) questionText(locale string, q Question) string {
+	if q.Text != "" {
+		return q.Text
+	}
+	return standup.bot.T(locale, "standup.question."+q.ID)
+}
+
+// sectionTitle names the section of a question in the summary: the
+// default ones are translated, the others keep their ID
+func (standup *Standup) sectionTitle(locale string, q Question) string {
+	for _, d := range defaultQuestions {
+		if d.ID == q.ID {
+			return standup.bot.T(locale, "standup.section."+q.ID)
+		}
+	}
+	return strings.ToUpper(q.ID[:1]) + q.ID[1:] + ":"
+}
+
+// memberLocation is the timezone of a member as set in Slack, or def
+func (standup *Standup) memberLocation(member string, def *time.Location) *time.Location {
+	user, ok := standup.bot.Users.Get(member)
+	if !ok || user.TZ == "" {
+		return def
+	}
+	loc, err := time.LoadLocation(user.TZ)
+	if err != nil {
+		return def
+	}
+	return loc
+}
+
+func (standup *Standup) userLocale(id string) string {
+	if user, ok := standup.bot.Users.Get(id); ok {
+		return standup.bot.LocaleFor(&user, nil)
+	}
+	return standup.bot.LocaleFor(nil, nil)
+}
+
+func (standup *Standup) channelLocale(id string) string {
+	if channel, ok := standup.bot.Channels.Get(id); ok {
+		return standup.bot.LocaleFor(nil, &channel)
+	}
+	return standup.bot.LocaleFor(nil, nil)
+}
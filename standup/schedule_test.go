@@ -0,0 +1,258 @@
+package standup
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func at(s string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", s)
+	return t
+}
+
+func newTestTeam(standup *Standup) {
+	standup.conf = Config{
+		Timezone: "UTC",
+		Holidays: []string{"2026-10-20"},
+		Teams: []TeamConfig{{
+			Name:        "backend",
+			Channel:     "#backend",
+			Members:     []string{"A", "@B"},
+			Time:        "09:30",
+			RemindAfter: "1h",
+			SummaryTime: "12:00",
+		}},
+	}
+}
+
+func TestScheduledStandup(t *testing.T) {
+	standup, out := newTestStandup(t)
+	newTestTeam(standup)
+
+	// Monday, too early
+	standup.tick(at("2026-10-19 09:29"))
+	if len(*out) != 0 {
+		t.Fatalf("expected nothing before 09:30, got %v", *out)
+	}
+
+	// B is in New York, where it's 05:30
+	standup.tick(at("2026-10-19 09:30"))
+	if len(*out) != 1 || (*out)[0].To != "U1" || !strings.Contains((*out)[0].Text, "What did you do yesterday?") {
+		t.Fatalf("expected A to be asked the first question, got %v", *out)
+	}
+
+	for _, answer := range []string{"coded", "tested", "nothing"} {
+		if err := standup.answer("U1", answer, at("2026-10-19 09:35")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*out) != 4 || !strings.Contains((*out)[3].Text, "Thanks") {
+		t.Fatalf("expected the questions and thanks, got %v", *out)
+	}
+	data, _ := standup.store.Get(standupKey{date: timeToStandupDate(at("2026-10-19 00:00")), email: "A@test.ly"})
+	if data.Yesterday != "coded" || data.Today != "tested" || data.Blocking != "nothing" {
+		t.Errorf("expected the answers to be stored, got %v", data)
+	}
+
+	// Answers without a standup are ignored
+	standup.answer("U1", "hello", at("2026-10-19 09:40"))
+	if len(*out) != 4 {
+		t.Errorf("expected no reply, got %v", (*out)[4:])
+	}
+
+	*out = nil
+	standup.tick(at("2026-10-19 13:30"))
+	if len(*out) != 2 {
+		t.Fatalf("expected B's question and the summary, got %v", *out)
+	}
+	if (*out)[0].To != "U2" {
+		t.Errorf("expected B to be asked at 09:30 in New York, got %v", (*out)[0])
+	}
+	summary := (*out)[1]
+	if summary.To != "C1" || summary.Text != strings.Join([]string{
+		"*backend standup*, Mon 2026-10-19",
+		"*<@U1>*",
+		"> *Yesterday:* coded",
+		"> *Today:* tested",
+		"> *Blocking:* nothing",
+		"No answer from <@U2>",
+	}, "\n") {
+		t.Errorf("unexpected summary %v", summary)
+	}
+
+	// A late answer follows the summary
+	*out = nil
+	for _, answer := range []string{"a", "b", "c"} {
+		standup.answer("U2", answer, at("2026-10-19 13:40"))
+	}
+	if len(*out) != 4 || (*out)[3].To != "C1" || !strings.HasPrefix((*out)[3].Text, "<@U2> answered the *backend* standup late:") {
+		t.Errorf("expected the late answers in the channel, got %v", *out)
+	}
+
+	// No standup on holidays and weekends
+	*out = nil
+	standup.tick(at("2026-10-20 10:00"))
+	standup.tick(at("2026-10-24 10:00"))
+	standup.tick(at("2026-10-24 15:00"))
+	if len(*out) != 0 {
+		t.Errorf("expected nothing on holidays and weekends, got %v", *out)
+	}
+}
+
+func TestScheduledStandupReminders(t *testing.T) {
+	standup, out := newTestStandup(t)
+	newTestTeam(standup)
+
+	standup.tick(at("2026-10-21 09:30"))
+	standup.answer("U1", "coded", at("2026-10-21 09:31"))
+	*out = nil
+
+	for _, now := range []string{"2026-10-21 10:00", "2026-10-21 10:31", "2026-10-21 11:00", "2026-10-21 11:32", "2026-10-21 11:59"} {
+		standup.tick(at(now))
+	}
+	if len(*out) != 2 {
+		t.Fatalf("expected 2 reminders, got %v", *out)
+	}
+	for _, s := range *out {
+		if s.To != "U1" || !strings.Contains(s.Text, "What will you do today?") {
+			t.Errorf("expected a reminder of the second question, got %v", s)
+		}
+	}
+
+	// The unfinished standup expires with the next one
+	*out = nil
+	standup.tick(at("2026-10-22 09:30"))
+	if len(*out) != 1 || !strings.Contains((*out)[0].Text, "What did you do yesterday?") {
+		t.Errorf("expected a new standup, got %v", *out)
+	}
+	old, _ := standup.store.Session("backend", "2026-10-21", "U1")
+	if old == nil || !old.Expired {
+		t.Errorf("expected the old standup to expire, got %v", old)
+	}
+}
+
+func TestQuestionsRemovedDuringStandup(t *testing.T) {
+	standup, out := newTestStandup(t)
+	newTestTeam(standup)
+	standup.conf.Teams[0].Questions = []Question{{ID: "done"}, {ID: "next"}, {ID: "risks"}}
+
+	standup.tick(at("2026-10-21 09:30"))
+	standup.answer("U1", "mockups", at("2026-10-21 09:31"))
+	standup.answer("U1", "review", at("2026-10-21 09:32"))
+
+	// The last question is gone before A answers it
+	standup.conf.Teams[0].Questions = []Question{{ID: "done"}}
+	*out = nil
+	standup.tick(at("2026-10-21 10:40"))
+	if len(*out) != 1 || (*out)[0].To != "U1" || !strings.Contains((*out)[0].Text, "Thanks") {
+		t.Fatalf("expected the standup to finish, got %v", *out)
+	}
+
+	session, _ := standup.store.Session("backend", "2026-10-21", "U1")
+	if session == nil || !session.Done() {
+		t.Errorf("expected the standup to be done, got %v", session)
+	}
+	data, _ := standup.store.Get(standupKey{date: timeToStandupDate(at("2026-10-21 00:00")), email: "A@test.ly"})
+	if data.Answers["done"] != "mockups" {
+		t.Errorf("expected the answers to be stored, got %v", data)
+	}
+}
+
+func TestNewTeam(t *testing.T) {
+	standup, _ := newTestStandup(t)
+
+	tests := []struct {
+		name string
+		conf TeamConfig
+		err  string
+	}{
+		{name: "defaults", conf: TeamConfig{Name: "a", Channel: "C1"}},
+		{name: "no name", conf: TeamConfig{Channel: "C1"}, err: "needs a name"},
+		{name: "channel", conf: TeamConfig{Name: "a", Channel: "#nope"}, err: "unknown channel"},
+		{name: "member", conf: TeamConfig{Name: "a", Channel: "C1", Members: []string{"Z"}}, err: "unknown member"},
+		{name: "time", conf: TeamConfig{Name: "a", Channel: "C1", Time: "9h"}, err: "invalid time"},
+		{name: "summary", conf: TeamConfig{Name: "a", Channel: "C1", SummaryTime: "08:00"}, err: "must come after"},
+		{name: "question", conf: TeamConfig{Name: "a", Channel: "C1", Questions: []Question{{ID: "Done"}}}, err: "invalid question ID"},
+		{name: "twice", conf: TeamConfig{Name: "a", Channel: "C1", Questions: []Question{{ID: "done"}, {ID: "done"}}}, err: "there twice"},
+		{name: "days", conf: TeamConfig{Name: "a", Channel: "C1", Days: []string{"someday"}}, err: "invalid day"},
+		{name: "format", conf: TeamConfig{Name: "a", Channel: "C1", SummaryFormat: "{{.Nope"}, err: "invalid summary_format"},
+	}
+
+	for _, test := range tests {
+		team, err := newTeam(standup.bot, Config{}, test.conf)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", test.name, err)
+			} else if team.Hour != 9 || team.Min != 30 || team.SummaryAfter != 3*time.Hour || team.Reminders != 2 {
+				t.Errorf("%s: unexpected defaults %+v", test.name, team)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error with %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestCustomQuestions(t *testing.T) {
+	standup, out := newTestStandup(t)
+	standup.conf = Config{
+		Timezone: "UTC",
+		Teams: []TeamConfig{{
+			Name:          "design",
+			Channel:       "C1",
+			Members:       []string{"A"},
+			Days:          []string{"mon", "Wednesday"},
+			Questions:     []Question{{ID: "done", Text: "What did you finish?"}, {ID: "next", Text: "What's next?"}},
+			SummaryFormat: "{{range .Members}}<@{{.User}}>:{{range .Answers}} {{.ID}}={{.Text}}{{end}}{{end}}",
+		}},
+	}
+
+	// Tuesday isn't a working day of the team
+	standup.tick(at("2026-10-20 09:30"))
+	if len(*out) != 0 {
+		t.Fatalf("expected nothing on Tuesday, got %v", *out)
+	}
+
+	standup.tick(at("2026-10-21 09:30"))
+	standup.answer("U1", "mockups", at("2026-10-21 09:31"))
+	standup.answer("U1", "review", at("2026-10-21 09:32"))
+	if len(*out) != 3 || (*out)[0].Text[strings.LastIndex((*out)[0].Text, "\n")+1:] != "What did you finish?" || (*out)[1].Text != "What's next?" {
+		t.Fatalf("expected the questions of the team, got %v", *out)
+	}
+
+	data, _ := standup.store.Get(standupKey{date: timeToStandupDate(at("2026-10-21 00:00")), email: "A@test.ly"})
+	if data.Answers["done"] != "mockups" || data.Answers["next"] != "review" {
+		t.Errorf("expected the answers to be stored, got %v", data)
+	}
+
+	*out = nil
+	standup.tick(at("2026-10-21 12:30"))
+	if len(*out) != 1 || (*out)[0].Text != "<@U1>: done=mockups next=review" {
+		t.Errorf("expected the summary of the template, got %v", *out)
+	}
+}
+
+func TestSectionsOfTeams(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	standup.conf = Config{Teams: []TeamConfig{{
+		Name:      "design",
+		Channel:   "C1",
+		Members:   []string{"A"},
+		Questions: []Question{{ID: "done"}, {ID: "next"}},
+	}}}
+
+	if ids := standup.sectionsFor("U1"); strings.Join(ids, " ") != "done next" {
+		t.Errorf("expected the questions of the team, got %v", ids)
+	}
+	if ids := standup.sectionsFor("U2"); strings.Join(ids, " ") != "yesterday today blocking" {
+		t.Errorf("expected the default questions, got %v", ids)
+	}
+
+	text := "!done mockups\n!todayish not a section\n!blocking nothing"
+	sections := extractSectionAndText(text, standup.parser().FindAllStringSubmatchIndex(text, -1))
+	if len(sections) != 2 || sections[0].name != "done" || sections[0].text != "mockups\n!todayish not a section" || sections[1].name != "blocking" {
+		t.Errorf("unexpected sections %v", sections)
+	}
+}
@@ -1,11 +1,38 @@
 // Package standup is a plugin for bawt that facilitates standups for teams
 package standup
 
-import "github.com/gopherworks/bawt"
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+)
 
 type Standup struct {
 	bot            *bawt.Bot
+	store          Store
+	conf           Config
 	sectionUpdates chan sectionUpdate
+	once           sync.Once
+
+	// lock serializes the scheduled standups and their answers
+	lock sync.Mutex
+	// teamErrors keeps the last error of the teams left out
+	teamErrors map[string]string
+	// parserRegexp matches the sections of parserKey, the question IDs
+	parserKey    string
+	parserRegexp *regexp.Regexp
+	// blockerError is the last error of the blockers config
+	blockerError string
+
+	// send and sendPrivate post to a channel and to a user, by ID
+	send        func(channel, text string)
+	sendPrivate func(user, text string)
+	// post sends to a channel, or to a user when private is set, then
+	// calls posted with the lock held once Slack has the message
+	post func(to, text string, private bool, posted func(channel, ts string))
 }
 
 const TODAY = 0
@@ -16,27 +43,212 @@ func init() {
 }
 
 func (standup *Standup) InitPlugin(bot *bawt.Bot) {
-	standup.bot = bot
+	standup.setup(bot)
 	standup.sectionUpdates = make(chan sectionUpdate, 15)
 
 	go standup.manageUpdatesInteraction()
+	go standup.scheduleLoop()
+	bot.Events.OnReaction(standup.onReaction)
 
 	bot.Listen(&bawt.Listener{
 		MessageHandlerFunc: standup.ChatHandler,
+		ListenForEdits:     true,
+		MainWorkspaceOnly:  true,
 		Name:               "Standup",
 		Description:        "Provides an assistant for running stand up over chat",
+		Commands: []bawt.Command{
+			{
+				Usage:    "!yesterday <what you did>",
+				HelpText: "Tells what you did yesterday, along with `!today` and `!blocking` in the same message or not. Editing the message updates your standup",
+			},
+			{
+				Usage:    "!today <what you'll do>",
+				HelpText: "Tells what you'll do today",
+			},
+			{
+				Usage:    "!blocking <what blocks you>",
+				HelpText: "Tells what blocks you",
+			},
+		},
+	})
+
+	bot.Listen(&bawt.Listener{
+		MessageHandlerFunc: standup.handleAnswer,
+		PrivateOnly:        true,
+		MainWorkspaceOnly:  true,
+		Name:               "Standup answers",
+		Description:        "Collects the answers to the standup questions asked privately to the teams of the `standup` config",
+	})
+
+	bot.Listen(&bawt.Listener{
+		Matches:            regexp.MustCompile(`^!standup\b.*`),
+		MessageHandlerFunc: standup.handleStandup,
+		MainWorkspaceOnly:  true,
+		Name:               "Standup reports",
+		Slug:               "standup",
+		Description:        "Reports on the standups given",
+		Commands: []bawt.Command{
+			{
+				Usage:    "!standup report [@user] [last N days]",
+				HelpText: "Shows the standups of everyone, or of a user, during the last 7 days or N days",
+			},
+			{
+				Usage:    "!standup blockers [@user]",
+				HelpText: "Lists the open blockers, of everyone or of a user",
+			},
+			{
+				Usage:    "!standup resolve <id> [note]",
+				HelpText: "Resolves a blocker, like reacting to a message about it or replying `resolved` in its thread",
+			},
+			{
+				Usage:    "!standup team list|create|delete|add|remove|questions|set",
+				HelpText: "Manages the teams asked the standup questions privately, besides those of the config",
+			},
+		},
+	})
+}
+
+// setup loads the config and opens the store, for the chat and the web
+// alike, whichever comes first
+func (standup *Standup) setup(bot *bawt.Bot) {
+	standup.once.Do(func() {
+		standup.bot = bot
+
+		err := bot.DB.Update(createBuckets)
+		if err != nil {
+			bot.Logging.Logger.Fatalln("Couldn't create the `standup` bucket")
+		}
+		standup.store = &boltStore{db: bot.DB}
+
+		var conf struct {
+			Standup Config
+		}
+		bot.LoadConfig(&conf)
+		standup.conf = conf.Standup
+		standup.teamErrors = map[string]string{}
+
+		standup.send = func(channel, text string) {
+			bot.SendOutgoingMessage(text, channel)
+		}
+		standup.sendPrivate = func(user, text string) {
+			bot.SendPrivateMessage(user, text)
+		}
+		standup.post = func(to, text string, private bool, posted func(channel, ts string)) {
+			var reply *bawt.Reply
+			if private {
+				reply = bot.SendPrivateMessage(to, text)
+			} else {
+				reply = bot.SendOutgoingMessage(text, to)
+			}
+			if reply == nil {
+				return
+			}
+			reply.OnAck(func(ack *slack.AckMessage) {
+				standup.lock.Lock()
+				defer standup.lock.Unlock()
+				posted(reply.Channel, ack.Timestamp)
+			})
+		}
 	})
 }
 
 func (standup *Standup) ChatHandler(listen *bawt.Listener, msg *bawt.Message) {
-	res := sectionRegexp.FindAllStringSubmatchIndex(msg.Text, -1)
-	if res != nil {
-		for _, section := range extractSectionAndText(msg.Text, res) {
-			standup.TriggerReminders(msg, section.name)
-			// err := standup.StoreLine(msg, section.name, section.text)
-			// if err != nil {
-			// 	log.Println(err)
-			// }
+	if msg.FromUser == nil {
+		return
+	}
+
+	standup.lock.Lock()
+	parser := standup.parser()
+	standup.lock.Unlock()
+
+	res := parser.FindAllStringSubmatchIndex(msg.Text, -1)
+	if msg.IsEdit {
+		// An edit can remove sections, so it's handled even without any
+		sections := extractSectionAndText(msg.Text, res)
+		standup.updateFromEdit(msg, sections)
+		if msg.SubMessage != nil {
+			standup.lock.Lock()
+			standup.trackChatBlocker(msg, sections)
+			standup.lock.Unlock()
+		}
+		return
+	}
+
+	if res == nil {
+		standup.lock.Lock()
+		b, err := standup.resolveFromReply(msg)
+		standup.lock.Unlock()
+		if err != nil {
+			standup.bot.ReportError("standup", err)
+		} else if b != nil {
+			msg.ReplyT("standup.blocker.resolved", b.ID, b.Text, msg.FromUser.ID)
+		}
+		return
+	}
+
+	sections := extractSectionAndText(msg.Text, res)
+	for _, section := range sections {
+		standup.TriggerReminders(msg, section.name)
+		err := standup.StoreLine(msg, section.name, section.text)
+		if err != nil {
+			standup.bot.ReportError("standup", err)
+		}
+	}
+
+	standup.lock.Lock()
+	standup.trackChatBlocker(msg, sections)
+	standup.lock.Unlock()
+}
+
+// userKey is the key of the entry of a user on a day. Users are known by
+// email, or by ID when their email is hidden.
+func userKey(user *slack.User, date standupDate) standupKey {
+	email := user.Profile.Email
+	if email == "" {
+		email = user.ID
+	}
+	return standupKey{date: date, email: email}
+}
+
+// StoreLine saves a section of the standup of the author of a message,
+// for the day the message was sent
+func (standup *Standup) StoreLine(msg *bawt.Message, section, text string) error {
+	key := userKey(msg.FromUser, timeToStandupDate(timestampToTime(msg.Timestamp)))
+
+	data, _ := standup.store.Get(key)
+	data.set(section, text, msg.Timestamp)
+	data.LastUpdate = time.Now()
+
+	return standup.store.Put(key, data)
+}
+
+// updateFromEdit saves the sections of an edited message, and clears
+// those it no longer has
+func (standup *Standup) updateFromEdit(msg *bawt.Message, sections []sectionMatch) {
+	if msg.SubMessage == nil {
+		return
+	}
+	ts := msg.SubMessage.Timestamp
+	key := userKey(msg.FromUser, timeToStandupDate(timestampToTime(ts)))
+
+	data, found := standup.store.Get(key)
+	if !found && len(sections) == 0 {
+		return
+	}
+
+	given := map[string]bool{}
+	for _, section := range sections {
+		data.set(section.name, section.text, ts)
+		given[section.name] = true
+	}
+	for section, source := range data.Sources {
+		if source == ts && !given[section] {
+			data.set(section, "", ts)
 		}
 	}
+	data.LastUpdate = time.Now()
+
+	if err := standup.store.Put(key, data); err != nil {
+		standup.bot.ReportError("standup", err)
+	}
 }
@@ -12,12 +12,77 @@ type standupData struct {
 	Today      string
 	Blocking   string
 	LastUpdate time.Time
+
+	// Answers has the sections of the teams' own questions
+	Answers map[string]string `json:",omitempty"`
+
+	// Sources maps each section to the timestamp of the message it was
+	// given in, to follow the edits
+	Sources map[string]string `json:",omitempty"`
+}
+
+// set fills a section from the text of a message
+func (sd *standupData) set(section, text, ts string) {
+	switch strings.ToLower(section) {
+	case "yesterday":
+		sd.Yesterday = text
+	case "today":
+		sd.Today = text
+	case "blocking":
+		sd.Blocking = text
+	default:
+		if sd.Answers == nil {
+			sd.Answers = map[string]string{}
+		}
+		if text == "" {
+			delete(sd.Answers, strings.ToLower(section))
+		} else {
+			sd.Answers[strings.ToLower(section)] = text
+		}
+	}
+
+	if sd.Sources == nil {
+		sd.Sources = map[string]string{}
+	}
+	if text == "" {
+		delete(sd.Sources, strings.ToLower(section))
+	} else {
+		sd.Sources[strings.ToLower(section)] = ts
+	}
+}
+
+// sections returns the sections given, by name
+func (sd standupData) sections() map[string]string {
+	out := map[string]string{}
+	for section, text := range map[string]string{"yesterday": sd.Yesterday, "today": sd.Today, "blocking": sd.Blocking} {
+		if text != "" {
+			out[section] = text
+		}
+	}
+	for section, text := range sd.Answers {
+		out[section] = text
+	}
+	return out
 }
 
 func (sd standupData) String() string {
-	str := fmt.Sprintf("Yesterday: %s\n", sd.Yesterday)
-	str += fmt.Sprintf("Today: %s\n", sd.Today)
-	str += fmt.Sprintf("Blocking: %s\n", sd.Blocking)
+	// Teams with their own questions may have none of the usual sections
+	str := ""
+	if sd.Yesterday != "" || sd.Today != "" || sd.Blocking != "" || len(sd.Answers) == 0 {
+		str += fmt.Sprintf("Yesterday: %s\n", sd.Yesterday)
+		str += fmt.Sprintf("Today: %s\n", sd.Today)
+		str += fmt.Sprintf("Blocking: %s\n", sd.Blocking)
+	}
+
+	sections := make([]string, 0, len(sd.Answers))
+	for section := range sd.Answers {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		str += fmt.Sprintf("%s: %s\n", strings.ToUpper(section[:1])+section[1:], sd.Answers[section])
+	}
+
 	return str
 }
 
@@ -35,7 +100,9 @@ func (sm standupMap) Keys() standupDates {
 func (sm standupMap) filterByEmail(email string) standupMap {
 	fsm := make(standupMap)
 	for date, users := range sm {
-		fsm[date] = users.filterByEmail(email)
+		if fusers := users.filterByEmail(email); len(fusers) != 0 {
+			fsm[date] = fusers
+		}
 	}
 	return fsm
 }
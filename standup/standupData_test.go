@@ -26,6 +26,20 @@ Blocking: c
 	}
 }
 
+func TestStandupDataStringAnswers(t *testing.T) {
+	data := standupData{}
+	data.set("next", "b", "")
+	data.set("done", "a", "")
+	if str := data.String(); str != "Done: a\nNext: b\n" {
+		t.Errorf("expected the custom sections sorted, got %q", str)
+	}
+
+	data.set("today", "c", "")
+	if str := data.String(); str != "Yesterday: \nToday: c\nBlocking: \nDone: a\nNext: b\n" {
+		t.Errorf("expected the default sections first, got %q", str)
+	}
+}
+
 func getTestStandupMap() standupMap {
 
 	sm := make(standupMap)
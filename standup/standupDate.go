@@ -23,6 +23,23 @@ func getStandupDate(daysFromToday int) standupDate {
 	}
 }
 
+func timeToStandupDate(t time.Time) standupDate {
+	return standupDate{
+		year:  t.Year(),
+		month: t.Month(),
+		day:   t.Day(),
+	}
+}
+
+// timestampToTime parses the timestamp of a Slack message
+func timestampToTime(ts string) time.Time {
+	secs, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(int64(secs), 0)
+}
+
 func unixToStandupDate(unix int64) standupDate {
 	d := time.Unix(unix, 0).UTC()
 	return standupDate{
@@ -46,6 +63,11 @@ func (sd standupDate) String() string {
 	return strconv.Itoa(sd.year) + "-" + sd.month.String() + "-" + strconv.Itoa(sd.day)
 }
 
+// format returns the date as YYYY-MM-DD
+func (sd standupDate) format() string {
+	return time.Date(sd.year, sd.month, sd.day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
 func (sd standupDate) Unix() int64 {
 	return time.Date(sd.year, sd.month, sd.day, 0, 0, 0, 0, time.Local).Unix()
 }
@@ -1,6 +1,12 @@
 package standup
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+)
 
 func TestRegexpMatch(t *testing.T) {
 	input := `!blocking this is good
@@ -20,3 +26,89 @@ func TestRegexpMatch(t *testing.T) {
 		t.Error("res[1].text should be 'thank you'")
 	}
 }
+
+func TestStoreLineAndEdit(t *testing.T) {
+	standup, _ := newTestStandup(t)
+
+	user, _ := standup.bot.Users.Get("U1")
+	msg := &bawt.Message{
+		Msg:      &slack.Msg{Text: "!yesterday coded\n!Today tested\n!blocking nothing", Timestamp: "1431950000.000100"},
+		FromUser: &user,
+	}
+	for _, section := range extractSectionAndText(msg.Text, sectionRegexp.FindAllStringSubmatchIndex(msg.Text, -1)) {
+		if err := standup.StoreLine(msg, section.name, section.text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	key := standupKey{date: timeToStandupDate(timestampToTime("1431950000.000100")), email: "A@test.ly"}
+	data, _ := standup.store.Get(key)
+	if data.Yesterday != "coded" || data.Today != "tested" || data.Blocking != "nothing" {
+		t.Errorf("unexpected entry %v", data)
+	}
+
+	// An edit updates the sections of the message, and drops the others
+	edit := &bawt.Message{
+		Msg:        &slack.Msg{Text: "!yesterday coded a lot\n!today tested"},
+		SubMessage: &slack.Msg{Timestamp: "1431950000.000100"},
+		FromUser:   &user,
+		IsEdit:     true,
+	}
+	standup.ChatHandler(nil, edit)
+
+	data, _ = standup.store.Get(key)
+	if data.Yesterday != "coded a lot" || data.Today != "tested" || data.Blocking != "" {
+		t.Errorf("unexpected entry after the edit %v", data)
+	}
+}
+
+func TestParseReportArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		user  string
+		days  int
+		ok    bool
+	}{
+		{input: "", days: 7, ok: true},
+		{input: "<@U1>", user: "<@U1>", days: 7, ok: true},
+		{input: "last 3 days", days: 3, ok: true},
+		{input: "@bob last 30 days", user: "@bob", days: 30, ok: true},
+		{input: "bob 2d", user: "bob", days: 2, ok: true},
+		{input: "bob last week", ok: false},
+		{input: "last 0 days", ok: false},
+	}
+
+	for _, test := range tests {
+		user, days, ok := parseReportArgs(strings.Fields(test.input))
+		if ok != test.ok || (ok && (user != test.user || days != test.days)) {
+			t.Errorf("%q: expected %q, %d, %v, got %q, %d, %v", test.input, test.user, test.days, test.ok, user, days, ok)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	standup, _ := newTestStandup(t)
+
+	today, yesterday := getStandupDate(TODAY), getStandupDate(-1)
+	standup.store.Put(standupKey{date: today, email: "B@test.ly"}, standupData{Today: "b"})
+	standup.store.Put(standupKey{date: today, email: "A@test.ly"}, standupData{Today: "a"})
+	standup.store.Put(standupKey{date: yesterday, email: "gone@test.ly"}, standupData{Today: "c"})
+	standup.store.Put(standupKey{date: getStandupDate(-10), email: "A@test.ly"}, standupData{Today: "old"})
+
+	sm, err := standup.report(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sm) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(sm))
+	}
+	if users := sm[today]; len(users) != 2 || users[0].Name != "A" || users[1].Name != "B" {
+		t.Errorf("expected A and B today, got %v", users)
+	}
+	if users := sm[yesterday]; len(users) != 1 || users[0].Name != "gone@test.ly" {
+		t.Errorf("expected the email of a gone user, got %v", users)
+	}
+	if fsm := sm.filterByEmail("A@test.ly"); len(fsm) != 1 {
+		t.Errorf("expected a single day for A, got %d", len(fsm))
+	}
+}
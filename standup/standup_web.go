@@ -1,34 +1,422 @@
 package standup
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gopherworks/bawt"
 	"github.com/gorilla/mux"
 )
 
+// defaultWebDays is the period of the web views, today included, and
+// maxWebDays the longest one a request can ask for
+const (
+	defaultWebDays = 30
+	maxWebDays     = 366
+)
+
+var (
+	errWebTeamNotFound = errors.New("no such team")
+	errWebForbidden    = errors.New("only GlobalAdmins and the members of a team can see its standups")
+)
+
+// webReport is what the dashboard shows of a period, and what's exported
+type webReport struct {
+	Team          string             `json:"team,omitempty"`
+	From          string             `json:"from"`
+	To            string             `json:"to"`
+	Entries       []webEntry         `json:"entries"`
+	Participation []webParticipation `json:"participation"`
+	Blockers      []webBlocker       `json:"blockers"`
+
+	// Sections orders the sections of the entries
+	Sections []string `json:"-"`
+}
+
 func (standup *Standup) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, pubRouter *mux.Router) {
-	// privRouter.HandleFunc("/plugins/standup.json", func(w http.ResponseWriter, r *http.Request) {
-	// 	data := struct {
-	// 		Users []*StandupUser
-	// 	}{
-	// 		Users: make([]*StandupUser, 0),
-	// 	}
-	// 	for _, value := range *standup.data {
-	// 		data.Users = append(data.Users, value)
-	// 	}
+	standup.setup(bot)
 
-	// 	w.Header().Set("Content-Type", "application/json")
+	privRouter.HandleFunc("/plugins/standup.json", standup.handleWebJSON).Methods("GET")
+	privRouter.HandleFunc("/plugins/standup.csv", standup.handleWebCSV).Methods("GET")
+	privRouter.HandleFunc("/plugins/standup", standup.handleWebDashboard).Methods("GET")
+	privRouter.HandleFunc("/plugins/standup/users/{user}", standup.handleWebUser).Methods("GET")
+}
 
-	// 	err := json.NewEncoder(w).Encode(data)
-	// 	if err != nil {
-	// 		webReportError(w, "Error encoding data", err)
-	// 	}
-	// })
+// webNow is the time in the global timezone
+func (standup *Standup) webNow() time.Time {
+	loc, err := standup.conf.location()
+	if err != nil {
+		loc = time.Local
+	}
+	return time.Now().In(loc)
+}
+
+// webFilterFor reads the `team`, `user`, `from`, `to` and `days` query
+// parameters, the last 30 days of everyone by default. GlobalAdmins see
+// every team, the others their own teams and their own history: their
+// first team by default.
+func (standup *Standup) webFilterFor(r *http.Request, now time.Time) (webFilter, error) {
+	viewer, err := standup.bot.WebServer.AuthenticatedUser(r)
+	if err != nil || viewer == nil {
+		return webFilter{}, errWebForbidden
+	}
+
+	today, _ := time.Parse(dateFormat, now.Format(dateFormat))
+	f := webFilter{To: today}
+
+	if to := r.FormValue("to"); to != "" {
+		t, err := time.Parse(dateFormat, to)
+		if err != nil {
+			return f, fmt.Errorf("invalid to %q, use YYYY-MM-DD", to)
+		}
+		f.To = t
+	}
+
+	days := defaultWebDays
+	if s := r.FormValue("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxWebDays {
+			return f, fmt.Errorf("invalid days %q, use 1 to %d", s, maxWebDays)
+		}
+		days = n
+	}
+	f.From = f.To.AddDate(0, 0, 1-days)
+
+	if from := r.FormValue("from"); from != "" {
+		t, err := time.Parse(dateFormat, from)
+		if err != nil {
+			return f, fmt.Errorf("invalid from %q, use YYYY-MM-DD", from)
+		}
+		f.From = t
+	}
+	if f.From.After(f.To) {
+		return f, errors.New("from must come before to")
+	}
+	if f.From.Before(f.To.AddDate(0, 0, 1-maxWebDays)) {
+		return f, fmt.Errorf("the period can't be longer than %d days", maxWebDays)
+	}
+
+	if name := r.FormValue("user"); name != "" {
+		// Users gone from Slack are still known by the email of their
+		// entries
+		f.Email = bawt.CleanUser(name)
+		if user, ok := standup.bot.Users.Find(f.Email); ok {
+			f.Email = userKey(&user, standupDate{}).email
+		}
+	}
+
+	admin := standup.isAdmin(viewer.ID)
+	own := f.Email != "" && strings.EqualFold(f.Email, userKey(viewer, standupDate{}).email)
+
+	standup.lock.Lock()
+	f.Team = standup.dashboardTeam()
+	if name := r.FormValue("team"); name != "" {
+		f.Team = standup.team(name)
+	} else if !admin && !own {
+		f.Team = nil
+		for _, team := range standup.teams() {
+			if team.isMember(viewer.ID) {
+				f.Team = team
+				break
+			}
+		}
+		if f.Team == nil {
+			standup.lock.Unlock()
+			return f, errWebForbidden
+		}
+	}
+	standup.lock.Unlock()
+	if f.Team == nil {
+		return f, errWebTeamNotFound
+	}
+
+	if !admin && !own && !f.Team.isMember(viewer.ID) {
+		return f, errWebForbidden
+	}
+
+	return f, nil
+}
+
+// webReport gathers the entries, participation and blockers of a period
+func (standup *Standup) webReport(f webFilter) (*webReport, error) {
+	entries, err := standup.webEntries(f)
+	if err != nil {
+		return nil, err
+	}
+	blockers, err := standup.webBlockers(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []webParticipation
+	if f.Team.Name != "" && f.Email == "" {
+		members = standup.webMembers(f.Team)
+	}
+
+	return &webReport{
+		Team:          f.Team.Name,
+		From:          f.From.Format(dateFormat),
+		To:            f.To.Format(dateFormat),
+		Entries:       entries,
+		Participation: participation(f.Team, f.From, f.To, entries, members),
+		Blockers:      blockers,
+		Sections:      sectionOrder(f.Team, entries),
+	}, nil
+}
+
+// webFilterError answers with the status matching an error of the
+// query parameters
+func webFilterError(w http.ResponseWriter, err error) {
+	switch err {
+	case errWebTeamNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errWebForbidden:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+func (standup *Standup) handleWebJSON(w http.ResponseWriter, r *http.Request) {
+	f, err := standup.webFilterFor(r, standup.webNow())
+	if err != nil {
+		webFilterError(w, err)
+		return
+	}
+
+	report, err := standup.webReport(f)
+	if err != nil {
+		webReportError(w, "Error reading the standups", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		webReportError(w, "Error encoding data", err)
+	}
+}
+
+// handleWebCSV exports the entries, one line per section
+func (standup *Standup) handleWebCSV(w http.ResponseWriter, r *http.Request) {
+	f, err := standup.webFilterFor(r, standup.webNow())
+	if err != nil {
+		webFilterError(w, err)
+		return
+	}
+
+	report, err := standup.webReport(f)
+	if err != nil {
+		webReportError(w, "Error reading the standups", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="standup.csv"`)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"date", "user", "name", "email", "section", "text"})
+	for _, e := range report.Entries {
+		for _, section := range report.Sections {
+			if text, ok := e.Sections[section]; ok {
+				out.Write([]string{e.Date, csvCell(e.User), csvCell(e.Name), csvCell(e.Email), section, csvCell(text)})
+			}
+		}
+	}
+	out.Flush()
+}
+
+// csvCell keeps spreadsheets from reading a text as a formula
+func csvCell(text string) string {
+	if text != "" && strings.ContainsAny(text[:1], "=+-@\t\r") {
+		return "'" + text
+	}
+	return text
+}
+
+// handleWebDashboard shows the standups of a day, along with the
+// participation and blockers of the period ending that day
+func (standup *Standup) handleWebDashboard(w http.ResponseWriter, r *http.Request) {
+	now := standup.webNow()
+	if r.FormValue("date") != "" && r.FormValue("to") == "" {
+		r.Form.Set("to", r.FormValue("date"))
+	}
+	f, err := standup.webFilterFor(r, now)
+	if err != nil {
+		webFilterError(w, err)
+		return
+	}
+
+	report, err := standup.webReport(f)
+	if err != nil {
+		webReportError(w, "Error reading the standups", err)
+		return
+	}
+
+	var day []webEntry
+	for _, e := range report.Entries {
+		if e.Date == report.To {
+			day = append(day, e)
+		}
+	}
+
+	standup.lock.Lock()
+	var teams []string
+	for _, team := range standup.teams() {
+		teams = append(teams, team.Name)
+	}
+	standup.lock.Unlock()
+	sort.Strings(teams)
+
+	err = dashboardTemplate.Execute(w, struct {
+		*webReport
+		Teams      []string
+		Day        []webEntry
+		Prev, Next string
+	}{
+		webReport: report,
+		Teams:     teams,
+		Day:       day,
+		Prev:      f.To.AddDate(0, 0, -1).Format(dateFormat),
+		Next:      f.To.AddDate(0, 0, 1).Format(dateFormat),
+	})
+	if err != nil {
+		webReportError(w, "Error rendering the dashboard", err)
+	}
+}
+
+// handleWebUser shows the history of a user
+func (standup *Standup) handleWebUser(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	r.Form.Set("user", mux.Vars(r)["user"])
+
+	f, err := standup.webFilterFor(r, standup.webNow())
+	if err != nil {
+		webFilterError(w, err)
+		return
+	}
+
+	report, err := standup.webReport(f)
+	if err != nil {
+		webReportError(w, "Error reading the standups", err)
+		return
+	}
+
+	user := standup.userByEmail(f.Email)
+	err = userTemplate.Execute(w, struct {
+		*webReport
+		Name  string
+		Email string
+	}{report, user.Name, f.Email})
+	if err != nil {
+		webReportError(w, "Error rendering the history", err)
+	}
 }
 
 func webReportError(w http.ResponseWriter, msg string, err error) {
 	w.WriteHeader(http.StatusInternalServerError)
 	w.Write([]byte(fmt.Sprintf("%s\n\n%s\n", msg, err)))
 }
+
+var webFuncs = template.FuncMap{
+	"percent": func(rate float64) string { return fmt.Sprintf("%.0f%%", rate*100) },
+}
+
+var dashboardTemplate = template.Must(template.New("standup").Funcs(webFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Standup{{if .Team}} of {{.Team}}{{end}}, {{.To}}</title>
+</head>
+<body>
+  <p>
+    <a href="/plugins/standup?date={{.To}}">Everyone</a>
+    {{range .Teams}} | <a href="/plugins/standup?team={{.}}&date={{$.To}}">{{.}}</a>{{end}}
+  </p>
+  <h1>Standup{{if .Team}} of {{.Team}}{{end}}, {{.To}}</h1>
+  <p>
+    <a href="/plugins/standup?team={{.Team}}&date={{.Prev}}">Previous day</a> |
+    <a href="/plugins/standup?team={{.Team}}&date={{.Next}}">Next day</a> |
+    Export <a href="/plugins/standup.json?team={{.Team}}&from={{.From}}&to={{.To}}">JSON</a>
+    <a href="/plugins/standup.csv?team={{.Team}}&from={{.From}}&to={{.To}}">CSV</a>
+  </p>
+  {{if .Day}}
+  <table>
+    <tr><th></th>{{range .Sections}}<th>{{.}}</th>{{end}}</tr>
+    {{range .Day}}
+    {{$entry := .}}
+    <tr>
+      <td><a href="/plugins/standup/users/{{.Email}}?team={{$.Team}}">{{.Name}}</a></td>
+      {{range $.Sections}}<td>{{index $entry.Sections .}}</td>{{end}}
+    </tr>
+    {{end}}
+  </table>
+  {{else}}
+  <p>No standup on {{.To}}.</p>
+  {{end}}
+
+  <h2>Open blockers</h2>
+  {{if .Blockers}}
+  <ul>
+    {{range .Blockers}}<li><code>#{{.ID}}</code> <a href="/plugins/standup/users/{{.Email}}?team={{$.Team}}">{{.Name}}</a> since {{.Date}}: {{.Text}}{{if .Escalated}} <strong>escalated</strong>{{end}}</li>{{end}}
+  </ul>
+  {{else}}
+  <p>Nobody is blocked.</p>
+  {{end}}
+
+  <h2>Participation from {{.From}} to {{.To}}</h2>
+  <table>
+    <tr><th></th><th>Answered</th><th>Rate</th><th>Streak</th><th>Longest streak</th></tr>
+    {{range .Participation}}
+    <tr>
+      <td><a href="/plugins/standup/users/{{.Email}}?team={{$.Team}}">{{.Name}}</a></td>
+      <td>{{.Answered}}/{{.Days}}</td>
+      <td>{{percent .Rate}}</td>
+      <td>{{.Streak}}</td>
+      <td>{{.Longest}}</td>
+    </tr>
+    {{end}}
+  </table>
+</body>
+</html>
+`))
+
+var userTemplate = template.Must(template.New("standup-user").Funcs(webFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Standups of {{.Name}}</title>
+</head>
+<body>
+  <p><a href="/plugins/standup">Everyone</a></p>
+  <h1>Standups of {{.Name}} from {{.From}} to {{.To}}</h1>
+  {{range .Participation}}
+  <p>Answered {{.Answered}}/{{.Days}} working days ({{percent .Rate}}), {{.Streak}} in a row, {{.Longest}} at most.</p>
+  {{end}}
+  <p>Export <a href="/plugins/standup.json?team={{.Team}}&user={{.Email}}&from={{.From}}&to={{.To}}">JSON</a>
+  <a href="/plugins/standup.csv?team={{.Team}}&user={{.Email}}&from={{.From}}&to={{.To}}">CSV</a></p>
+  {{if .Entries}}
+  <table>
+    <tr><th>Date</th>{{range .Sections}}<th>{{.}}</th>{{end}}</tr>
+    {{range .Entries}}
+    {{$entry := .}}
+    <tr>
+      <td>{{.Date}}</td>
+      {{range $.Sections}}<td>{{index $entry.Sections .}}</td>{{end}}
+    </tr>
+    {{end}}
+  </table>
+  {{else}}
+  <p>No standup.</p>
+  {{end}}
+</body>
+</html>
+`))
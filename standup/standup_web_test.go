@@ -0,0 +1,178 @@
+package standup
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/gorilla/mux"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse(dateFormat, s)
+	return t
+}
+
+func TestParticipation(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	team := standup.dashboardTeam()
+
+	// From Monday 12 to Monday 19, Tuesday 13 off for A
+	var entries []webEntry
+	for _, date := range []string{"2026-10-12", "2026-10-14", "2026-10-15", "2026-10-16"} {
+		entries = append(entries, webEntry{Date: date, Name: "A", Email: "A@test.ly"})
+	}
+	entries = append(entries, webEntry{Date: "2026-10-19", Name: "B", Email: "B@test.ly"})
+	members := []webParticipation{{Name: "C", Email: "C@test.ly"}}
+
+	got := participation(team, day("2026-10-12"), day("2026-10-19"), entries, members)
+	if len(got) != 3 {
+		t.Fatalf("expected A, B and C, got %v", got)
+	}
+
+	// The 19th isn't over, A may still answer
+	a := got[0]
+	if a.Days != 5 || a.Answered != 4 || a.Rate != 0.8 || a.Streak != 3 || a.Longest != 3 {
+		t.Errorf("unexpected participation of A %+v", a)
+	}
+	if b := got[1]; b.Days != 6 || b.Answered != 1 || b.Streak != 1 || b.Longest != 1 {
+		t.Errorf("unexpected participation of B %+v", b)
+	}
+	if c := got[2]; c.Name != "C" || c.Days != 5 || c.Answered != 0 || c.Rate != 0 {
+		t.Errorf("unexpected participation of C %+v", c)
+	}
+}
+
+// newTestWeb serves the web views to A, a GlobalAdmin
+func newTestWeb(t *testing.T) (*Standup, *mux.Router, *bawttest.WebServer) {
+	standup, _ := newTestStandup(t)
+	standup.once.Do(func() {})
+
+	user, _ := standup.bot.Users.Get("U1")
+	server := &bawttest.WebServer{User: &user}
+	standup.bot.WebServer = server
+	bawttest.MakeAdmin(t, standup.bot.DB, "U1")
+
+	router := mux.NewRouter()
+	standup.InitWebPlugin(standup.bot, router, nil)
+
+	return standup, router, server
+}
+
+func serve(router http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	return rec
+}
+
+func TestWebExport(t *testing.T) {
+	standup, router, _ := newTestWeb(t)
+	standup.conf = Config{Teams: []TeamConfig{{Name: "backend", Channel: "C1", Members: []string{"A"}}}}
+
+	monday := timeToStandupDate(day("2026-10-19"))
+	standup.store.Put(standupKey{date: monday, email: "A@test.ly"}, standupData{Today: "code", Blocking: "the build"})
+	standup.store.Put(standupKey{date: monday, email: "B@test.ly"}, standupData{Answers: map[string]string{"done": "mockups"}})
+	standup.store.PutBlocker(&Blocker{Owner: "U1", Text: "the build", Date: "2026-10-19", CreatedAt: day("2026-10-19")})
+	standup.store.PutBlocker(&Blocker{Owner: "U2", Text: "reviews", Date: "2026-10-19", CreatedAt: day("2026-10-19")})
+	standup.store.PutBlocker(&Blocker{Owner: "U1", Text: "the VPN", Date: "2026-10-12", CreatedAt: day("2026-10-12"), ResolvedAt: day("2026-10-13")})
+
+	rec := serve(router, "/plugins/standup.json?team=backend&from=2026-10-19&to=2026-10-19")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	var report webReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Entries) != 1 || report.Entries[0].User != "U1" || report.Entries[0].Sections["today"] != "code" {
+		t.Errorf("expected the entry of A, got %+v", report.Entries)
+	}
+	if len(report.Participation) != 1 || report.Participation[0].Answered != 1 {
+		t.Errorf("unexpected participation %+v", report.Participation)
+	}
+	if len(report.Blockers) != 1 || report.Blockers[0].ID != 1 || report.Blockers[0].Name != "A" {
+		t.Errorf("expected the open blocker of A, got %+v", report.Blockers)
+	}
+
+	rec = serve(router, "/plugins/standup.csv?from=2026-10-19&to=2026-10-19")
+	expected := "date,user,name,email,section,text\n" +
+		"2026-10-19,U1,A,A@test.ly,today,code\n" +
+		"2026-10-19,U1,A,A@test.ly,blocking,the build\n" +
+		"2026-10-19,U2,B,B@test.ly,done,mockups\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != expected {
+		t.Errorf("unexpected CSV %d:\n%s", rec.Code, rec.Body)
+	}
+
+	rec = serve(router, "/plugins/standup.json?user=<@U2>&to=2026-10-19&days=7")
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.From != "2026-10-13" || len(report.Entries) != 1 || report.Entries[0].Name != "B" {
+		t.Errorf("expected the week of B, got %+v", report)
+	}
+
+	for target, code := range map[string]int{
+		"/plugins/standup.json?team=nope":                     http.StatusNotFound,
+		"/plugins/standup.json?from=yesterday":                http.StatusBadRequest,
+		"/plugins/standup.csv?from=2026-10-20&to=2026-10-19":  http.StatusBadRequest,
+		"/plugins/standup.json?days=100000":                   http.StatusBadRequest,
+		"/plugins/standup.csv?from=0001-01-01&to=9999-12-31":  http.StatusBadRequest,
+		"/plugins/standup.json?from=2025-10-20&to=2026-10-20": http.StatusOK,
+	} {
+		if rec := serve(router, target); rec.Code != code {
+			t.Errorf("%s: expected %d, got %d", target, code, rec.Code)
+		}
+	}
+}
+
+func TestWebDashboard(t *testing.T) {
+	standup, router, _ := newTestWeb(t)
+
+	standup.store.Put(standupKey{date: timeToStandupDate(day("2026-10-19")), email: "A@test.ly"}, standupData{Today: "code", Blocking: "the build"})
+
+	rec := serve(router, "/plugins/standup?date=2026-10-19")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<td>code</td>") || !strings.Contains(rec.Body.String(), "the build") {
+		t.Errorf("expected the standup of A, got %d:\n%s", rec.Code, rec.Body)
+	}
+
+	rec = serve(router, "/plugins/standup/users/A@test.ly?to=2026-10-19")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Standups of A") || !strings.Contains(rec.Body.String(), "<td>2026-10-19</td>") {
+		t.Errorf("expected the history of A, got %d:\n%s", rec.Code, rec.Body)
+	}
+}
+
+func TestWebAccess(t *testing.T) {
+	standup, router, server := newTestWeb(t)
+	standup.conf = Config{Teams: []TeamConfig{{Name: "backend", Channel: "C1", Members: []string{"A"}}}}
+
+	standup.store.Put(standupKey{date: timeToStandupDate(day("2026-10-19")), email: "B@test.ly"}, standupData{Today: "=HYPERLINK(\"http://evil\")"})
+
+	rec := serve(router, "/plugins/standup.csv?user=<@U2>&from=2026-10-19&to=2026-10-19")
+	if expected := "'=HYPERLINK(\"\"http://evil\"\")"; !strings.Contains(rec.Body.String(), expected) {
+		t.Errorf("expected the formula to be escaped, got:\n%s", rec.Body)
+	}
+
+	user, _ := standup.bot.Users.Get("U2")
+	server.User = &user
+	for target, code := range map[string]int{
+		"/plugins/standup.json?team=backend&from=2026-10-19&to=2026-10-19": http.StatusForbidden,
+		"/plugins/standup.csv?from=2026-10-19&to=2026-10-19":               http.StatusForbidden,
+		"/plugins/standup?date=2026-10-19":                                 http.StatusForbidden,
+		"/plugins/standup/users/A@test.ly?to=2026-10-19":                   http.StatusForbidden,
+		"/plugins/standup/users/B@test.ly?to=2026-10-19":                   http.StatusOK,
+		"/plugins/standup.json?user=<@U2>&to=2026-10-19":                   http.StatusOK,
+	} {
+		if rec := serve(router, target); rec.Code != code {
+			t.Errorf("%s: expected %d, got %d", target, code, rec.Code)
+		}
+	}
+
+	server.User = nil
+	if rec := serve(router, "/plugins/standup.json?user=<@U2>"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected anonymous requests to be refused, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,300 @@
+package standup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Store keeps the standup entries of each user, by day
+type Store interface {
+	Get(key standupKey) (standupData, bool)
+	Put(key standupKey, data standupData) error
+	// Range returns the entries from a day to another, both included
+	Range(from, to standupDate) (map[standupKey]standupData, error)
+
+	// Session returns the standup a member of a team is asked on a day,
+	// if any, and PutSession saves it
+	Session(team, date, user string) (*Session, error)
+	PutSession(s *Session) error
+	// Pending returns the unfinished sessions of a user, oldest first
+	Pending(user string) ([]*Session, error)
+
+	// Summarized tells whether the summary of a team's standup was
+	// posted on a day, and SetSummarized records it
+	Summarized(team, date string) bool
+	SetSummarized(team, date string) error
+
+	// Teams returns the teams defined in chat, by name
+	Teams() ([]TeamConfig, error)
+	PutTeam(team TeamConfig) error
+	DeleteTeam(name string) error
+
+	// Blockers returns the blockers tracked, by ID
+	Blockers() ([]*Blocker, error)
+	// PutBlocker saves a blocker, giving an ID to the new ones
+	PutBlocker(b *Blocker) error
+}
+
+// Session is a standup asked privately to a member of a team, one
+// question after the other
+type Session struct {
+	Team      string
+	Date      string
+	User      string
+	Answers   []string
+	StartedAt time.Time
+	// RemindedAt is the last reminder, Reminders how many were sent
+	RemindedAt time.Time
+	Reminders  int
+	DoneAt     time.Time
+	// Expired is set on the sessions left unfinished the day before
+	Expired bool `json:",omitempty"`
+}
+
+// Done tells whether all the questions were answered
+func (s *Session) Done() bool {
+	return !s.DoneAt.IsZero()
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+var (
+	bucketName          = []byte("standup")
+	sessionsBucketName  = []byte("standup_sessions")
+	summariesBucketName = []byte("standup_summaries")
+	teamsBucketName     = []byte("standup_teams")
+	blockersBucketName  = []byte("standup_blockers")
+)
+
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bucketName, sessionsBucketName, summariesBucketName, teamsBucketName, blockersBucketName} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *boltStore) Get(key standupKey) (data standupData, found bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get(key.key())
+		if v == nil {
+			return nil
+		}
+		found = json.Unmarshal(v, &data) == nil
+		return nil
+	})
+	return
+}
+
+func (s *boltStore) Put(key standupKey, data standupData) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cnt, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(bucketName).Put(key.key(), cnt)
+	})
+}
+
+func (s *boltStore) Range(from, to standupDate) (map[standupKey]standupData, error) {
+	out := make(map[standupKey]standupData)
+	prefix := []byte(standupPrefix + ":")
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		for k, v := c.Seek(standupKey{date: from}.key()); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			key := standupKeyFromBytes(k)
+			if key.date.UnixUTC() > to.UnixUTC() {
+				break
+			}
+
+			var data standupData
+			if err := json.Unmarshal(v, &data); err != nil {
+				return err
+			}
+			out[key] = data
+		}
+		return nil
+	})
+
+	return out, err
+}
+
+// Sessions are kept by day, then by team and user
+func sessionKey(team, user string) []byte {
+	return []byte(team + ":" + user)
+}
+
+func (s *boltStore) Session(team, date, user string) (*Session, error) {
+	var session *Session
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(sessionsBucketName).Bucket([]byte(date))
+		if b == nil {
+			return nil
+		}
+
+		v := b.Get(sessionKey(team, user))
+		if v == nil {
+			return nil
+		}
+
+		session = &Session{}
+		return json.Unmarshal(v, session)
+	})
+
+	return session, err
+}
+
+func (s *boltStore) PutSession(session *Session) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(sessionsBucketName).CreateBucketIfNotExists([]byte(session.Date))
+		if err != nil {
+			return err
+		}
+
+		cnt, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(sessionKey(session.Team, session.User), cnt)
+	})
+}
+
+// pendingDays is how many of the last days are looked at for sessions
+// still waiting for answers, timezones spanning two days at most
+const pendingDays = 2
+
+func (s *boltStore) Pending(user string) ([]*Session, error) {
+	var pending []*Session
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(sessionsBucketName).Cursor()
+
+		days := 0
+		for k, _ := c.Last(); k != nil && days < pendingDays; k, _ = c.Prev() {
+			days++
+
+			err := tx.Bucket(sessionsBucketName).Bucket(k).ForEach(func(_, v []byte) error {
+				session := &Session{}
+				if err := json.Unmarshal(v, session); err != nil {
+					return err
+				}
+				if session.User == user && !session.Done() && !session.Expired {
+					pending = append(pending, session)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].StartedAt.Before(pending[j].StartedAt) })
+
+	return pending, err
+}
+
+func summaryKey(team, date string) []byte {
+	return []byte(date + ":" + team)
+}
+
+func (s *boltStore) Summarized(team, date string) bool {
+	found := false
+	s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(summariesBucketName).Get(summaryKey(team, date)) != nil
+		return nil
+	})
+	return found
+}
+
+func (s *boltStore) SetSummarized(team, date string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(summariesBucketName).Put(summaryKey(team, date), []byte(time.Now().Format(time.RFC3339)))
+	})
+}
+
+func (s *boltStore) Teams() ([]TeamConfig, error) {
+	var teams []TeamConfig
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(teamsBucketName).ForEach(func(_, v []byte) error {
+			var team TeamConfig
+			if err := json.Unmarshal(v, &team); err != nil {
+				return err
+			}
+			teams = append(teams, team)
+			return nil
+		})
+	})
+
+	return teams, err
+}
+
+func (s *boltStore) PutTeam(team TeamConfig) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cnt, err := json.Marshal(team)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(teamsBucketName).Put([]byte(team.Name), cnt)
+	})
+}
+
+func (s *boltStore) DeleteTeam(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(teamsBucketName).Delete([]byte(name))
+	})
+}
+
+func (s *boltStore) Blockers() ([]*Blocker, error) {
+	var blockers []*Blocker
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(blockersBucketName).ForEach(func(_, v []byte) error {
+			var b Blocker
+			if err := json.Unmarshal(v, &b); err != nil {
+				return err
+			}
+			blockers = append(blockers, &b)
+			return nil
+		})
+	})
+
+	return blockers, err
+}
+
+func (s *boltStore) PutBlocker(b *Blocker) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(blockersBucketName)
+		if b.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			b.ID = int(id)
+		}
+
+		cnt, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+
+		// Keys sort by ID
+		return bucket.Put([]byte(fmt.Sprintf("%010d", b.ID)), cnt)
+	})
+}
@@ -0,0 +1,73 @@
+package standup
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/nlopes/slack"
+)
+
+func newTestStandup(t *testing.T) (*Standup, *[]bawttest.Sent) {
+	db := bawttest.NewDB(t, createBuckets)
+	bot := bawttest.NewBot(db)
+	bot.Users.Replace([]slack.User{
+		{ID: "U1", Name: "A", Profile: slack.UserProfile{Email: "A@test.ly"}},
+		{ID: "U2", Name: "B", TZ: "America/New_York", Profile: slack.UserProfile{Email: "B@test.ly"}},
+	})
+	bot.Channels.Replace([]bawt.Channel{{ID: "C1", Name: "backend"}})
+
+	var out []bawttest.Sent
+	standup := &Standup{
+		bot:         bot,
+		store:       &boltStore{db: db},
+		teamErrors:  map[string]string{},
+		send:        bawttest.Recorder(&out),
+		sendPrivate: bawttest.Recorder(&out),
+	}
+	// Messages are acknowledged right away, with their index as timestamp
+	standup.post = func(to, text string, private bool, posted func(channel, ts string)) {
+		out = append(out, bawttest.Sent{To: to, Text: text})
+		channel := to
+		if private {
+			channel = "D" + to
+		}
+		posted(channel, strconv.Itoa(len(out)))
+	}
+
+	return standup, &out
+}
+
+func TestStoreRange(t *testing.T) {
+	standup, _ := newTestStandup(t)
+
+	day := unixToStandupDate(1431921600) // 2015-May-18
+	for i, date := range []standupDate{day, day.next(), day.next().next()} {
+		for _, email := range []string{"A@test.ly", "B@test.ly"} {
+			err := standup.store.Put(standupKey{date: date, email: email}, standupData{Today: string('a' + rune(i))})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	data, found := standup.store.Get(standupKey{date: day.next(), email: "B@test.ly"})
+	if !found || data.Today != "b" {
+		t.Errorf("expected the entry of B on the 19th, got %v", data)
+	}
+	if _, found := standup.store.Get(standupKey{date: day, email: "C@test.ly"}); found {
+		t.Error("expected no entry for C")
+	}
+
+	entries, err := standup.store.Range(day.next(), day.next().next())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("expected 4 entries, got %d", len(entries))
+	}
+	if _, ok := entries[standupKey{date: day, email: "A@test.ly"}]; ok {
+		t.Error("expected the 18th to be left out")
+	}
+}
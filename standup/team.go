@@ -0,0 +1,319 @@
+package standup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gopherworks/bawt"
+)
+
+var (
+	// errUsage replies with the usage of the command
+	errUsage        = errors.New("usage")
+	errTeamNotFound = errors.New("no such team")
+	errTeamExists   = errors.New("this team exists already")
+	errConfigTeam   = errors.New("this team is defined in the config, change it there")
+	errNotAllowed   = errors.New("only GlobalAdmins and members of the team can change it")
+)
+
+// teamFields are the settings of a team changed with `!standup team set`
+var teamFields = []string{"channel", "time", "timezone", "days", "remind_after", "reminders", "summary_time", "summary_format", "group", "holidays"}
+
+// handleTeam runs `!standup team <action> [name] [args]`
+func (standup *Standup) handleTeam(msg *bawt.Message, args []string) {
+	if len(args) == 0 {
+		msg.ReplyMentionT("standup.team.usage")
+		return
+	}
+	if args[0] == "list" {
+		standup.listTeams(msg)
+		return
+	}
+	if len(args) < 2 {
+		msg.ReplyMentionT("standup.team.usage")
+		return
+	}
+
+	action, name := args[0], args[1]
+	conf, err := standup.changeTeam(msg.FromUser.ID, action, name, args[2:])
+
+	if err != errUsage {
+		entry := bawt.AuditEntry{
+			Actor:  msg.FromUser.ID,
+			Action: "standup:team:" + action,
+			Target: name,
+		}
+		if len(args) > 2 {
+			entry.Params = map[string]string{"args": strings.Join(args[2:], " ")}
+		}
+		switch {
+		case err == errNotAllowed:
+			entry.Result = bawt.AuditDenied
+		case err != nil:
+			entry.Result = bawt.AuditFailure
+			entry.Error = err.Error()
+		}
+		standup.bot.Audit(entry)
+	}
+
+	switch {
+	case err == errUsage:
+		msg.ReplyMentionT("standup.team.usage")
+	case err != nil:
+		msg.ReplyMentionT("standup.team.error", name, err.Error())
+	case action == "create":
+		msg.ReplyMentionT("standup.team.created", conf.Name)
+	case action == "delete":
+		msg.ReplyMentionT("standup.team.deleted", conf.Name)
+	default:
+		msg.ReplyMentionT("standup.team.updated", conf.Name)
+	}
+}
+
+// changeTeam creates, changes or deletes a team defined in chat, for
+// GlobalAdmins and the members of the team. Changes are checked like the
+// teams of the config before being saved.
+func (standup *Standup) changeTeam(user, action, name string, args []string) (TeamConfig, error) {
+	standup.lock.Lock()
+	defer standup.lock.Unlock()
+
+	if standup.configTeam(name) {
+		return TeamConfig{}, errConfigTeam
+	}
+	conf, found, err := standup.storedTeam(name)
+	if err != nil {
+		return conf, err
+	}
+	if !standup.canChangeTeam(user, conf, found) {
+		return conf, errNotAllowed
+	}
+
+	switch action {
+	case "create":
+		if found {
+			return conf, errTeamExists
+		}
+		if len(args) == 0 || len(args) > 3 {
+			return conf, errUsage
+		}
+		conf = TeamConfig{Name: name, Channel: cleanChannel(args[0])}
+		if len(args) > 1 {
+			conf.Time = args[1]
+		}
+		if len(args) > 2 {
+			conf.Timezone = args[2]
+		}
+
+	case "delete":
+		if !found {
+			return conf, errTeamNotFound
+		}
+		return conf, standup.store.DeleteTeam(conf.Name)
+
+	case "add", "remove":
+		if !found {
+			return conf, errTeamNotFound
+		}
+		if len(args) == 0 {
+			return conf, errUsage
+		}
+		for _, arg := range args {
+			user, ok := standup.bot.Users.Find(bawt.CleanUser(arg))
+			if !ok {
+				return conf, fmt.Errorf("I don't know any user called %s", arg)
+			}
+			conf.Members = removeMember(conf.Members, user.ID)
+			if action == "add" {
+				conf.Members = append(conf.Members, user.ID)
+			}
+		}
+
+	case "questions":
+		if !found {
+			return conf, errTeamNotFound
+		}
+		if conf.Questions, err = parseQuestions(strings.Join(args, " ")); err != nil {
+			return conf, err
+		}
+
+	case "set":
+		if !found {
+			return conf, errTeamNotFound
+		}
+		if len(args) < 2 {
+			return conf, errUsage
+		}
+		if err := setTeamField(&conf, strings.ToLower(args[0]), args[1:]); err != nil {
+			return conf, err
+		}
+
+	default:
+		return conf, errUsage
+	}
+
+	if _, err := newTeam(standup.bot, standup.conf, conf); err != nil {
+		return conf, err
+	}
+	return conf, standup.store.PutTeam(conf)
+}
+
+// isAdmin tells whether a user is a GlobalAdmin
+func (standup *Standup) isAdmin(user string) bool {
+	admins := bawt.InternalGroup{Name: "GlobalAdmins"}
+	admin, err := admins.IsUserMember(standup.bot.DB, user)
+	return err == nil && admin
+}
+
+// canChangeTeam tells whether a user may change a team defined in chat:
+// GlobalAdmins may change any, members their own team
+func (standup *Standup) canChangeTeam(user string, conf TeamConfig, found bool) bool {
+	if standup.isAdmin(user) {
+		return true
+	}
+	if !found {
+		return false
+	}
+	for _, member := range conf.Members {
+		if member == user {
+			return true
+		}
+	}
+	return false
+}
+
+// storedTeam returns the team defined in chat with the given name
+func (standup *Standup) storedTeam(name string) (TeamConfig, bool, error) {
+	teams, err := standup.store.Teams()
+	if err != nil {
+		return TeamConfig{}, false, err
+	}
+	for _, conf := range teams {
+		if strings.EqualFold(conf.Name, name) {
+			return conf, true, nil
+		}
+	}
+	return TeamConfig{}, false, nil
+}
+
+// setTeamField sets a field of a team to value, or clears it with `none`
+func setTeamField(conf *TeamConfig, field string, value []string) error {
+	text := strings.Join(value, " ")
+	if strings.ToLower(text) == "none" {
+		text = ""
+	}
+
+	switch field {
+	case "channel":
+		if text == "" {
+			return errUsage
+		}
+		conf.Channel = cleanChannel(text)
+	case "time":
+		conf.Time = text
+	case "timezone":
+		conf.Timezone = text
+	case "days":
+		conf.Days = splitList(text)
+	case "remind_after":
+		conf.RemindAfter = text
+	case "reminders":
+		conf.Reminders = nil
+		if text != "" {
+			n, err := strconv.Atoi(text)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid reminders %q, use a number", text)
+			}
+			conf.Reminders = &n
+		}
+	case "summary_time":
+		conf.SummaryTime = text
+	case "summary_format":
+		conf.SummaryFormat = text
+	case "group":
+		conf.Group = text
+	case "holidays":
+		conf.Holidays = splitList(text)
+	default:
+		return fmt.Errorf("unknown setting %q, use one of %s", field, strings.Join(teamFields, ", "))
+	}
+	return nil
+}
+
+// parseQuestions understands `id: text | id: text`, or `default` for the
+// default questions
+func parseQuestions(text string) ([]Question, error) {
+	if strings.TrimSpace(strings.ToLower(text)) == "default" {
+		return nil, nil
+	}
+
+	var questions []Question
+	for _, part := range strings.Split(text, "|") {
+		fields := strings.SplitN(part, ":", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("invalid question %q, use `id: question`", strings.TrimSpace(part))
+		}
+		questions = append(questions, Question{
+			ID:   strings.ToLower(strings.TrimSpace(fields[0])),
+			Text: strings.TrimSpace(fields[1]),
+		})
+	}
+	return questions, nil
+}
+
+// listTeams replies with the teams and their settings, or why they're
+// left out
+func (standup *Standup) listTeams(msg *bawt.Message) {
+	standup.lock.Lock()
+	confs := standup.teamConfigs()
+	standup.lock.Unlock()
+
+	if len(confs) == 0 {
+		msg.ReplyMentionT("standup.team.none")
+		return
+	}
+	sort.Slice(confs, func(i, j int) bool { return confs[i].Name < confs[j].Name })
+
+	lines := []string{msg.T("standup.team.list", len(confs))}
+	for _, conf := range confs {
+		team, err := newTeam(standup.bot, standup.conf, conf)
+		if err != nil {
+			lines = append(lines, msg.T("standup.team.broken", conf.Name, err.Error()))
+			continue
+		}
+
+		var ids []string
+		for _, q := range team.Questions {
+			ids = append(ids, "`!"+q.ID+"`")
+		}
+		at := fmt.Sprintf("%02d:%02d %s", team.Hour, team.Min, team.Location)
+		lines = append(lines, msg.T("standup.team.line", team.Name, team.Channel, len(team.Members), at, strings.Join(ids, " ")))
+	}
+	msg.Reply(strings.Join(lines, "\n"))
+}
+
+func removeMember(members []string, user string) []string {
+	var out []string
+	for _, member := range members {
+		if member != user {
+			out = append(out, member)
+		}
+	}
+	return out
+}
+
+// cleanChannel turns `<#C1234|name>` into `C1234`
+func cleanChannel(value string) string {
+	if strings.HasPrefix(value, "<#") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">")
+		return strings.SplitN(value, "|", 2)[0]
+	}
+	return value
+}
+
+// splitList splits a list given as `a, b` or `a b`
+func splitList(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' })
+}
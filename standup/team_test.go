@@ -0,0 +1,98 @@
+package standup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gopherworks/bawt/bawttest"
+)
+
+func TestChangeTeam(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	standup.conf = Config{Teams: []TeamConfig{{Name: "backend", Channel: "C1"}}}
+	bawttest.MakeAdmin(t, standup.bot.DB, "U1")
+
+	steps := []struct {
+		action string
+		name   string
+		args   string
+		err    string
+	}{
+		{action: "create", name: "backend", args: "#backend", err: "defined in the config"},
+		{action: "create", name: "design", args: "<#C1|backend> 10:00 Europe/Paris"},
+		{action: "create", name: "design", args: "#backend", err: "exists already"},
+		{action: "create", name: "ops", args: "#nope", err: "unknown channel"},
+		{action: "add", name: "design", args: "<@U1> @B"},
+		{action: "add", name: "design", args: "@Z", err: "I don't know"},
+		{action: "remove", name: "design", args: "@B"},
+		{action: "questions", name: "design", args: "done: What did you finish? | next: What's next?"},
+		{action: "questions", name: "design", args: "What's next?", err: "invalid question"},
+		{action: "set", name: "design", args: "days mon, wed"},
+		{action: "set", name: "design", args: "time 9h", err: "invalid time"},
+		{action: "set", name: "design", args: "color blue", err: "unknown setting"},
+		{action: "set", name: "ops", args: "time 10:00", err: "no such team"},
+	}
+	for _, step := range steps {
+		_, err := standup.changeTeam("U1", step.action, step.name, strings.Fields(step.args))
+		if step.err == "" && err != nil {
+			t.Errorf("%s %s: unexpected error %s", step.action, step.args, err)
+		}
+		if step.err != "" && (err == nil || !strings.Contains(err.Error(), step.err)) {
+			t.Errorf("%s %s: expected an error with %q, got %v", step.action, step.args, step.err, err)
+		}
+	}
+
+	team := standup.team("design")
+	if team == nil {
+		t.Fatal("expected the design team")
+	}
+	if team.Channel != "C1" || team.Hour != 10 || team.Location.String() != "Europe/Paris" {
+		t.Errorf("unexpected settings %+v", team)
+	}
+	if strings.Join(team.Members, " ") != "U1" || len(team.Questions) != 2 || team.Questions[1].Text != "What's next?" {
+		t.Errorf("unexpected members or questions %+v", team)
+	}
+	if len(team.Days) != 2 || !team.workday(at("2026-10-21 10:00")) || team.workday(at("2026-10-20 10:00")) {
+		t.Errorf("expected Monday and Wednesday, got %v", team.Days)
+	}
+
+	if _, err := standup.changeTeam("U1", "delete", "design", nil); err != nil {
+		t.Fatal(err)
+	}
+	if standup.team("design") != nil {
+		t.Error("expected the team to be deleted")
+	}
+}
+
+func TestChangeTeamPermissions(t *testing.T) {
+	standup, _ := newTestStandup(t)
+	bawttest.MakeAdmin(t, standup.bot.DB, "U1")
+
+	steps := []struct {
+		user    string
+		action  string
+		args    string
+		allowed bool
+	}{
+		{user: "U2", action: "create", args: "#backend"},
+		{user: "U1", action: "create", args: "#backend", allowed: true},
+		{user: "U2", action: "add", args: "@B"},
+		{user: "U1", action: "add", args: "@B", allowed: true},
+		{user: "U2", action: "set", args: "time 10:00", allowed: true},
+		{user: "U1", action: "remove", args: "@B", allowed: true},
+		{user: "U2", action: "delete"},
+	}
+	for _, step := range steps {
+		_, err := standup.changeTeam(step.user, step.action, "design", strings.Fields(step.args))
+		if step.allowed && err != nil {
+			t.Errorf("%s %s: unexpected error %s", step.user, step.action, err)
+		}
+		if !step.allowed && err != errNotAllowed {
+			t.Errorf("%s %s: expected to be denied, got %v", step.user, step.action, err)
+		}
+	}
+
+	if team := standup.team("design"); team == nil || team.Hour != 10 {
+		t.Errorf("expected the member's change to be saved, got %+v", team)
+	}
+}
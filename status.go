@@ -1,49 +1,305 @@
 package bawt
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/cskr/pubsub"
+)
+
+// TopicHealthChanged is published on PubSub with a ComponentStatus
+// payload every time a component changes state
+const TopicHealthChanged = "bawt:health:changed"
+
+// HealthState is the state of a single component
+type HealthState int
+
+const (
+	// StateUnknown indicates a component that hasn't reported yet
+	StateUnknown HealthState = iota
+	// StateOK indicates a healthy component
+	StateOK
+	// StateDegraded indicates a component that works with reduced functionality
+	StateDegraded
+	// StateFailing indicates a component that doesn't work
+	StateFailing
 )
 
-// Status represents the status of various components
+// String returns the lower-cased name of the state
+func (hs HealthState) String() string {
+	switch hs {
+	case StateOK:
+		return "ok"
+	case StateDegraded:
+		return "degraded"
+	case StateFailing:
+		return "failing"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON renders the state as its name
+func (hs HealthState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hs.String())
+}
+
+// HealthCheck is polled by the Status registry every time the status
+// is requested. It returns the current state of the component and an
+// optional error explaining it.
+type HealthCheck func() (HealthState, error)
+
+// ComponentStatus is a snapshot of a single registered component
+type ComponentStatus struct {
+	Name       string      `json:"name"`
+	State      HealthState `json:"state"`
+	Required   bool        `json:"required"`
+	LastError  string      `json:"last_error,omitempty"`
+	LastChange time.Time   `json:"last_change"`
+
+	check HealthCheck
+}
+
+// Status is a registry of named components and their health. Core
+// components ("db", "chat" and "http") are always registered, plugins
+// can add their own with Register.
 type Status struct {
-	DB   string
-	Chat string
-	HTTP string
+	lock       sync.RWMutex
+	components map[string]*ComponentStatus
+
+	pubsub *pubsub.PubSub // optional, set by the bot
+}
+
+// NewStatus returns a new status registry with the core components
+func NewStatus() *Status {
+	s := &Status{
+		components: make(map[string]*ComponentStatus),
+	}
+
+	s.Register("db", true, nil)
+	s.Register("chat", true, nil)
+	s.Register("http", false, nil)
+
+	return s
+}
+
+// Register adds a component to the registry. Required components must
+// not be failing for the bot to be alive, and must be ok or degraded for
+// the bot to be ready. `check` can be nil for components that push their
+// state with Set.
+func (s *Status) Register(name string, required bool, check HealthCheck) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	name = strings.ToLower(name)
+	if comp, ok := s.components[name]; ok {
+		comp.Required = required
+		comp.check = check
+		return
+	}
+
+	s.components[name] = &ComponentStatus{
+		Name:       name,
+		State:      StateUnknown,
+		Required:   required,
+		LastChange: time.Now(),
+		check:      check,
+	}
 }
 
-// NewStatus returns a new status struct
-func NewStatus() Status {
-	return Status{
-		DB:   "Not ok",
-		Chat: "Not ok",
-		HTTP: "N/A",
+// Unregister removes a component from the registry
+func (s *Status) Unregister(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.components, strings.ToLower(name))
+}
+
+// Set records the state of a registered component. LastChange is only
+// updated when the state actually changes.
+func (s *Status) Set(name string, state HealthState, err error) error {
+	s.lock.Lock()
+
+	comp, ok := s.components[strings.ToLower(name)]
+	if !ok {
+		s.lock.Unlock()
+		return fmt.Errorf("Invalid component: %s", name)
+	}
+
+	changed := comp.set(state, err)
+	snapshot := *comp
+	s.lock.Unlock()
+
+	if changed && s.pubsub != nil {
+		s.pubsub.TryPub(snapshot, TopicHealthChanged)
 	}
+
+	return nil
 }
 
-// Update updates the value of the component in the struct
+// Update updates the value of the component with the legacy "ok",
+// "not ok" and "n/a" values
 func (s *Status) Update(comp string, value string) error {
+	var state HealthState
+
 	switch strings.ToLower(value) {
 	case "ok":
-		break
+		state = StateOK
 	case "not ok":
-		break
+		state = StateFailing
 	case "n/a":
-		break
+		state = StateUnknown
 	default:
 		return fmt.Errorf("Invalid value: %s", value)
 	}
 
-	switch strings.ToLower(comp) {
-	case "db":
-		s.DB = value
-	case "chat":
-		s.Chat = value
-	case "http":
-		s.HTTP = value
-	default:
-		return fmt.Errorf("Invalid component: %s", comp)
+	return s.Set(comp, state, nil)
+}
+
+// Get returns a snapshot of a single component
+func (s *Status) Get(name string) (ComponentStatus, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	comp, ok := s.components[strings.ToLower(name)]
+	if !ok {
+		return ComponentStatus{}, false
 	}
 
-	return nil
+	return *comp, true
+}
+
+// Components runs the registered health checks and returns a snapshot of
+// all the components, sorted by name
+func (s *Status) Components() []ComponentStatus {
+	s.runChecks()
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	out := make([]ComponentStatus, 0, len(s.components))
+	for _, comp := range s.components {
+		out = append(out, *comp)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+// Live returns false if any required component is failing
+func (s *Status) Live() bool {
+	return live(s.Components())
+}
+
+// Ready returns true once all required components are ok or degraded
+func (s *Status) Ready() bool {
+	return ready(s.Components())
+}
+
+func live(comps []ComponentStatus) bool {
+	for _, comp := range comps {
+		if comp.Required && comp.State == StateFailing {
+			return false
+		}
+	}
+
+	return true
+}
+
+func ready(comps []ComponentStatus) bool {
+	for _, comp := range comps {
+		if comp.Required && comp.State != StateOK && comp.State != StateDegraded {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LivenessHandler serves the status of all components, with a 503 when
+// the bot isn't alive. It is meant to be mounted on `/healthz`.
+func (s *Status) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	comps := s.Components()
+	serve(w, comps, live(comps))
+}
+
+// ReadinessHandler serves the status of all components, with a 503 when
+// the bot isn't ready. It is meant to be mounted on `/readyz`.
+func (s *Status) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	comps := s.Components()
+	serve(w, comps, ready(comps))
+}
+
+// String returns a human readable report, one component per line
+func (s *Status) String() string {
+	var lines []string
+
+	for _, comp := range s.Components() {
+		line := fmt.Sprintf("%s: %s (since %s)", comp.Name, comp.State, comp.LastChange.Format(time.RFC3339))
+		if comp.LastError != "" {
+			line += fmt.Sprintf(", last error: %s", comp.LastError)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func serve(w http.ResponseWriter, comps []ComponentStatus, ok bool) {
+	out := struct {
+		Status     string            `json:"status"`
+		Components []ComponentStatus `json:"components"`
+	}{
+		Status:     "ok",
+		Components: comps,
+	}
+
+	code := http.StatusOK
+	if !ok {
+		out.Status = "failing"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(out)
+}
+
+func (s *Status) runChecks() {
+	s.lock.RLock()
+	checks := make(map[string]HealthCheck)
+	for name, comp := range s.components {
+		if comp.check != nil {
+			checks[name] = comp.check
+		}
+	}
+	s.lock.RUnlock()
+
+	for name, check := range checks {
+		state, err := check()
+		s.Set(name, state, err)
+	}
+}
+
+// set records the state and tells whether it changed
+func (comp *ComponentStatus) set(state HealthState, err error) bool {
+	changed := comp.State != state
+	if changed {
+		comp.State = state
+		comp.LastChange = time.Now()
+	}
+
+	if err != nil {
+		comp.LastError = err.Error()
+	} else if state == StateOK {
+		comp.LastError = ""
+	}
+
+	return changed
 }
@@ -1,63 +1,151 @@
 package bawt
 
 import (
-	"reflect"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewStatus(t *testing.T) {
+	s := NewStatus()
+
 	tests := []struct {
-		name string
-		want Status
+		name     string
+		required bool
 	}{
-		{"Test defaults", Status{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}},
+		{"db", true},
+		{"chat", true},
+		{"http", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewStatus(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewStatus() = %v, want %v", got, tt.want)
+			comp, ok := s.Get(tt.name)
+			if !ok {
+				t.Fatalf("NewStatus() is missing component %s", tt.name)
 			}
+			assert.Equal(t, StateUnknown, comp.State)
+			assert.Equal(t, tt.required, comp.Required)
 		})
 	}
 }
 
 func TestStatus_Update(t *testing.T) {
-	type fields struct {
-		DB   string
-		Chat string
-		HTTP string
-	}
 	type args struct {
 		comp  string
 		value string
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
 		wantErr bool
 	}{
-		{"Test component DB", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", "ok"}, false},
-		{"Test component Chat", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"chat", "ok"}, false},
-		{"Test component HTTP", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"http", "ok"}, false},
-		{"Test component wild", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"wild", "ok"}, true},
-		{"Test component blank", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"", "ok"}, true},
-		{"Test value N/A", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", "N/A"}, false},
-		{"Test value Ok", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", "Ok"}, false},
-		{"Test value Not ok", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", "Not ok"}, false},
-		{"Test value wild", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", "wild"}, true},
-		{"Test value blank", fields{DB: "Not ok", Chat: "Not ok", HTTP: "N/A"}, args{"db", ""}, true},
+		{"Test component DB", args{"db", "ok"}, false},
+		{"Test component Chat", args{"chat", "ok"}, false},
+		{"Test component HTTP", args{"http", "ok"}, false},
+		{"Test component wild", args{"wild", "ok"}, true},
+		{"Test component blank", args{"", "ok"}, true},
+		{"Test value N/A", args{"db", "N/A"}, false},
+		{"Test value Ok", args{"db", "Ok"}, false},
+		{"Test value Not ok", args{"db", "Not ok"}, false},
+		{"Test value wild", args{"db", "wild"}, true},
+		{"Test value blank", args{"db", ""}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &Status{
-				DB:   tt.fields.DB,
-				Chat: tt.fields.Chat,
-				HTTP: tt.fields.HTTP,
-			}
+			s := NewStatus()
 			if err := s.Update(tt.args.comp, tt.args.value); (err != nil) != tt.wantErr {
 				t.Errorf("Status.Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestStatus_Set(t *testing.T) {
+	s := NewStatus()
+
+	assert.NoError(t, s.Set("db", StateFailing, fmt.Errorf("boom")))
+	comp, _ := s.Get("db")
+	assert.Equal(t, StateFailing, comp.State)
+	assert.Equal(t, "boom", comp.LastError)
+	changed := comp.LastChange
+
+	// Same state doesn't move LastChange
+	assert.NoError(t, s.Set("db", StateFailing, nil))
+	comp, _ = s.Get("db")
+	assert.Equal(t, changed, comp.LastChange)
+	assert.Equal(t, "boom", comp.LastError)
+
+	// Recovering clears the error
+	assert.NoError(t, s.Set("db", StateOK, nil))
+	comp, _ = s.Get("db")
+	assert.Equal(t, StateOK, comp.State)
+	assert.Empty(t, comp.LastError)
+
+	assert.Error(t, s.Set("wild", StateOK, nil))
+}
+
+func TestStatus_LiveAndReady(t *testing.T) {
+	s := NewStatus()
+
+	// Nothing reported yet: alive, but not ready
+	assert.True(t, s.Live())
+	assert.False(t, s.Ready())
+
+	s.Set("db", StateOK, nil)
+	s.Set("chat", StateDegraded, nil)
+	assert.True(t, s.Live())
+	assert.True(t, s.Ready())
+
+	// Optional components don't matter
+	s.Register("plugin", false, func() (HealthState, error) {
+		return StateFailing, fmt.Errorf("plugin down")
+	})
+	assert.True(t, s.Live())
+	assert.True(t, s.Ready())
+
+	s.Set("chat", StateFailing, nil)
+	assert.False(t, s.Live())
+	assert.False(t, s.Ready())
+}
+
+func TestStatus_Handlers(t *testing.T) {
+	s := NewStatus()
+	s.Register("plugin", false, func() (HealthState, error) {
+		return StateDegraded, fmt.Errorf("slow")
+	})
+
+	rec := httptest.NewRecorder()
+	s.ReadinessHandler(rec, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	s.Set("db", StateOK, nil)
+	s.Set("chat", StateOK, nil)
+
+	rec = httptest.NewRecorder()
+	s.ReadinessHandler(rec, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.LivenessHandler(rec, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var out struct {
+		Status     string
+		Components []struct {
+			Name      string
+			State     string
+			LastError string `json:"last_error"`
+		}
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	assert.Equal(t, "ok", out.Status)
+	assert.Len(t, out.Components, 4)
+	assert.Equal(t, "plugin", out.Components[3].Name)
+	assert.Equal(t, "degraded", out.Components[3].State)
+	assert.Equal(t, "slow", out.Components[3].LastError)
+}
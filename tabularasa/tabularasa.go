@@ -35,13 +35,44 @@ func (tabula *TabulaRasa) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, p
 
 	privRouter.HandleFunc("/plugins/tabularasa", func(w http.ResponseWriter, r *http.Request) {
 
-		tabula.TabulaRasta()
+		actor := "web:unknown"
+		user, err := bot.WebServer.AuthenticatedUser(r)
+		if err != nil {
+			user = nil
+		} else if user != nil {
+			actor = user.ID
+		}
+
+		entry := bawt.AuditEntry{
+			Actor:  actor,
+			Action: "tabularasa:unassign-all",
+			Target: asanaConf.Asana.Workspace,
+			Params: map[string]string{"remote_addr": r.RemoteAddr},
+		}
+
+		admins := bawt.InternalGroup{Name: "GlobalAdmins"}
+		if user == nil {
+			entry.Result = bawt.AuditDenied
+		} else if admin, err := admins.IsUserMember(bot.DB, user.ID); err != nil || !admin {
+			entry.Result = bawt.AuditDenied
+		}
+		if entry.Result == bawt.AuditDenied {
+			bot.Audit(entry)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if err := tabula.TabulaRasta(); err != nil {
+			entry.Result = bawt.AuditFailure
+			entry.Error = err.Error()
+		}
+		bot.Audit(entry)
 
 	})
 
 }
 
-func (tabula *TabulaRasa) TabulaRasta() {
+func (tabula *TabulaRasa) TabulaRasta() error {
 
 	taskhose := make(chan asana.Task, 100)
 
@@ -51,7 +82,7 @@ func (tabula *TabulaRasa) TabulaRasta() {
 
 	if err != nil {
 		fmt.Println("anasa Client: ", err)
-		return
+		return err
 	}
 
 	go tabula.SpinUpTaskWorker(taskhose)
@@ -69,6 +100,7 @@ func (tabula *TabulaRasa) TabulaRasta() {
 	wg.Wait()
 	close(taskhose)
 
+	return nil
 }
 
 func (tabula *TabulaRasa) GetFullTasksByAssignee(user asana.User, taskhose chan asana.Task, wg *sync.WaitGroup) {
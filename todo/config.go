@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+Config is the `todo` section of the configuration, like:
+
+	todo:
+	  timezone: Europe/Paris
+	  remind_every: 24h
+	  digest_time: "09:00"
+
+Due dates are days in `timezone`, the local one by default. Owners of
+overdue tasks are reminded privately every `remind_every` (24h by
+default, `0` disables reminders). Channels opting in with `!todo digest
+on` get a digest of their tasks every day at `digest_time` (09:00 by
+default).
+*/
+type Config struct {
+	Timezone    string
+	RemindEvery string `mapstructure:"remind_every"`
+	DigestTime  string `mapstructure:"digest_time"`
+}
+
+const (
+	defaultRemindEvery = 24 * time.Hour
+	defaultDigestTime  = "09:00"
+)
+
+// location returns the timezone of the due dates
+func (c Config) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// remindEvery returns the delay between two reminders of a task
+func (c Config) remindEvery() (time.Duration, error) {
+	if c.RemindEvery == "" {
+		return defaultRemindEvery, nil
+	}
+	return time.ParseDuration(c.RemindEvery)
+}
+
+// digestAt returns the hour and minute of the daily digest
+func (c Config) digestAt() (int, int, error) {
+	s := c.DigestTime
+	if s == "" {
+		s = defaultDigestTime
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid digest_time %q, use HH:MM", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
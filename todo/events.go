@@ -0,0 +1,46 @@
+package todo
+
+import "github.com/gopherworks/bawt"
+
+// TopicChanged is published with a TaskEvent every time a task is
+// created, updated, closed, reopened, moved or a change to it is undone
+const TopicChanged = "todo:changed"
+
+// Actions of a TaskEvent
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionClosed  = "closed"
+	ActionUndone  = "undone"
+	// ActionReopened is published when a recurring task comes back
+	ActionReopened = "reopened"
+	// ActionMoved is published for the list a task is moved to, its
+	// channel being that list
+	ActionMoved = "moved"
+)
+
+// TaskEvent describes a change to a task
+type TaskEvent struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+	User    string `json:"user"`
+	Task    Task   `json:"task"`
+}
+
+func init() {
+	bawt.RegisterEvent(TopicChanged, TaskEvent{})
+}
+
+// publish sends a TaskEvent for a change made by a user, in chat or on
+// the web
+func (p *Plugin) publish(channel, user, action string, task *Task) {
+	err := p.bot.Events.Publish(TopicChanged, TaskEvent{
+		Action:  action,
+		Channel: channel,
+		User:    user,
+		Task:    *task,
+	})
+	if err != nil {
+		p.bot.ReportError("todo", err)
+	}
+}
@@ -0,0 +1,187 @@
+package todo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// personalPrefix starts the key of the personal lists in the store,
+// followed by the Slack ID of their owner
+const personalPrefix = "@"
+
+var (
+	errSameList       = errors.New("the task is already there")
+	errUnknownChannel = errors.New("unknown channel")
+)
+
+// personalList is the key of a user's personal list
+func personalList(user string) string {
+	return personalPrefix + user
+}
+
+// isPersonal tells whether a list is someone's personal list
+func isPersonal(list string) bool {
+	return strings.HasPrefix(list, personalPrefix)
+}
+
+// listOwner is the Slack ID of the owner of a personal list
+func listOwner(list string) string {
+	return strings.TrimPrefix(list, personalPrefix)
+}
+
+// canSee tells whether a user may see a list: their own personal list, a
+// public channel, or a private conversation they're a member of. Lists of
+// conversations missing from the directory are hidden.
+func (p *Plugin) canSee(list, user string) bool {
+	if isPersonal(list) {
+		return listOwner(list) == user
+	}
+
+	channel, ok := p.bot.Channels.Get(list)
+	switch {
+	case !ok:
+		return false
+	case channel.IsChannel:
+		return true
+	case channel.IsIM:
+		return channel.User == user
+	}
+	for _, member := range channel.Members {
+		if member == user {
+			return true
+		}
+	}
+	return false
+}
+
+// listLabel names a list in a message: the channel or the personal list
+func (p *Plugin) listLabel(t func(string, ...interface{}) string, list string) string {
+	if isPersonal(list) {
+		return t("todo.personal", listOwner(list))
+	}
+	return "<#" + list + ">"
+}
+
+// listFor resolves where to move a task: `me` for the personal list of
+// the user, or a channel as `<#C1234|name>`, `#name` or its ID. The
+// channel must be one the user can see.
+func (p *Plugin) listFor(value, me string) (string, error) {
+	if strings.ToLower(value) == "me" {
+		return personalList(me), nil
+	}
+
+	var id string
+	if strings.HasPrefix(value, "<#") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">")
+		id = strings.SplitN(value, "|", 2)[0]
+	} else {
+		name := strings.TrimPrefix(value, "#")
+		if channel, ok := p.bot.Channels.ByName(name); ok {
+			id = channel.ID
+		} else if _, ok := p.bot.Channels.Get(name); ok {
+			id = name
+		}
+	}
+
+	if id == "" || isPersonal(id) || !p.canSee(id, me) {
+		return "", errUnknownChannel
+	}
+	return id, nil
+}
+
+// moveTask takes a task off a list and adds it to another, under a new ID
+// if its own is taken there. The caller holds the lock.
+func (p *Plugin) moveTask(from, to, user, id string) (*Task, error) {
+	if from == to {
+		return nil, errSameList
+	}
+
+	src := p.store.Get(from)
+	index, err := getTaskIndex(id, src)
+	if err != nil {
+		return nil, errNotFound
+	}
+	task := src[index]
+	before := task.clone()
+	src = append(src[:index], src[index+1:]...)
+
+	dst := p.store.Get(to)
+	if idInList(task.ID, dst) {
+		task.ID = p.generateRandomID(dst)
+	}
+	task.History = append(task.History, Change{Time: time.Now(), User: user, Action: ActionMoved, From: from, To: to})
+	dst = append(dst, task)
+
+	p.store.Put(from, src)
+	p.store.Put(to, dst)
+	p.pushUndo(from, user, ActionMoved, task.ID, before, to)
+	p.publish(to, user, ActionMoved, task)
+
+	return task, nil
+}
+
+// overview lists the open tasks assigned to or created by a user in
+// every list the viewer can see, along with the personal list of the
+// viewer when it's the same user
+func (p *Plugin) overview(user, viewer string, t func(string, ...interface{}) string, now time.Time) []string {
+	lists := p.store.Channels()
+	sort.Strings(lists)
+
+	var out []string
+	for _, list := range lists {
+		if !p.canSee(list, viewer) || (isPersonal(list) && user != viewer) {
+			continue
+		}
+
+		for _, task := range (Query{Sort: sortDue}).Apply(openTasks(p.store.Get(list)), now) {
+			if task.Assignee == user || task.CreatedBy == user || isPersonal(list) {
+				out = append(out, p.listLabel(t, list)+" "+task.format(now))
+			}
+		}
+	}
+	return out
+}
+
+// move moves a task from the list of the conversation
+func (p *Plugin) move(msg *bawt.Message, list, id, where string) {
+	to, err := p.listFor(where, msg.FromUser.ID)
+	if err != nil {
+		msg.ReplyMentionT("todo.channel_not_found", where)
+		return
+	}
+
+	task, err := p.moveTask(list, to, msg.FromUser.ID, id)
+	switch err {
+	case nil:
+		msg.ReplyMentionT("todo.moved", task.String(), p.listLabel(msg.T, to))
+	case errNotFound:
+		msg.ReplyMentionT("todo.not_found")
+	default:
+		msg.ReplyMentionT("todo.bad_query", err.Error())
+	}
+}
+
+// showOverview replies privately with the tasks of the user asking, or of
+// the user in args, in every channel the user asking can see
+func (p *Plugin) showOverview(msg *bawt.Message, args []string) {
+	user := msg.FromUser.ID
+	if len(args) != 0 && args[0] != "" && args[0] != "me" {
+		id, ok := p.userID(bawt.CleanUser(args[0]))
+		if !ok {
+			msg.ReplyMentionT("todo.user_not_found", args[0])
+			return
+		}
+		user = id
+	}
+
+	lines := p.overview(user, msg.FromUser.ID, msg.T, time.Now().In(p.loc))
+	if len(lines) == 0 {
+		msg.ReplyPrivatelyT("todo.nothing_to_do")
+		return
+	}
+	msg.ReplyPrivately(msg.T("todo.overview", len(lines), user) + "\n" + strings.Join(lines, "\n"))
+}
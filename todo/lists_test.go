@@ -0,0 +1,142 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveTask(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	p.store.Put("C1", Todo{{ID: "aa", Text: []string{"share"}, CreatedBy: "U1"}})
+	p.store.Put(personalList("U1"), Todo{{ID: "aa", Text: []string{"mine"}, CreatedBy: "U1"}})
+
+	task, err := p.moveTask("C1", personalList("U1"), "U1", "aa")
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, "aa", task.ID)
+		assert.Equal(t, Change{User: "U1", Action: ActionMoved, From: "C1", To: "@U1"}, withoutTime(task.History)[0])
+	}
+	assert.Empty(t, p.store.Get("C1"))
+	assert.Len(t, p.store.Get(personalList("U1")), 2)
+
+	_, err = p.moveTask("C1", "C1", "U1", "aa")
+	assert.Equal(t, errSameList, err)
+	_, err = p.moveTask("C1", "C2", "U1", "aa")
+	assert.Equal(t, errNotFound, err)
+
+	// Undoing the move brings the task back under its ID
+	u, back, err := p.undoLast("C1", "U1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ActionMoved, u.Action)
+		assert.Equal(t, "aa", back.ID)
+		assert.Equal(t, []string{"share"}, back.Text)
+	}
+	assert.Len(t, p.store.Get("C1"), 1)
+	assert.Len(t, p.store.Get(personalList("U1")), 1)
+}
+
+func TestListFor(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	p.bot.Channels.Set(bawt.Channel{ID: "C1", Name: "general", IsChannel: true})
+	p.bot.Channels.Set(bawt.Channel{ID: "G1", Name: "secret", IsGroup: true, Members: []string{"U2"}})
+	p.bot.Channels.Set(bawt.Channel{ID: "D2", IsIM: true, User: "U2"})
+
+	for value, list := range map[string]string{
+		"me":            "@U1",
+		"<#C1|general>": "C1",
+		"<#C1>":         "C1",
+		"#general":      "C1",
+		"C1":            "C1",
+	} {
+		got, err := p.listFor(value, "U1")
+		assert.NoError(t, err, value)
+		assert.Equal(t, list, got, value)
+	}
+
+	// Unknown channels, and conversations the user isn't in
+	for _, value := range []string{"<#C9|nope>", "C9", "#nope", "<#G1|secret>", "G1", "D2", "<#@U2>"} {
+		_, err := p.listFor(value, "U1")
+		assert.Equal(t, errUnknownChannel, err, value)
+	}
+
+	list, err := p.listFor("G1", "U2")
+	assert.NoError(t, err)
+	assert.Equal(t, "G1", list)
+}
+
+func TestOverview(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	p.bot.Channels.Set(bawt.Channel{ID: "C1", Name: "general", IsChannel: true})
+	p.bot.Channels.Set(bawt.Channel{ID: "C2", Name: "random", IsChannel: true})
+	p.bot.Channels.Set(bawt.Channel{ID: "G1", Name: "secret", IsGroup: true, Members: []string{"U1"}})
+
+	p.store.Put("C1", Todo{
+		{ID: "aa", Text: []string{"assigned"}, CreatedBy: "U2", Assignee: "U1"},
+		{ID: "bb", Text: []string{"created"}, CreatedBy: "U1", Assignee: "U2"},
+		{ID: "cc", Text: []string{"someone else's"}, CreatedBy: "U2"},
+	})
+	p.store.Put("C2", Todo{
+		{ID: "aa", Text: []string{"done"}, CreatedBy: "U1", Closed: true},
+	})
+	p.store.Put("G1", Todo{{ID: "aa", Text: []string{"hush"}, CreatedBy: "U1"}})
+	p.store.Put("D2", Todo{{ID: "aa", Text: []string{"between us"}, CreatedBy: "U1"}})
+	p.store.Put(personalList("U1"), Todo{{ID: "aa", Text: []string{"private"}, CreatedBy: "U1"}})
+
+	tr := func(key string, args ...interface{}) string { return p.bot.T("en", key, args...) }
+
+	assert.Equal(t, []string{
+		"<@U1>'s list `aa` private",
+		"<#C1> `aa` assigned → <@U1>",
+		"<#C1> `bb` created → <@U2>",
+		"<#G1> `aa` hush",
+	}, p.overview("U1", "U1", tr, now))
+
+	// Personal lists are for their owner's eyes only, and private
+	// conversations for their members
+	assert.Equal(t, []string{
+		"<#C1> `aa` assigned → <@U1>",
+		"<#C1> `bb` created → <@U2>",
+	}, p.overview("U1", "U2", tr, now))
+}
+
+func TestCloseRecurring(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	task, _ := p.addTask("C1", "U1", "water the plants", func(task *Task) error {
+		task.Recur = "monday"
+		task.Due = date("2026-10-12")
+		return nil
+	})
+
+	closed, err := p.closeTask("C1", "U1", task.ID, "")
+	assert.NoError(t, err)
+	assert.True(t, closed.Closed)
+
+	done, _ := p.store.Archived("C1", time.Time{})
+	assert.Len(t, done, 1)
+
+	// Comes back due on the next Monday
+	todo := p.store.Get("C1")
+	if assert.Len(t, todo, 1) {
+		assert.False(t, todo[0].Closed)
+		assert.Equal(t, task.ID, todo[0].ID)
+		next := todo[0].Due
+		assert.Equal(t, time.Monday, next.Weekday())
+		assert.True(t, next.After(time.Now()))
+		assert.Equal(t, ActionReopened, todo[0].History[len(todo[0].History)-1].Action)
+	}
+
+	// Undoing the close takes the place of the next occurrence
+	_, reopened, err := p.undoLast("C1", "U1")
+	assert.NoError(t, err)
+	todo = p.store.Get("C1")
+	if assert.Len(t, todo, 1) {
+		assert.Equal(t, date("2026-10-12"), reopened.Due.UTC())
+		assert.Equal(t, date("2026-10-12"), todo[0].Due.UTC())
+	}
+}
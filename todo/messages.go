@@ -0,0 +1,78 @@
+package todo
+
+import "github.com/gopherworks/bawt"
+
+func init() {
+	bawt.RegisterMessages(bawt.DefaultLocale, map[string]bawt.Translation{
+		"todo.add.usage":           {Other: "Add a task with `!todo add [some text]`"},
+		"todo.id.usage":            {Other: "Please %[1]s a task with `!todo %[1]s ID`"},
+		"todo.append.usage":        {Other: "Please %[1]s a task with `!todo %[1]s ID [more notes]`"},
+		"todo.unknown":             {Other: "Wooops, not sure what you wanted.\n"},
+		"todo.not_found":           {Other: "Task not found..."},
+		"todo.id_not_found":        {Other: "Task `%s` not found"},
+		"todo.details":             {Other: "%s\n> Created %s by <@%s>"},
+		"todo.added":               {Other: "added: %s"},
+		"todo.updated":             {Other: "updated %s"},
+		"todo.nothing_to_do":       {Other: "Nothing to do... Coffee time?"},
+		"todo.nothing_matches":     {Other: "No task matches, try `!todo` for all of them"},
+		"todo.bad_query":           {Other: "%s"},
+		"todo.user_not_found":      {Other: "I don't know any user called %s"},
+		"todo.assign.usage":        {Other: "Please assign a task with `!todo assign ID @user`, `me` or `none`"},
+		"todo.due.usage":           {Other: "Please set the due date of a task with `!todo due ID YYYY-MM-DD`, `today`, `tomorrow`, a weekday, a number of days like `3d` or `none`"},
+		"todo.priority.usage":      {Other: "Please set the priority of a task with `!todo priority ID high`, `medium`, `low` or `none`"},
+		"todo.digest.usage":        {Other: "Please use `!todo digest on` or `!todo digest off`"},
+		"todo.digest.on":           {Other: "This channel gets a digest of its tasks every day at %s"},
+		"todo.digest.off":          {Other: "This channel gets no digest of its tasks"},
+		"todo.digest":              {One: "*To do digest*: %d open task", Other: "*To do digest*: %d open tasks"},
+		"todo.digest.overdue":      {Other: "*%d overdue*"},
+		"todo.digest.soon":         {Other: "%d due this week"},
+		"todo.digest.rest":         {Other: "and %d more, see `!todo`"},
+		"todo.web.added":           {Other: "<@%s> added from the web: %s"},
+		"todo.web.updated":         {Other: "<@%s> updated from the web: %s"},
+		"todo.web.closed":          {Other: "<@%s> closed from the web: %s"},
+		"todo.done":                {One: "1 task closed since %[2]s:", Other: "%d tasks closed since %s:"},
+		"todo.done.task":           {Other: "%s — closed by <@%s> on %s"},
+		"todo.done.empty":          {Other: "No task closed since %s"},
+		"todo.undo.nothing":        {Other: "Nothing to undo"},
+		"todo.undo.gone":           {Other: "Can't undo, task `%s` is gone"},
+		"todo.undo.created":        {Other: "undone, removed %s"},
+		"todo.undo.updated":        {Other: "undone, back to %s"},
+		"todo.undo.closed":         {Other: "undone, reopened %s"},
+		"todo.undo.moved":          {Other: "undone, moved back %s"},
+		"todo.history.usage":       {Other: "Please show the history of a task with `!todo history ID`"},
+		"todo.history.created":     {Other: "%s <@%s> created it"},
+		"todo.history.updated":     {Other: "%s <@%s> changed %s from %s to %s"},
+		"todo.history.closed":      {Other: "%s <@%s> closed it"},
+		"todo.history.closed_note": {Other: "%s <@%s> closed it: _%s_"},
+		"todo.history.undone":      {Other: "%s <@%s> undid a change (%s)"},
+		"todo.history.reopened":    {Other: "%s reopened it, due %s"},
+		"todo.history.moved":       {Other: "%s <@%s> moved it from %s to %s"},
+		"todo.every.usage":         {Other: "Please make a task recurring with `!todo every ID monday`, `day`, `weekday`, `week`, `month` or `none`"},
+		"todo.move.usage":          {Other: "Please move a task with `!todo move ID me` or `#channel`"},
+		"todo.moved":               {Other: "moved %s to %s"},
+		"todo.channel_not_found":   {Other: "I don't know any channel called %s"},
+		"todo.personal":            {Other: "<@%s>'s list"},
+		"todo.overview":            {One: "1 task of <@%[2]s>:", Other: "%d tasks of <@%s>:"},
+		"todo.digest.personal":     {Other: "Personal lists get no digest, try `!todo overview`"},
+		"todo.reminder":            {One: "This task of yours is overdue:", Other: "These %d tasks of yours are overdue:"},
+		"todo.help": {Other: "%sCommands:```\n" +
+			"!todo add [some text]             - add task\n" +
+			"!todo                             - list tasks\n" +
+			"!todo [mine|@user] [high] [overdue] [sort:due] - filter and sort tasks\n" +
+			"!todo scratch [id]                - deletes task(s)\n" +
+			"!todo append [id] [more stuff]    - append text to a task\n" +
+			"!todo assign [id] [@user|me|none] - set who owns a task\n" +
+			"!todo due [id] [date|none]        - set when a task is due\n" +
+			"!todo priority [id] [high|medium|low|none] - set the priority of a task\n" +
+			"!todo done [since]                - list the closed tasks\n" +
+			"!todo history [id]                - show the changes to a task\n" +
+			"!todo undo                        - undo the last change\n" +
+			"!todo every [id] [monday|day|week|month|none] - make a task recurring\n" +
+			"!todo me [command]                - use your personal list\n" +
+			"!todo move [id] [me|#channel]     - move a task to another list\n" +
+			"!todo overview [@user]            - list your tasks in every channel\n" +
+			"!todo digest [on|off]             - daily digest of the channel's tasks\n" +
+			"!todo help                        - show this help\n" +
+			"```"},
+	})
+}
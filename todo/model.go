@@ -1,6 +1,7 @@
 package todo
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -13,6 +14,44 @@ func (a byID) Len() int           { return len(a) }
 func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byID) Less(i, j int) bool { return a[i].ID < a[j].ID }
 
+// Priority of a task, from none to high
+type Priority int
+
+// Priorities of a task
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = map[Priority]string{
+	PriorityNone:   "none",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+func (p Priority) String() string {
+	return priorityNames[p]
+}
+
+// ParsePriority understands `none`, `low`, `medium` and `high`, along
+// with `med`, `hi` and `lo`
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(s) {
+	case "none", "":
+		return PriorityNone, nil
+	case "low", "lo":
+		return PriorityLow, nil
+	case "medium", "med":
+		return PriorityMedium, nil
+	case "high", "hi":
+		return PriorityHigh, nil
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q, use low, medium, high or none", s)
+}
+
 type Task struct {
 	ID          string
 	CreatedAt   time.Time
@@ -20,11 +59,106 @@ type Task struct {
 	Text        []string
 	Closed      bool
 	ClosingNote string
+	// ClosedBy is the Slack ID of the user who closed the task
+	ClosedBy string
+	ClosedAt time.Time
+
+	// Assignee is the Slack ID of the user owning the task
+	Assignee string
+	// Due is the day the task is due, at midnight, or zero
+	Due      time.Time
+	Priority Priority
+	// Recur is when the task comes back once closed, if ever
+	Recur Recurrence `json:",omitempty"`
+	// RemindedAt is when the assignee was last reminded of the overdue task
+	RemindedAt time.Time
+
+	// History lists the changes made to the task, oldest first
+	History []Change `json:",omitempty"`
+}
+
+// Change is an entry in the history of a task. Field, From and To are
+// set when a field was updated.
+type Change struct {
+	Time   time.Time
+	User   string
+	Action string
+	Field  string `json:",omitempty"`
+	From   string `json:",omitempty"`
+	To     string `json:",omitempty"`
+}
+
+// fields returns the textual values of the fields which can be edited
+func (t *Task) fields() map[string]string {
+	due := ""
+	if !t.Due.IsZero() {
+		due = t.Due.Format("2006-01-02")
+	}
+
+	return map[string]string{
+		fieldText:     joinText(t.Text),
+		fieldAssignee: t.Assignee,
+		fieldDue:      due,
+		fieldPriority: t.Priority.String(),
+		fieldRecur:    string(t.Recur),
+	}
+}
+
+// record appends to the history the fields which differ from before
+func (t *Task) record(before *Task, user string, at time.Time) {
+	from, to := before.fields(), t.fields()
+	for _, field := range []string{fieldText, fieldAssignee, fieldDue, fieldPriority, fieldRecur} {
+		if from[field] == to[field] {
+			continue
+		}
+		t.History = append(t.History, Change{
+			Time:   at,
+			User:   user,
+			Action: ActionUpdated,
+			Field:  field,
+			From:   from[field],
+			To:     to[field],
+		})
+	}
+}
+
+// clone copies a task deeply enough to be changed on its own
+func (t *Task) clone() *Task {
+	c := *t
+	c.Text = append([]string(nil), t.Text...)
+	c.History = append([]Change(nil), t.History...)
+	return &c
+}
+
+// Overdue tells whether the due day of the task is over
+func (t *Task) Overdue(now time.Time) bool {
+	return !t.Closed && !t.Due.IsZero() && !now.Before(t.Due.AddDate(0, 0, 1))
+}
+
+// Owner is who hears about the task: the assignee, else its creator
+func (t *Task) Owner() string {
+	if t.Assignee != "" {
+		return t.Assignee
+	}
+	return t.CreatedBy
 }
 
 func (t *Task) String() string {
+	return t.format(time.Now())
+}
+
+func (t *Task) format(now time.Time) string {
 	out := "`" + t.ID + "` "
-	text := strings.Join(t.Text, " // ")
+	text := joinText(t.Text)
+
+	switch t.Priority {
+	case PriorityHigh:
+		out += ":red_circle: "
+	case PriorityMedium:
+		out += ":large_orange_diamond: "
+	case PriorityLow:
+		out += ":small_blue_diamond: "
+	}
 
 	if t.Closed {
 		out += "~" + text + "~"
@@ -36,5 +170,27 @@ func (t *Task) String() string {
 		out += " _" + t.ClosingNote + "_"
 	}
 
+	if t.Assignee != "" {
+		out += " → <@" + t.Assignee + ">"
+	}
+
+	if !t.Due.IsZero() {
+		due := t.Due.Format("Mon 2006-01-02")
+		if t.Overdue(now) {
+			out += " *due " + due + "*"
+		} else {
+			out += " due " + due
+		}
+	}
+
+	if t.Recur != RecurNone {
+		out += " :repeat: every " + string(t.Recur)
+	}
+
 	return out
 }
+
+// joinText joins the lines of a task, as they are appended
+func joinText(text []string) string {
+	return strings.Join(text, " // ")
+}
@@ -1,13 +1,27 @@
 package todo
 
 import (
-	"github.com/boltdb/bolt"
+	"sync"
+	"time"
+
 	"github.com/gopherworks/bawt"
+	"github.com/gorilla/mux"
 )
 
 type Plugin struct {
 	bot   *bawt.Bot
 	store Store
+	conf  Config
+	loc   *time.Location
+	once  sync.Once
+
+	// lock serializes the changes of the chat commands, the web and the
+	// reminders
+	lock sync.Mutex
+
+	// send and sendPrivate post to a channel and to a user, by ID
+	send        func(channel, text string)
+	sendPrivate func(user, text string)
 }
 
 func init() {
@@ -15,20 +29,54 @@ func init() {
 }
 
 func (p *Plugin) InitPlugin(bot *bawt.Bot) {
-	p.bot = bot
+	p.setup(bot)
 
-	err := bot.DB.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucketName)
-		return err
-	})
-	if err != nil {
-		p.bot.Logging.Logger.Fatalln("Couldn't create the `todos` bucket")
-	}
+	p.listenTodo()
+
+	go p.remindLoop()
+	go p.digestLoop()
+}
 
-	p.store = &boltStore{
-		db:  bot.DB,
-		log: bot.Logging.Logger,
-	}
+func (p *Plugin) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, pubRouter *mux.Router) {
+	p.setup(bot)
 
-	p.listenTodo()
+	p.listenWeb(privRouter)
+}
+
+// setup loads the config and opens the store, for the chat and the web
+// alike, whichever comes first
+func (p *Plugin) setup(bot *bawt.Bot) {
+	p.once.Do(func() {
+		p.bot = bot
+
+		var conf struct {
+			Todo Config
+		}
+		bot.LoadConfig(&conf)
+		p.conf = conf.Todo
+
+		loc, err := p.conf.location()
+		if err != nil {
+			bot.Logging.Logger.WithError(err).Error("Todo: invalid timezone, using the local one")
+			loc = time.Local
+		}
+		p.loc = loc
+
+		err = bot.DB.Update(createBuckets)
+		if err != nil {
+			p.bot.Logging.Logger.Fatalln("Couldn't create the `todos` bucket")
+		}
+
+		p.store = &boltStore{
+			db:  bot.DB,
+			log: bot.Logging.Logger,
+		}
+
+		p.send = func(channel, text string) {
+			bot.SendOutgoingMessage(text, channel)
+		}
+		p.sendPrivate = func(user, text string) {
+			bot.SendPrivateMessage(user, text)
+		}
+	})
 }
@@ -0,0 +1,261 @@
+package todo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Sort orders of a listing
+const (
+	sortID       = "id"
+	sortDue      = "due"
+	sortPriority = "priority"
+	sortAssignee = "assignee"
+	sortCreated  = "created"
+)
+
+// Query filters and sorts the tasks of a `!todo` listing. Assignee is a
+// Slack ID, or `none` for unassigned tasks.
+type Query struct {
+	Assignee string
+	Priority Priority
+	Overdue  bool
+	DueUntil time.Time
+	Sort     string
+}
+
+/*
+ParseQuery understands the filters and sort order of `!todo`:
+
+	mine, me, @user, assignee:@user  tasks assigned to someone
+	unassigned, assignee:none        tasks assigned to no one
+	high, medium, low, priority:...  tasks of at least that priority
+	overdue, due:overdue             tasks past their due day
+	due:today, due:week, due:<date>  tasks due until then
+	sort:due|priority|assignee|created|id
+
+`me` is the Slack ID of the user asking.
+*/
+func ParseQuery(fields []string, me string, now time.Time) (Query, error) {
+	q := Query{Sort: sortID}
+
+	for _, field := range fields {
+		key, value := "", field
+		if idx := strings.Index(field, ":"); idx > 0 && !strings.HasPrefix(field, "<") {
+			key, value = strings.ToLower(field[:idx]), field[idx+1:]
+		}
+
+		switch key {
+		case "":
+			switch lower := strings.ToLower(field); {
+			case lower == "mine" || lower == "me":
+				q.Assignee = me
+			case lower == "unassigned":
+				q.Assignee = "none"
+			case lower == "overdue":
+				q.Overdue = true
+			case strings.HasPrefix(field, "<@") || strings.HasPrefix(field, "@"):
+				q.Assignee = bawt.CleanUser(field)
+			default:
+				p, err := ParsePriority(field)
+				if err != nil || p == PriorityNone {
+					return q, fmt.Errorf("unknown filter %q", field)
+				}
+				q.Priority = p
+			}
+		case "assignee", "for":
+			switch strings.ToLower(value) {
+			case "me", "mine":
+				q.Assignee = me
+			case "none":
+				q.Assignee = "none"
+			default:
+				q.Assignee = bawt.CleanUser(value)
+			}
+		case "priority":
+			p, err := ParsePriority(value)
+			if err != nil {
+				return q, err
+			}
+			q.Priority = p
+		case "due":
+			switch strings.ToLower(value) {
+			case "overdue":
+				q.Overdue = true
+			case "week":
+				q.DueUntil = day(now).AddDate(0, 0, 7)
+			default:
+				t, err := ParseDue(value, now)
+				if err != nil {
+					return q, err
+				}
+				q.DueUntil = t
+			}
+		case "sort", "by":
+			switch s := strings.ToLower(value); s {
+			case sortID, sortDue, sortPriority, sortAssignee, sortCreated:
+				q.Sort = s
+			default:
+				return q, fmt.Errorf("unknown sort order %q, use due, priority, assignee, created or id", value)
+			}
+		default:
+			return q, fmt.Errorf("unknown filter %q", field)
+		}
+	}
+
+	return q, nil
+}
+
+// Match tells whether an open task passes the filters
+func (q Query) Match(t *Task, now time.Time) bool {
+	switch q.Assignee {
+	case "":
+	case "none":
+		if t.Assignee != "" {
+			return false
+		}
+	default:
+		if t.Assignee != q.Assignee {
+			return false
+		}
+	}
+
+	if t.Priority < q.Priority {
+		return false
+	}
+
+	if q.Overdue && !t.Overdue(now) {
+		return false
+	}
+
+	if !q.DueUntil.IsZero() && (t.Due.IsZero() || t.Due.After(q.DueUntil)) {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns the matching tasks, sorted. Tasks without a due date or
+// an assignee come last when sorting by those.
+func (q Query) Apply(todo Todo, now time.Time) Todo {
+	var out Todo
+	for _, t := range todo {
+		if q.Match(t, now) {
+			out = append(out, t)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch q.Sort {
+		case sortDue:
+			if a.Due.IsZero() != b.Due.IsZero() {
+				return b.Due.IsZero()
+			}
+			if !a.Due.Equal(b.Due) {
+				return a.Due.Before(b.Due)
+			}
+		case sortPriority:
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+		case sortAssignee:
+			if (a.Assignee == "") != (b.Assignee == "") {
+				return b.Assignee == ""
+			}
+			if a.Assignee != b.Assignee {
+				return a.Assignee < b.Assignee
+			}
+		case sortCreated:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	return out
+}
+
+/*
+ParseDue understands `today`, `tomorrow`, a weekday (`friday`, `fri`, the
+next one, today included), a number of days or weeks from now (`3d`, `2w`)
+and dates (`2006-01-02`). It returns the day at midnight, in the location
+of now.
+*/
+func ParseDue(input string, now time.Time) (time.Time, error) {
+	today := day(now)
+	in := strings.ToLower(input)
+
+	switch in {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		name := strings.ToLower(w.String())
+		if in == name || in == name[:3] {
+			return today.AddDate(0, 0, (int(w)-int(now.Weekday())+7)%7), nil
+		}
+	}
+
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if strings.HasSuffix(in, suffix) {
+			if n, err := strconv.Atoi(strings.TrimSuffix(in, suffix)); err == nil && n >= 0 {
+				return today.AddDate(0, 0, n*days), nil
+			}
+		}
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid due date %q, use YYYY-MM-DD, today, tomorrow, a weekday or a number of days like 3d", input)
+}
+
+// ParseSince understands when to look back from, for the archive: a day
+// as YYYY-MM-DD, today, yesterday, a number of days or weeks like 3d or
+// 2w, or a duration like 12h
+func ParseSince(input string, now time.Time) (time.Time, error) {
+	today := day(now)
+	in := strings.ToLower(input)
+
+	switch in {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if strings.HasSuffix(in, suffix) {
+			if n, err := strconv.Atoi(strings.TrimSuffix(in, suffix)); err == nil && n >= 0 {
+				return today.AddDate(0, 0, -n*days), nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(in); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD, today, yesterday, a number of days like 3d or a duration like 12h", input)
+}
+
+// day truncates a time to midnight, in its location
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,153 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// now is a Wednesday
+var now = time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestParseDue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   bool
+	}{
+		{input: "today", want: "2026-10-14"},
+		{input: "Tomorrow", want: "2026-10-15"},
+		{input: "wednesday", want: "2026-10-14"},
+		{input: "fri", want: "2026-10-16"},
+		{input: "monday", want: "2026-10-19"},
+		{input: "3d", want: "2026-10-17"},
+		{input: "2w", want: "2026-10-28"},
+		{input: "2026-12-24", want: "2026-12-24"},
+		{input: "someday", err: true},
+		{input: "-2d", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseDue(test.input, now)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, date(test.want), got)
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+		err   bool
+	}{
+		{input: "today", want: date("2026-10-14")},
+		{input: "yesterday", want: date("2026-10-13")},
+		{input: "3d", want: date("2026-10-11")},
+		{input: "2w", want: date("2026-09-30")},
+		{input: "12h", want: now.Add(-12 * time.Hour)},
+		{input: "2026-09-01", want: date("2026-09-01")},
+		{input: "last year", err: true},
+		{input: "-2d", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseSince(test.input, now)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Query
+		err   bool
+	}{
+		{input: "", want: Query{Sort: sortID}},
+		{input: "mine", want: Query{Assignee: "U1", Sort: sortID}},
+		{input: "<@U2> high", want: Query{Assignee: "U2", Priority: PriorityHigh, Sort: sortID}},
+		{input: "@bob priority:medium", want: Query{Assignee: "bob", Priority: PriorityMedium, Sort: sortID}},
+		{input: "unassigned overdue", want: Query{Assignee: "none", Overdue: true, Sort: sortID}},
+		{input: "due:week sort:due", want: Query{DueUntil: date("2026-10-21"), Sort: sortDue}},
+		{input: "due:tomorrow by:priority", want: Query{DueUntil: date("2026-10-15"), Sort: sortPriority}},
+		{input: "sort:color", err: true},
+		{input: "whatever", err: true},
+		{input: "none", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseQuery(strings.Fields(test.input), "U1", now)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestQuery_Apply(t *testing.T) {
+	todo := Todo{
+		{ID: "aa", Text: []string{"write"}, Assignee: "U1", Priority: PriorityLow, Due: date("2026-10-20")},
+		{ID: "bb", Text: []string{"review"}, Assignee: "U2", Priority: PriorityHigh, Due: date("2026-10-13")},
+		{ID: "cc", Text: []string{"ship"}, Priority: PriorityMedium},
+		{ID: "dd", Text: []string{"celebrate"}, Assignee: "U1", Due: date("2026-10-14")},
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{name: "all", q: Query{Sort: sortID}, want: "aa bb cc dd"},
+		{name: "assignee", q: Query{Assignee: "U1"}, want: "aa dd"},
+		{name: "unassigned", q: Query{Assignee: "none"}, want: "cc"},
+		{name: "at least medium", q: Query{Priority: PriorityMedium}, want: "bb cc"},
+		{name: "overdue", q: Query{Overdue: true}, want: "bb"},
+		{name: "due until", q: Query{DueUntil: date("2026-10-14")}, want: "bb dd"},
+		{name: "sort by due", q: Query{Sort: sortDue}, want: "bb dd aa cc"},
+		{name: "sort by priority", q: Query{Sort: sortPriority}, want: "bb cc aa dd"},
+		{name: "sort by assignee", q: Query{Sort: sortAssignee}, want: "aa dd bb cc"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, task := range test.q.Apply(todo, now) {
+				ids = append(ids, task.ID)
+			}
+			assert.Equal(t, test.want, strings.Join(ids, " "))
+		})
+	}
+}
+
+func TestTask_Format(t *testing.T) {
+	task := &Task{ID: "ab", Text: []string{"deploy"}, Assignee: "U1", Priority: PriorityHigh, Due: date("2026-10-13")}
+	assert.Equal(t, "`ab` :red_circle: deploy → <@U1> *due Tue 2026-10-13*", task.format(now))
+
+	task.Due = date("2026-10-14")
+	assert.False(t, task.Overdue(now))
+	assert.Equal(t, "`ab` :red_circle: deploy → <@U1> due Wed 2026-10-14", task.format(now))
+	assert.True(t, task.Overdue(now.AddDate(0, 0, 1)))
+}
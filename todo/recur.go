@@ -0,0 +1,95 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Recurrence tells when a task comes back once closed: every `day`,
+// `weekday`, `week`, `month` or on a given day of the week, like `monday`
+type Recurrence string
+
+// Recurrences of a task, besides the days of the week
+const (
+	RecurNone    Recurrence = ""
+	RecurDay     Recurrence = "day"
+	RecurWeekday Recurrence = "weekday"
+	RecurWeek    Recurrence = "week"
+	RecurMonth   Recurrence = "month"
+)
+
+// ParseRecurrence understands `none`, `day`, `weekday`, `week`, `month`
+// and the days of the week, in full or abbreviated like `mon`
+func ParseRecurrence(s string) (Recurrence, error) {
+	in := strings.ToLower(s)
+
+	switch Recurrence(in) {
+	case RecurDay, RecurWeekday, RecurWeek, RecurMonth:
+		return Recurrence(in), nil
+	case "none", "":
+		return RecurNone, nil
+	case "daily":
+		return RecurDay, nil
+	case "weekly":
+		return RecurWeek, nil
+	case "monthly":
+		return RecurMonth, nil
+	}
+
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		name := strings.ToLower(w.String())
+		if in == name || in == name[:3] {
+			return Recurrence(name), nil
+		}
+	}
+
+	return RecurNone, fmt.Errorf("unknown recurrence %q, use day, weekday, week, month, a day of the week or none", s)
+}
+
+// Next returns the first day the task is due again after `after`, a day
+// at midnight
+func (r Recurrence) Next(after time.Time) time.Time {
+	switch r {
+	case RecurDay:
+		return after.AddDate(0, 0, 1)
+	case RecurWeekday:
+		next := after.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case RecurWeek:
+		return after.AddDate(0, 0, 7)
+	case RecurMonth:
+		return after.AddDate(0, 1, 0)
+	}
+
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if string(r) == strings.ToLower(w.String()) {
+			days := (int(w) - int(after.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return after.AddDate(0, 0, days)
+		}
+	}
+
+	return time.Time{}
+}
+
+// splitRecurrence takes a trailing `every <recurrence>` off the text of a
+// new task
+func splitRecurrence(text string) (string, Recurrence) {
+	fields := strings.Fields(text)
+	if len(fields) < 3 || strings.ToLower(fields[len(fields)-2]) != "every" {
+		return text, RecurNone
+	}
+
+	r, err := ParseRecurrence(fields[len(fields)-1])
+	if err != nil || r == RecurNone {
+		return text, RecurNone
+	}
+
+	return strings.Join(fields[:len(fields)-2], " "), r
+}
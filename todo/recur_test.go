@@ -0,0 +1,59 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrence_Next(t *testing.T) {
+	tests := []struct {
+		input string
+		after string
+		want  string
+		err   bool
+	}{
+		{input: "day", after: "2026-10-14", want: "2026-10-15"},
+		{input: "daily", after: "2026-10-14", want: "2026-10-15"},
+		{input: "weekday", after: "2026-10-16", want: "2026-10-19"},
+		{input: "week", after: "2026-10-14", want: "2026-10-21"},
+		{input: "month", after: "2026-10-14", want: "2026-11-14"},
+		{input: "Monday", after: "2026-10-14", want: "2026-10-19"},
+		{input: "wed", after: "2026-10-14", want: "2026-10-21"},
+		{input: "fortnight", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			r, err := ParseRecurrence(test.input)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, date(test.want), r.Next(date(test.after)))
+		})
+	}
+}
+
+func TestSplitRecurrence(t *testing.T) {
+	tests := []struct {
+		input string
+		text  string
+		recur Recurrence
+	}{
+		{input: "water the plants every monday", text: "water the plants", recur: "monday"},
+		{input: "stand up every weekday", text: "stand up", recur: RecurWeekday},
+		{input: "every day", text: "every day"},
+		{input: "call bob every now and then", text: "call bob every now and then"},
+		{input: "eat every apple", text: "eat every apple"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			text, recur := splitRecurrence(test.input)
+			assert.Equal(t, test.text, text)
+			assert.Equal(t, test.recur, recur)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package todo
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// remindCheckInterval is how often overdue tasks are looked for
+const remindCheckInterval = 10 * time.Minute
+
+func (p *Plugin) remindLoop() {
+	every, err := p.conf.remindEvery()
+	if err != nil {
+		p.bot.Logging.Logger.WithError(err).Error("Todo: invalid remind_every, reminders are disabled")
+		return
+	}
+	if every <= 0 {
+		return
+	}
+
+	for {
+		p.remind(time.Now().In(p.loc), every)
+		time.Sleep(remindCheckInterval)
+	}
+}
+
+// remind sends each owner of overdue tasks a single private message
+// listing those that weren't reminded during the last `every`
+func (p *Plugin) remind(now time.Time, every time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	reminders := map[string][]string{}
+
+	for _, channel := range p.store.Channels() {
+		todo := p.store.Get(channel)
+		changed := false
+
+		for _, task := range todo {
+			if !task.Overdue(now) || now.Sub(task.RemindedAt) < every {
+				continue
+			}
+
+			owner := task.Owner()
+			t := func(key string, args ...interface{}) string {
+				return p.bot.T(p.userLocale(owner), key, args...)
+			}
+			reminders[owner] = append(reminders[owner], p.listLabel(t, channel)+" "+task.format(now))
+			task.RemindedAt = now
+			changed = true
+		}
+
+		if changed {
+			p.store.Put(channel, todo)
+		}
+	}
+
+	for owner, lines := range reminders {
+		sort.Strings(lines)
+		text := p.bot.T(p.userLocale(owner), "todo.reminder", len(lines)) + "\n" + strings.Join(lines, "\n")
+		p.sendPrivate(owner, text)
+	}
+}
+
+func (p *Plugin) digestLoop() {
+	hour, min, err := p.conf.digestAt()
+	if err != nil {
+		p.bot.Logging.Logger.WithError(err).Error("Todo: digests are disabled")
+		return
+	}
+
+	for {
+		now := time.Now().In(p.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, p.loc)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		time.Sleep(next.Sub(now))
+		p.digest(time.Now().In(p.loc))
+	}
+}
+
+// digest posts to the channels which opted in the overdue tasks, those
+// due within a week, and how many other tasks are open
+func (p *Plugin) digest(now time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	week := day(now).AddDate(0, 0, 7)
+
+	for _, channel := range p.store.Channels() {
+		if isPersonal(channel) || !p.store.Digest(channel) {
+			continue
+		}
+
+		open := Query{Sort: sortDue}.Apply(openTasks(p.store.Get(channel)), now)
+		if len(open) == 0 {
+			continue
+		}
+
+		locale := p.channelLocale(channel)
+
+		var overdue, soon []string
+		for _, task := range open {
+			switch {
+			case task.Overdue(now):
+				overdue = append(overdue, task.format(now))
+			case !task.Due.IsZero() && !task.Due.After(week):
+				soon = append(soon, task.format(now))
+			}
+		}
+
+		lines := []string{p.bot.T(locale, "todo.digest", len(open))}
+		if len(overdue) > 0 {
+			lines = append(lines, p.bot.T(locale, "todo.digest.overdue", len(overdue)))
+			lines = append(lines, overdue...)
+		}
+		if len(soon) > 0 {
+			lines = append(lines, p.bot.T(locale, "todo.digest.soon", len(soon)))
+			lines = append(lines, soon...)
+		}
+		if rest := len(open) - len(overdue) - len(soon); rest > 0 {
+			lines = append(lines, p.bot.T(locale, "todo.digest.rest", rest))
+		}
+
+		p.send(channel, strings.Join(lines, "\n"))
+	}
+}
+
+// openTasks leaves the closed tasks out
+func openTasks(todo Todo) Todo {
+	var open Todo
+	for _, task := range todo {
+		if !task.Closed {
+			open = append(open, task)
+		}
+	}
+	return open
+}
+
+func (p *Plugin) userLocale(id string) string {
+	if user, ok := p.bot.Users.Get(id); ok {
+		return p.bot.LocaleFor(&user, nil)
+	}
+	return p.bot.LocaleFor(nil, nil)
+}
+
+func (p *Plugin) channelLocale(id string) string {
+	if channel, ok := p.bot.Channels.Get(id); ok {
+		return p.bot.LocaleFor(nil, &channel)
+	}
+	return p.bot.LocaleFor(nil, nil)
+}
@@ -0,0 +1,100 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPlugin(t *testing.T) (*Plugin, *[]bawttest.Sent) {
+	db := bawttest.NewDB(t, createBuckets)
+	bot := bawttest.NewBot(db)
+
+	var out []bawttest.Sent
+	p := &Plugin{
+		bot:         bot,
+		store:       &boltStore{db: db, log: bot.Logging.Logger},
+		loc:         time.UTC,
+		send:        bawttest.Recorder(&out),
+		sendPrivate: bawttest.Recorder(&out),
+	}
+
+	return p, &out
+}
+
+func TestRemind(t *testing.T) {
+	p, out := newTestPlugin(t)
+
+	p.store.Put("C1", Todo{
+		{ID: "aa", Text: []string{"late"}, CreatedBy: "U1", Due: date("2026-10-12")},
+		{ID: "bb", Text: []string{"later"}, CreatedBy: "U1", Assignee: "U2", Due: date("2026-10-13")},
+		{ID: "cc", Text: []string{"on time"}, CreatedBy: "U1", Due: date("2026-10-14")},
+		{ID: "dd", Text: []string{"done"}, CreatedBy: "U1", Due: date("2026-10-01"), Closed: true},
+	})
+	p.store.Put("C2", Todo{
+		{ID: "aa", Text: []string{"also late"}, CreatedBy: "U3", Assignee: "U2", Due: date("2026-10-10")},
+	})
+
+	p.remind(now, 24*time.Hour)
+
+	if assert.Len(t, *out, 2) {
+		byUser := map[string]string{}
+		for _, s := range *out {
+			byUser[s.To] = s.Text
+		}
+		assert.Contains(t, byUser["U1"], "This task of yours is overdue:")
+		assert.Contains(t, byUser["U1"], "<#C1> `aa` late")
+		assert.Contains(t, byUser["U2"], "These 2 tasks of yours are overdue:")
+		assert.Contains(t, byUser["U2"], "<#C1> `bb` later")
+		assert.Contains(t, byUser["U2"], "<#C2> `aa` also late")
+	}
+	assert.Equal(t, now, p.store.Get("C1")[0].RemindedAt)
+
+	// Nothing new within a day
+	*out = nil
+	p.remind(now.Add(12*time.Hour), 24*time.Hour)
+	assert.Empty(t, *out)
+
+	p.remind(now.Add(24*time.Hour), 24*time.Hour)
+	assert.Len(t, *out, 2)
+}
+
+func TestDigest(t *testing.T) {
+	p, out := newTestPlugin(t)
+
+	p.store.Put("C1", Todo{
+		{ID: "aa", Text: []string{"late"}, Due: date("2026-10-12")},
+		{ID: "bb", Text: []string{"soon"}, Due: date("2026-10-16")},
+		{ID: "cc", Text: []string{"whenever"}},
+		{ID: "dd", Text: []string{"done"}, Closed: true},
+	})
+	p.store.Put("C2", Todo{
+		{ID: "aa", Text: []string{"quiet"}},
+	})
+	p.store.Put("C3", Todo{
+		{ID: "aa", Text: []string{"done"}, Closed: true},
+	})
+	p.store.SetDigest("C1", true)
+	p.store.SetDigest("C3", true)
+
+	p.digest(now)
+
+	if assert.Len(t, *out, 1) {
+		assert.Equal(t, "C1", (*out)[0].To)
+		assert.Equal(t, strings.Join([]string{
+			"*To do digest*: 3 open tasks",
+			"*1 overdue*",
+			"`aa` late *due Mon 2026-10-12*",
+			"1 due this week",
+			"`bb` soon due Fri 2026-10-16",
+			"and 1 more, see `!todo`",
+		}, "\n"), (*out)[0].Text)
+	}
+
+	p.store.SetDigest("C1", false)
+	assert.False(t, p.store.Digest("C1"))
+	assert.ElementsMatch(t, []string{"C1", "C2", "C3"}, p.store.Channels())
+}
@@ -1,7 +1,11 @@
 package todo
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -11,6 +15,35 @@ import (
 type Store interface {
 	Get(channel string) Todo
 	Put(channel string, t Todo)
+	// Channels lists the channels having a todo list
+	Channels() []string
+	// Digest tells whether the channel gets the daily digest
+	Digest(channel string) bool
+	SetDigest(channel string, enabled bool)
+
+	// Archive keeps a closed task, and Unarchive takes it back
+	Archive(channel string, t *Task) error
+	Unarchive(channel string, t *Task) error
+	// Archived returns the tasks closed since a time, oldest first
+	Archived(channel string, since time.Time) (Todo, error)
+	// FindArchived returns the last closed task with the given ID
+	FindArchived(channel, id string) (*Task, error)
+
+	// PushUndo records a change, and PopUndo takes back the last one
+	PushUndo(channel string, u Undo) error
+	PopUndo(channel string) (*Undo, error)
+}
+
+// Undo records how to revert a change to a task. Before is the task
+// before the change, nil when it was created, and List is where a moved
+// task went.
+type Undo struct {
+	Time   time.Time
+	User   string
+	Action string
+	TaskID string
+	Before *Task
+	List   string `json:",omitempty"`
 }
 
 type boltStore struct {
@@ -18,7 +51,24 @@ type boltStore struct {
 	log *logrus.Logger
 }
 
-var bucketName = []byte("todos")
+var (
+	bucketName        = []byte("todos")
+	digestBucketName  = []byte("todo_digests")
+	archiveBucketName = []byte("todo_archive")
+	undoBucketName    = []byte("todo_undo")
+)
+
+// maxUndo is the number of changes which can be undone in a channel
+const maxUndo = 50
+
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bucketName, digestBucketName, archiveBucketName, undoBucketName} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 func (s *boltStore) Get(channel string) (t Todo) {
 	err := s.db.View(func(tx *bolt.Tx) error {
@@ -48,3 +98,180 @@ func (s *boltStore) Put(channel string, t Todo) {
 		s.log.Println("ERROR saving Todo:", err)
 	}
 }
+
+func (s *boltStore) Channels() []string {
+	var channels []string
+	s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(k, _ []byte) error {
+			channels = append(channels, string(k))
+			return nil
+		})
+	})
+	return channels
+}
+
+func (s *boltStore) Digest(channel string) bool {
+	enabled := false
+	s.db.View(func(tx *bolt.Tx) error {
+		enabled = tx.Bucket(digestBucketName).Get([]byte(channel)) != nil
+		return nil
+	})
+	return enabled
+}
+
+func (s *boltStore) SetDigest(channel string, enabled bool) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(digestBucketName)
+		if enabled {
+			return b.Put([]byte(channel), []byte("on"))
+		}
+		return b.Delete([]byte(channel))
+	})
+	if err != nil {
+		s.log.Println("ERROR saving the Todo digest setting:", err)
+	}
+}
+
+// archiveKey sorts the archived tasks by closing time
+func archiveKey(t *Task) []byte {
+	return []byte(fmt.Sprintf("%020d-%s", t.ClosedAt.UnixNano(), t.ID))
+}
+
+func (s *boltStore) Archive(channel string, t *Task) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(archiveBucketName).CreateBucketIfNotExists([]byte(channel))
+		if err != nil {
+			return err
+		}
+
+		cnt, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(archiveKey(t), cnt)
+	})
+}
+
+func (s *boltStore) Unarchive(channel string, t *Task) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(archiveBucketName).Bucket([]byte(channel))
+		if b == nil {
+			return nil
+		}
+		return b.Delete(archiveKey(t))
+	})
+}
+
+func (s *boltStore) Archived(channel string, since time.Time) (Todo, error) {
+	todo := make(Todo, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(archiveBucketName).Bucket([]byte(channel))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek([]byte(fmt.Sprintf("%020d", since.UnixNano()))); k != nil; k, v = c.Next() {
+			var t Task
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			todo = append(todo, &t)
+		}
+
+		return nil
+	})
+
+	return todo, err
+}
+
+func (s *boltStore) FindArchived(channel, id string) (*Task, error) {
+	var found *Task
+	suffix := []byte("-" + id)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(archiveBucketName).Bucket([]byte(channel))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if !bytes.HasSuffix(k, suffix) {
+				continue
+			}
+
+			found = &Task{}
+			return json.Unmarshal(v, found)
+		}
+
+		return nil
+	})
+
+	return found, err
+}
+
+func (s *boltStore) PushUndo(channel string, u Undo) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(undoBucketName).CreateBucketIfNotExists([]byte(channel))
+		if err != nil {
+			return err
+		}
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+
+		cnt, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, cnt); err != nil {
+			return err
+		}
+
+		// Forget the oldest changes
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+		for i := 0; i < len(keys)-maxUndo; i++ {
+			if err := b.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *boltStore) PopUndo(channel string) (*Undo, error) {
+	var u *Undo
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(undoBucketName).Bucket([]byte(channel))
+		if b == nil {
+			return nil
+		}
+
+		k, v := b.Cursor().Last()
+		if k == nil {
+			return nil
+		}
+
+		u = &Undo{}
+		if err := json.Unmarshal(v, u); err != nil {
+			return err
+		}
+
+		return b.Delete(k)
+	})
+
+	return u, err
+}
@@ -0,0 +1,309 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+var (
+	errNotFound    = errors.New("task not found")
+	errNothingToDo = errors.New("nothing to undo")
+	errUnknownUser = errors.New("unknown user")
+	errNoText      = errors.New("a task needs some text")
+)
+
+// Fields of a task which can be set, by chat or by the API
+const (
+	fieldText     = "text"
+	fieldAssignee = "assign"
+	fieldDue      = "due"
+	fieldPriority = "priority"
+	fieldRecur    = "every"
+)
+
+// addTask creates a task in the list of a channel, with its other fields
+// set by f, if any. The caller holds the lock.
+func (p *Plugin) addTask(channel, user, text string, f func(*Task) error) (*Task, error) {
+	todo := p.store.Get(channel)
+
+	now := time.Now()
+	task := &Task{
+		ID:        p.generateRandomID(todo),
+		CreatedAt: now,
+		CreatedBy: user,
+		Text:      []string{text},
+		History:   []Change{{Time: now, User: user, Action: ActionCreated}},
+	}
+	if f != nil {
+		if err := f(task); err != nil {
+			return nil, err
+		}
+	}
+	todo = append(todo, task)
+	p.store.Put(channel, todo)
+	p.pushUndo(channel, user, ActionCreated, task.ID, nil)
+	p.publish(channel, user, ActionCreated, task)
+
+	return task, nil
+}
+
+// editTask changes a task of a channel with f, and saves it unless f
+// fails. The caller holds the lock.
+func (p *Plugin) editTask(channel, user, id string, f func(*Task) error) (*Task, error) {
+	todo := p.store.Get(channel)
+	index, err := getTaskIndex(id, todo)
+	if err != nil {
+		return nil, errNotFound
+	}
+
+	task := todo[index]
+	before := task.clone()
+	if err := f(task); err != nil {
+		return nil, err
+	}
+	task.record(before, user, time.Now())
+
+	p.store.Put(channel, todo)
+	p.pushUndo(channel, user, ActionUpdated, task.ID, before)
+	p.publish(channel, user, ActionUpdated, task)
+
+	return task, nil
+}
+
+// closeTask closes a task of a channel and moves it from the list to the
+// archive. A recurring task is reopened right away, due on its next
+// occurrence. The caller holds the lock.
+func (p *Plugin) closeTask(channel, user, id, note string) (*Task, error) {
+	todo := p.store.Get(channel)
+	index, err := getTaskIndex(id, todo)
+	if err != nil {
+		return nil, errNotFound
+	}
+
+	task := todo[index]
+	before := task.clone()
+	now := time.Now()
+	task.Closed = true
+	task.ClosingNote = note
+	task.ClosedBy = user
+	task.ClosedAt = now
+	task.History = append(task.History, Change{Time: now, User: user, Action: ActionClosed, To: note})
+
+	if err := p.store.Archive(channel, task); err != nil {
+		return nil, err
+	}
+	if task.Recur != RecurNone {
+		todo[index] = p.reopen(before, now)
+	} else {
+		todo = append(todo[:index], todo[index+1:]...)
+	}
+	p.store.Put(channel, todo)
+	p.pushUndo(channel, user, ActionClosed, task.ID, before)
+	p.publish(channel, user, ActionClosed, task)
+	if task.Recur != RecurNone {
+		p.publish(channel, user, ActionReopened, todo[index])
+	}
+
+	return task, nil
+}
+
+// reopen returns the next occurrence of a recurring task, due on the
+// first day it recurs after today or after it was due
+func (p *Plugin) reopen(task *Task, now time.Time) *Task {
+	next := task.clone()
+	after := day(now.In(p.loc))
+	if task.Due.After(after) {
+		after = task.Due
+	}
+	next.Due = task.Recur.Next(after)
+	next.RemindedAt = time.Time{}
+	next.History = append(next.History, Change{Time: now, Action: ActionReopened, To: next.Due.Format("2006-01-02")})
+	return next
+}
+
+// archiveClosed moves the tasks closed before the archive existed out of
+// the list of a channel. The caller holds the lock.
+func (p *Plugin) archiveClosed(channel string) {
+	todo := p.store.Get(channel)
+	open := openTasks(todo)
+	if len(open) == len(todo) {
+		return
+	}
+
+	for _, task := range todo {
+		if !task.Closed {
+			continue
+		}
+		if task.ClosedAt.IsZero() {
+			task.ClosedAt = task.CreatedAt
+		}
+		if err := p.store.Archive(channel, task); err != nil {
+			p.bot.ReportError("todo", err)
+			return
+		}
+	}
+	p.store.Put(channel, open)
+}
+
+// undoLast reverts the last change made to the tasks of a channel, and
+// returns what it was along with the task as it is now. The caller holds
+// the lock.
+func (p *Plugin) undoLast(channel, user string) (*Undo, *Task, error) {
+	u, err := p.store.PopUndo(channel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u == nil {
+		return nil, nil, errNothingToDo
+	}
+
+	now := time.Now()
+	undone := Change{Time: now, User: user, Action: ActionUndone, From: u.Action}
+	todo := p.store.Get(channel)
+	index, findErr := getTaskIndex(u.TaskID, todo)
+
+	var task *Task
+	switch u.Action {
+	case ActionCreated:
+		if findErr != nil {
+			return u, nil, errNotFound
+		}
+		task = todo[index]
+		todo = append(todo[:index], todo[index+1:]...)
+
+	case ActionUpdated:
+		if findErr != nil {
+			return u, nil, errNotFound
+		}
+		task = u.Before.clone()
+		task.History = append(todo[index].History, undone)
+		todo[index] = task
+
+	case ActionClosed:
+		closed, err := p.store.FindArchived(channel, u.TaskID)
+		if err != nil {
+			return u, nil, err
+		}
+		if closed == nil {
+			return u, nil, errNotFound
+		}
+		if err := p.store.Unarchive(channel, closed); err != nil {
+			return u, nil, err
+		}
+
+		task = u.Before.clone()
+		task.History = append(closed.History, undone)
+		switch {
+		case findErr != nil:
+			todo = append(todo, task)
+		case todo[index].Recur != RecurNone && todo[index].CreatedAt.Equal(task.CreatedAt):
+			// Takes the place of the next occurrence
+			todo[index] = task
+		default:
+			task.ID = p.generateRandomID(todo)
+			todo = append(todo, task)
+		}
+
+	case ActionMoved:
+		dst := p.store.Get(u.List)
+		i, err := getTaskIndex(u.TaskID, dst)
+		if err != nil {
+			return u, nil, errNotFound
+		}
+		task = dst[i]
+		p.store.Put(u.List, append(dst[:i], dst[i+1:]...))
+
+		task.ID = u.Before.ID
+		if idInList(task.ID, todo) {
+			task.ID = p.generateRandomID(todo)
+		}
+		task.History = append(task.History, undone)
+		todo = append(todo, task)
+	}
+
+	p.store.Put(channel, todo)
+	p.publish(channel, user, ActionUndone, task)
+
+	return u, task, nil
+}
+
+// pushUndo records a change so it can be undone. A moved task also gives
+// the list it went to.
+func (p *Plugin) pushUndo(channel, user, action, id string, before *Task, list ...string) {
+	u := Undo{
+		Time:   time.Now(),
+		User:   user,
+		Action: action,
+		TaskID: id,
+		Before: before,
+	}
+	if len(list) != 0 {
+		u.List = list[0]
+	}
+	err := p.store.PushUndo(channel, u)
+	if err != nil {
+		p.bot.ReportError("todo", err)
+	}
+}
+
+// setTaskField sets a field of a task from its textual value, as typed in
+// chat or the web UI. `me` is the Slack ID of the user asking.
+func (p *Plugin) setTaskField(task *Task, field, value, me string) error {
+	switch field {
+	case fieldText:
+		if strings.TrimSpace(value) == "" {
+			return errNoText
+		}
+		task.Text = strings.Split(value, " // ")
+
+	case fieldAssignee:
+		switch strings.ToLower(value) {
+		case "none", "":
+			task.Assignee = ""
+		case "me":
+			task.Assignee = me
+		default:
+			user, ok := p.userID(bawt.CleanUser(value))
+			if !ok {
+				return errUnknownUser
+			}
+			task.Assignee = user
+		}
+
+	case fieldDue:
+		if v := strings.ToLower(value); v == "none" || v == "" {
+			task.Due = time.Time{}
+			break
+		}
+
+		due, err := ParseDue(value, time.Now().In(p.loc))
+		if err != nil {
+			return err
+		}
+		task.Due = due
+
+	case fieldPriority:
+		prio, err := ParsePriority(value)
+		if err != nil {
+			return err
+		}
+		task.Priority = prio
+
+	case fieldRecur:
+		recur, err := ParseRecurrence(value)
+		if err != nil {
+			return err
+		}
+		task.Recur = recur
+	}
+
+	// A new owner or due date deserves a new reminder
+	if field == fieldAssignee || field == fieldDue {
+		task.RemindedAt = time.Time{}
+	}
+
+	return nil
+}
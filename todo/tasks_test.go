@@ -0,0 +1,153 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskHistory(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	task, err := p.addTask("C1", "U1", "write docs", nil)
+	assert.NoError(t, err)
+
+	_, err = p.editTask("C1", "U2", task.ID, func(task *Task) error {
+		return p.setTaskField(task, fieldPriority, "high", "U2")
+	})
+	assert.NoError(t, err)
+
+	closed, err := p.closeTask("C1", "U3", task.ID, "shipped")
+	assert.NoError(t, err)
+	assert.Equal(t, "U3", closed.ClosedBy)
+	assert.False(t, closed.ClosedAt.IsZero())
+	assert.Empty(t, p.store.Get("C1"))
+
+	archived, err := p.store.FindArchived("C1", task.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, archived) {
+		assert.Equal(t, []Change{
+			{User: "U1", Action: ActionCreated},
+			{User: "U2", Action: ActionUpdated, Field: fieldPriority, From: "none", To: "high"},
+			{User: "U3", Action: ActionClosed, To: "shipped"},
+		}, withoutTime(archived.History))
+	}
+
+	done, err := p.store.Archived("C1", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+
+	done, err = p.store.Archived("C1", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+}
+
+func TestUndo(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	_, _, err := p.undoLast("C1", "U1")
+	assert.Equal(t, errNothingToDo, err)
+
+	first, _ := p.addTask("C1", "U1", "first", nil)
+	second, _ := p.addTask("C1", "U1", "second", nil)
+	p.editTask("C1", "U1", first.ID, func(task *Task) error {
+		return p.setTaskField(task, fieldText, "first, renamed", "U1")
+	})
+	p.closeTask("C1", "U2", first.ID, "")
+
+	// Reopens the closed task
+	u, task, err := p.undoLast("C1", "U2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ActionClosed, u.Action)
+		assert.False(t, task.Closed)
+		assert.Equal(t, []string{"first, renamed"}, task.Text)
+	}
+	assert.Len(t, p.store.Get("C1"), 2)
+	archived, _ := p.store.FindArchived("C1", first.ID)
+	assert.Nil(t, archived)
+
+	// Reverts the edit
+	u, task, err = p.undoLast("C1", "U2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ActionUpdated, u.Action)
+		assert.Equal(t, []string{"first"}, task.Text)
+		assert.Equal(t, []Change{
+			{User: "U1", Action: ActionCreated},
+			{User: "U1", Action: ActionUpdated, Field: fieldText, From: "first", To: "first, renamed"},
+			{User: "U2", Action: ActionClosed},
+			{User: "U2", Action: ActionUndone, From: ActionClosed},
+			{User: "U2", Action: ActionUndone, From: ActionUpdated},
+		}, withoutTime(task.History))
+	}
+
+	// Removes the second task
+	u, _, err = p.undoLast("C1", "U2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ActionCreated, u.Action)
+	}
+	todo := p.store.Get("C1")
+	if assert.Len(t, todo, 1) {
+		assert.NotEqual(t, second.ID, todo[0].ID)
+	}
+}
+
+func TestUndo_Limit(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	for i := 0; i < maxUndo+5; i++ {
+		p.addTask("C1", "U1", "task", nil)
+	}
+	for i := 0; i < maxUndo; i++ {
+		_, _, err := p.undoLast("C1", "U1")
+		assert.NoError(t, err)
+	}
+	_, _, err := p.undoLast("C1", "U1")
+	assert.Equal(t, errNothingToDo, err)
+	assert.Len(t, p.store.Get("C1"), 5)
+}
+
+func TestArchiveClosed(t *testing.T) {
+	p, _ := newTestPlugin(t)
+
+	p.store.Put("C1", Todo{
+		{ID: "aa", Text: []string{"open"}},
+		{ID: "bb", Text: []string{"done"}, CreatedAt: now, Closed: true},
+	})
+
+	p.archiveClosed("C1")
+
+	todo := p.store.Get("C1")
+	if assert.Len(t, todo, 1) {
+		assert.Equal(t, "aa", todo[0].ID)
+	}
+	done, _ := p.store.Archived("C1", now.Add(-time.Hour))
+	if assert.Len(t, done, 1) {
+		assert.Equal(t, "bb", done[0].ID)
+		assert.Equal(t, now, done[0].ClosedAt.UTC())
+	}
+}
+
+func TestGenerateRandomID(t *testing.T) {
+	p := &Plugin{}
+	tasks := func(n int) Todo {
+		todo := make(Todo, n)
+		for i := range todo {
+			todo[i] = &Task{}
+		}
+		return todo
+	}
+
+	assert.Len(t, p.generateRandomID(tasks(10)), 2)
+	assert.Len(t, p.generateRandomID(tasks(400)), 3)
+	assert.Len(t, p.generateRandomID(tasks(10000)), 4)
+}
+
+// withoutTime clears the times of changes, to compare the rest
+func withoutTime(history []Change) []Change {
+	var out []Change
+	for _, c := range history {
+		c.Time = time.Time{}
+		out = append(out, c)
+	}
+	return out
+}
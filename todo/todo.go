@@ -3,7 +3,6 @@ package todo
 
 import (
 	"errors"
-	"fmt"
 	"math/rand"
 	"regexp"
 	"sort"
@@ -17,6 +16,7 @@ func (p *Plugin) listenTodo() {
 	p.bot.Listen(&bawt.Listener{
 		Matches:            regexp.MustCompile(`^!todo.*`),
 		MessageHandlerFunc: p.handleTodo,
+		MainWorkspaceOnly:  true,
 		Name:               "To Do",
 		Description:        "Keeps a tab of all your to do's!",
 		Commands: []bawt.Command{
@@ -24,9 +24,13 @@ func (p *Plugin) listenTodo() {
 				Usage:    "!todo",
 				HelpText: "Displays a list of tasks",
 			},
+			{
+				Usage:    "!todo [mine|@user|unassigned] [high|medium|low] [overdue|due:today|due:week] [sort:due|priority|assignee|created]",
+				HelpText: "Displays the tasks matching the filters, in the given order",
+			},
 			{
 				Usage:    "!todo add <some text>",
-				HelpText: "Displays a list of tasks",
+				HelpText: "Adds a task",
 			},
 			{
 				Usage:    "!todo scratch <id>",
@@ -36,15 +40,73 @@ func (p *Plugin) listenTodo() {
 				Usage:    "!todo append <id> <some text>",
 				HelpText: "Adds to the end of a task",
 			},
+			{
+				Usage:    "!todo assign <id> @user|me|none",
+				HelpText: "Sets who owns a task, reminded privately once it's overdue",
+			},
+			{
+				Usage:    "!todo due <id> <date>|none",
+				HelpText: "Sets the day a task is due: YYYY-MM-DD, today, tomorrow, a weekday or a number of days like 3d",
+			},
+			{
+				Usage:    "!todo priority <id> high|medium|low|none",
+				HelpText: "Sets the priority of a task",
+			},
+			{
+				Usage:    "!todo done [since]",
+				HelpText: "Lists the tasks closed since a date or for a while like 2w, by default the last 7 days",
+			},
+			{
+				Usage:    "!todo history <id>",
+				HelpText: "Shows who changed a task and when, even once closed",
+			},
+			{
+				Usage:    "!todo undo",
+				HelpText: "Undoes the last change to the channel's tasks",
+			},
+			{
+				Usage:    "!todo every <id> day|weekday|week|month|<weekday>|none",
+				HelpText: "Makes a task recurring: once closed, it reopens due on its next occurrence. `!todo add <some text> every monday` works too",
+			},
+			{
+				Usage:    "!todo me [command]",
+				HelpText: "Runs any command on your personal list rather than the channel's, like `!todo me add <some text>`. In private, commands work on your personal list",
+			},
+			{
+				Usage:    "!todo move <id> me|#channel",
+				HelpText: "Moves a task to your personal list or another channel",
+			},
+			{
+				Usage:    "!todo overview [@user]",
+				HelpText: "Lists the open tasks assigned to or created by you, or a user, in every channel",
+			},
+			{
+				Usage:    "!todo digest [on|off]",
+				HelpText: "Turns the daily digest of the channel's tasks on or off",
+			},
 		},
 	})
 }
 
 func (p *Plugin) handleTodo(listen *bawt.Listener, msg *bawt.Message) {
-	idFormat := regexp.MustCompile(`^[a-z]{2}$`)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	idFormat := regexp.MustCompile(`^[a-z]{2,}$`)
 	parts := strings.Split(msg.Match[0], " ")
+
+	// `!todo me ...` works on the personal list, like anything in private
+	list := msg.Channel
+	if msg.IsPrivate() {
+		list = personalList(msg.FromUser.ID)
+	}
+	if len(parts) > 1 && parts[1] == "me" {
+		list = personalList(msg.FromUser.ID)
+		parts = append(parts[:1], parts[2:]...)
+	}
+
 	if len(parts) == 1 {
-		p.listTasks(msg)
+		p.listTasks(msg, list, Query{Sort: sortID})
 		return
 	}
 	act := parts[1]
@@ -52,144 +114,336 @@ func (p *Plugin) handleTodo(listen *bawt.Listener, msg *bawt.Message) {
 	switch act {
 	case "add":
 		if len(parts) < 2 {
-			msg.ReplyMention("Add a task with `!todo add [some text]`")
+			msg.ReplyMentionT("todo.add.usage")
 			return
 		}
-		p.createTask(msg, strings.Join(parts[2:], " "))
+		p.createTask(msg, list, strings.Join(parts[2:], " "))
 
 	case "scratch":
 		if len(parts) < 3 || !idFormat.MatchString(parts[2]) {
-			msg.ReplyMention(fmt.Sprintf("Please %s a task with `!todo %s ID`", act, act))
+			msg.ReplyMentionT("todo.id.usage", act)
 			return
 		}
 
-		p.deleteTask(msg, parts[2], false)
+		p.deleteTask(msg, list, parts[2], strings.Join(parts[3:], " "))
 
 	case "append":
 		if len(parts) < 4 || !idFormat.MatchString(parts[2]) {
-			msg.ReplyMention(fmt.Sprintf("Please %s a task with `!todo %s ID [more notes]`", act, act))
+			msg.ReplyMentionT("todo.append.usage", act)
+			return
+		}
+
+		p.appendToTask(msg, list, parts[2], strings.Join(parts[3:], " "))
+
+	case fieldAssignee, fieldDue, fieldPriority, fieldRecur:
+		if len(parts) < 4 || !idFormat.MatchString(parts[2]) {
+			msg.ReplyMentionT("todo." + act + ".usage")
 			return
 		}
 
-		p.appendToTask(msg, parts[2], strings.Join(parts[3:], " "))
+		p.setField(msg, list, act, parts[2], parts[3])
+
+	case "done":
+		p.listDone(msg, list, parts[2:])
+
+	case "history":
+		if len(parts) < 3 || !idFormat.MatchString(parts[2]) {
+			msg.ReplyMentionT("todo.history.usage")
+			return
+		}
+
+		p.showHistory(msg, list, parts[2])
+
+	case "undo":
+		p.undo(msg, list)
+
+	case "move":
+		if len(parts) < 4 || !idFormat.MatchString(parts[2]) {
+			msg.ReplyMentionT("todo.move.usage")
+			return
+		}
+
+		p.move(msg, list, parts[2], parts[3])
+
+	case "overview":
+		p.showOverview(msg, parts[2:])
+
+	case "digest":
+		if isPersonal(list) {
+			msg.ReplyMentionT("todo.digest.personal")
+			return
+		}
+		p.setDigest(msg, parts[2:])
 
 	case "help":
 		p.replyHelp(msg, "")
 
 	default:
-		if idFormat.MatchString(act) {
-			p.replyHelp(msg, "Wooops, not sure what you wanted.\n")
+		q, err := ParseQuery(strings.Fields(strings.Join(parts[1:], " ")), msg.FromUser.ID, time.Now().In(p.loc))
+		if err != nil {
+			if idFormat.MatchString(act) {
+				p.replyHelp(msg, msg.T("todo.unknown"))
+			} else {
+				msg.ReplyMentionT("todo.bad_query", err.Error())
+			}
+			return
+		}
+
+		if q.Assignee != "" && q.Assignee != "none" {
+			id, ok := p.userID(q.Assignee)
+			if !ok {
+				msg.ReplyMentionT("todo.user_not_found", q.Assignee)
+				return
+			}
+			q.Assignee = id
+		}
+
+		p.listTasks(msg, list, q)
+	}
+}
+
+// setField sets the assignee, due date, priority or recurrence of a task
+func (p *Plugin) setField(msg *bawt.Message, list, field, id, value string) {
+	task, err := p.editTask(list, msg.FromUser.ID, id, func(task *Task) error {
+		return p.setTaskField(task, field, value, msg.FromUser.ID)
+	})
+	switch err {
+	case nil:
+		msg.ReplyMentionT("todo.updated", task.String())
+	case errNotFound:
+		msg.ReplyMentionT("todo.not_found")
+	case errUnknownUser:
+		msg.ReplyMentionT("todo.user_not_found", value)
+	default:
+		msg.ReplyMentionT("todo.bad_query", err.Error())
+	}
+}
+
+// setDigest turns the daily digest of the channel on or off, or tells
+// whether it's on
+func (p *Plugin) setDigest(msg *bawt.Message, args []string) {
+	if len(args) == 0 || args[0] == "" {
+		if p.store.Digest(msg.Channel) {
+			msg.ReplyT("todo.digest.on", p.digestTime())
 		} else {
-			p.listTasks(msg)
+			msg.ReplyT("todo.digest.off")
 		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		p.store.SetDigest(msg.Channel, true)
+		msg.ReplyT("todo.digest.on", p.digestTime())
+	case "off":
+		p.store.SetDigest(msg.Channel, false)
+		msg.ReplyT("todo.digest.off")
+	default:
+		msg.ReplyMentionT("todo.digest.usage")
+	}
+}
+
+func (p *Plugin) digestTime() string {
+	hour, min, err := p.conf.digestAt()
+	if err != nil {
+		return p.conf.DigestTime
 	}
+	return time.Date(0, 1, 1, hour, min, 0, 0, p.loc).Format("15:04 MST")
+}
+
+var slackUserID = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// userID resolves a Slack ID or a user name into a Slack ID
+func (p *Plugin) userID(value string) (string, bool) {
+	if user, ok := p.bot.Users.Find(value); ok {
+		return user.ID, true
+	}
+	if slackUserID.MatchString(value) {
+		return value, true
+	}
+	return "", false
 }
 
 func (p *Plugin) detailTask(msg *bawt.Message, id string) {
 	todo := p.store.Get(msg.Channel)
 	index, err := getTaskIndex(id, todo)
 	if err != nil {
-		msg.ReplyMention("Task not found...")
+		msg.ReplyMentionT("todo.not_found")
 		return
 	}
 	task := todo[index]
-	msg.Reply(printTaskDetails(task))
+	msg.Reply(printTaskDetails(msg, task))
 }
 
-func printTaskDetails(task *Task) string {
-	return fmt.Sprintf("%s\n> Created %s by <@%s>", task.String(), task.CreatedAt.Format("2006-01-02 15:04:05"), task.CreatedBy)
+func printTaskDetails(msg *bawt.Message, task *Task) string {
+	return msg.T("todo.details", task.String(), task.CreatedAt.Format("2006-01-02 15:04:05"), task.CreatedBy)
 }
 
-func (p *Plugin) createTask(msg *bawt.Message, content string) {
-	todo := p.store.Get(msg.Channel)
-
-	if len(todo) > 600 {
-		msg.ReplyMention("Gosh you have over 600 tasks!!! Clean some up first.")
+func (p *Plugin) createTask(msg *bawt.Message, list, content string) {
+	content, recur := splitRecurrence(content)
+	task, err := p.addTask(list, msg.FromUser.ID, content, func(task *Task) error {
+		task.Recur = recur
+		return nil
+	})
+	if err != nil {
+		msg.ReplyMentionT("todo.bad_query", err.Error())
 		return
 	}
-
-	id := p.generateRandomID(todo)
-	task := &Task{
-		ID:        id,
-		CreatedAt: time.Now(),
-		CreatedBy: msg.FromUser.ID,
-		Text:      []string{content},
-	}
-	todo = append(todo, task)
-	p.store.Put(msg.Channel, todo)
-	msg.ReplyMention("added: " + task.String())
+	msg.ReplyMentionT("todo.added", task.String())
 }
 
-func (p *Plugin) appendToTask(msg *bawt.Message, id, text string) {
-	todo := p.store.Get(msg.Channel)
-	index, err := getTaskIndex(id, todo)
+func (p *Plugin) appendToTask(msg *bawt.Message, list, id, text string) {
+	task, err := p.editTask(list, msg.FromUser.ID, id, func(task *Task) error {
+		task.Text = append(task.Text, strings.Split(text, " // ")...)
+		return nil
+	})
 	if err != nil {
-		msg.ReplyMention("Task not found...")
+		msg.ReplyMentionT("todo.not_found")
 		return
 	}
 
-	task := todo[index]
-	task.Text = append(task.Text, strings.Split(text, " // ")...)
-	p.store.Put(msg.Channel, todo)
-
-	msg.ReplyMention("updated " + task.String())
+	msg.ReplyMentionT("todo.updated", task.String())
 }
 
-func (p *Plugin) listTasks(msg *bawt.Message) {
-	todo := p.store.Get(msg.Channel)
-	sort.Sort(byID(todo))
+func (p *Plugin) listTasks(msg *bawt.Message, list string, q Query) {
+	p.archiveClosed(list)
 
-	var answer []string
-	var toDelete []string
-	for _, task := range todo {
-		if task.Closed {
-			toDelete = append(toDelete, task.ID)
-		} else {
-			answer = append(answer, task.String())
-		}
+	open := p.store.Get(list)
+	sort.Sort(byID(open))
+	if len(open) == 0 {
+		msg.ReplyMentionT("todo.nothing_to_do")
+		return
 	}
-	if len(toDelete) != 0 {
-		p.deleteTask(msg, strings.Join(toDelete, ","), true)
+
+	now := time.Now().In(p.loc)
+	var answer []string
+	for _, task := range q.Apply(open, now) {
+		answer = append(answer, task.format(now))
 	}
 	if len(answer) == 0 {
-		msg.ReplyMention("Nothing to do... Coffee time?")
+		msg.ReplyMentionT("todo.nothing_matches")
 	} else {
 		msg.Reply(strings.Join(answer, "\n"))
 	}
 }
 
-func (p *Plugin) deleteTask(msg *bawt.Message, ids string, silent bool) {
-	todo := p.store.Get(msg.Channel)
-
-	parts := strings.Split(msg.Match[0], " ")
-	var closingNotes string
-	if len(parts) > 3 {
-		closingNotes = strings.Join(parts[3:], " ")
-	}
-
+// deleteTask closes the tasks with the comma separated IDs, with a note
+func (p *Plugin) deleteTask(msg *bawt.Message, list, ids, note string) {
 	var out []string
 	for _, id := range strings.Split(ids, ",") {
-		index, err := getTaskIndex(id, todo)
+		task, err := p.closeTask(list, msg.FromUser.ID, id, note)
 		if err != nil {
-			out = append(out, "Task `"+id+"` not found")
+			out = append(out, msg.T("todo.id_not_found", id))
 			continue
 		}
+		out = append(out, task.String())
+	}
 
-		task := todo[index]
-		task.Closed = true
-		task.ClosingNote = closingNotes
-		todo = append(todo[:index], todo[index+1:]...)
+	msg.Reply(strings.Join(out, "\n"))
+}
 
-		if silent != true {
-			out = append(out, task.String())
+// listDone lists the archived tasks closed since the date in args, or
+// during the last week
+func (p *Plugin) listDone(msg *bawt.Message, list string, args []string) {
+	now := time.Now().In(p.loc)
+	since := day(now).AddDate(0, 0, -7)
+	if len(args) != 0 && args[0] != "" {
+		var err error
+		if since, err = ParseSince(strings.Join(args, " "), now); err != nil {
+			msg.ReplyMentionT("todo.bad_query", err.Error())
+			return
 		}
 	}
 
-	p.store.Put(msg.Channel, todo)
+	p.archiveClosed(list)
+	done, err := p.store.Archived(list, since)
+	if err != nil {
+		msg.ReplyMentionT("todo.bad_query", err.Error())
+		return
+	}
+	if len(done) == 0 {
+		msg.ReplyMentionT("todo.done.empty", since.Format("Mon 2006-01-02"))
+		return
+	}
+
+	out := []string{msg.T("todo.done", len(done), since.Format("Mon 2006-01-02"))}
+	for _, task := range done {
+		closedBy := task.ClosedBy
+		if closedBy == "" {
+			closedBy = task.CreatedBy
+		}
+		out = append(out, msg.T("todo.done.task", task.format(now), closedBy, task.ClosedAt.In(p.loc).Format("Mon 2006-01-02")))
+	}
+	msg.Reply(strings.Join(out, "\n"))
+}
+
+// showHistory shows the changes made to an open or archived task
+func (p *Plugin) showHistory(msg *bawt.Message, list, id string) {
+	todo := p.store.Get(list)
+	var task *Task
+	if index, err := getTaskIndex(id, todo); err == nil {
+		task = todo[index]
+	} else if task, err = p.store.FindArchived(list, id); err != nil || task == nil {
+		msg.ReplyMentionT("todo.not_found")
+		return
+	}
+
+	history := task.History
+	if len(history) == 0 {
+		// Tasks created before their history was kept
+		history = []Change{{Time: task.CreatedAt, User: task.CreatedBy, Action: ActionCreated}}
+	}
 
+	out := []string{task.String()}
+	for _, change := range history {
+		out = append(out, p.formatChange(msg, change))
+	}
 	msg.Reply(strings.Join(out, "\n"))
 }
 
+func (p *Plugin) formatChange(msg *bawt.Message, c Change) string {
+	at := c.Time.In(p.loc).Format("2006-01-02 15:04")
+
+	switch c.Action {
+	case ActionUpdated:
+		return msg.T("todo.history.updated", at, c.User, c.Field, orNone(c.From), orNone(c.To))
+	case ActionClosed:
+		if c.To != "" {
+			return msg.T("todo.history.closed_note", at, c.User, c.To)
+		}
+		return msg.T("todo.history.closed", at, c.User)
+	case ActionUndone:
+		return msg.T("todo.history.undone", at, c.User, c.From)
+	case ActionReopened:
+		return msg.T("todo.history.reopened", at, c.To)
+	case ActionMoved:
+		return msg.T("todo.history.moved", at, c.User, p.listLabel(msg.T, c.From), p.listLabel(msg.T, c.To))
+	}
+	return msg.T("todo.history.created", at, c.User)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// undo reverts the last change made to the channel's tasks
+func (p *Plugin) undo(msg *bawt.Message, list string) {
+	u, task, err := p.undoLast(list, msg.FromUser.ID)
+	switch err {
+	case nil:
+		msg.ReplyMentionT("todo.undo."+u.Action, task.String())
+	case errNothingToDo:
+		msg.ReplyMentionT("todo.undo.nothing")
+	case errNotFound:
+		msg.ReplyMentionT("todo.undo.gone", u.TaskID)
+	default:
+		msg.ReplyMentionT("todo.bad_query", err.Error())
+	}
+}
+
 func getTaskIndex(id string, todo Todo) (int, error) {
 	for i, task := range todo {
 		if task.ID == id {
@@ -200,15 +454,7 @@ func getTaskIndex(id string, todo Todo) (int, error) {
 }
 
 func (p *Plugin) replyHelp(msg *bawt.Message, extra string) {
-	answer := extra + `Commands:` + "```" + `
-!todo add [some text]             - add task
-!todo                             - list tasks
-!todo scratch [id]                - deletes task(s)
-!todo append [id] [more stuff]    - append text to a task
-!todo help                        - show this help
-` + "```"
-	msg.Reply(answer)
-	return
+	msg.ReplyT("todo.help", extra)
 }
 
 var letters = []rune("abcdefghijklmnopqrstuvwxyz")
@@ -221,9 +467,17 @@ func randSeq(n int) string {
 	return string(b)
 }
 
+// generateRandomID picks an unused ID, two letters long until the list
+// fills half of them, then longer
 func (p *Plugin) generateRandomID(todo Todo) string {
+	n, capacity := 2, len(letters)*len(letters)
+	for capacity < 2*(len(todo)+1) {
+		n++
+		capacity *= len(letters)
+	}
+
 	for {
-		id := randSeq(2)
+		id := randSeq(n)
 		if idInList(id, todo) {
 			continue
 		}
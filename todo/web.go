@@ -0,0 +1,480 @@
+package todo
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nlopes/slack"
+)
+
+// webChannel is a channel having a todo list
+type webChannel struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Open    int    `json:"open"`
+	Overdue int    `json:"overdue"`
+}
+
+// webTask is a task as shown by the web UI and the JSON API
+type webTask struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+	Assignee  string    `json:"assignee,omitempty"`
+	Due       string    `json:"due,omitempty"`
+	Priority  string    `json:"priority"`
+	Every     string    `json:"every,omitempty"`
+	Overdue   bool      `json:"overdue"`
+}
+
+// webTaskInput is the body of the JSON API calls creating or editing a
+// task. Missing fields are left as is.
+type webTaskInput struct {
+	Text     *string `json:"text"`
+	Assignee *string `json:"assignee"`
+	Due      *string `json:"due"`
+	Priority *string `json:"priority"`
+	Every    *string `json:"every"`
+	Note     string  `json:"note"`
+}
+
+func (p *Plugin) listenWeb(privRouter *mux.Router) {
+	privRouter.HandleFunc("/plugins/todo.json", p.handleWebChannelsJSON).Methods("GET")
+	privRouter.HandleFunc("/plugins/todo/{channel}.json", p.handleWebTasksJSON).Methods("GET", "POST")
+	privRouter.HandleFunc("/plugins/todo/{channel}/{id}.json", p.handleWebTaskJSON).Methods("GET", "PATCH", "DELETE")
+	privRouter.HandleFunc("/plugins/todo", p.handleWebChannels).Methods("GET")
+	privRouter.HandleFunc("/plugins/todo/{channel}", p.handleWebTasks).Methods("GET", "POST")
+}
+
+// webList returns the list of the request, unless the user can't see it:
+// the personal list of someone else, or a conversation they're not in
+func (p *Plugin) webList(w http.ResponseWriter, r *http.Request, user *slack.User) (string, bool) {
+	list := mux.Vars(r)["channel"]
+	if !p.canSee(list, user.ID) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return "", false
+	}
+	return list, true
+}
+
+// webUser returns the user logged in with the WebServerAuth plugin, who
+// is the author of the changes
+func (p *Plugin) webUser(w http.ResponseWriter, r *http.Request) (*slack.User, bool) {
+	user, err := p.bot.WebServer.AuthenticatedUser(r)
+	if err != nil || user == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+	return user, true
+}
+
+// webChannels lists the channels the user can see having open tasks,
+// along with their personal list
+func (p *Plugin) webChannels(user string, now time.Time) []webChannel {
+	var out []webChannel
+	for _, id := range p.store.Channels() {
+		if !p.canSee(id, user) {
+			continue
+		}
+
+		open := openTasks(p.store.Get(id))
+		if len(open) == 0 {
+			continue
+		}
+
+		c := webChannel{ID: id, Name: id, Open: len(open)}
+		if channel, ok := p.bot.Channels.Get(id); ok && channel.Name != "" {
+			c.Name = channel.Name
+		}
+		if isPersonal(id) {
+			c.Name = "personal"
+		}
+		for _, task := range open {
+			if task.Overdue(now) {
+				c.Overdue++
+			}
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+func (p *Plugin) webTasks(channel string, now time.Time) []webTask {
+	out := []webTask{}
+	for _, task := range (Query{Sort: sortID}).Apply(openTasks(p.store.Get(channel)), now) {
+		out = append(out, newWebTask(task, now))
+	}
+	return out
+}
+
+func newWebTask(task *Task, now time.Time) webTask {
+	t := webTask{
+		ID:        task.ID,
+		Text:      joinText(task.Text),
+		CreatedAt: task.CreatedAt,
+		CreatedBy: task.CreatedBy,
+		Assignee:  task.Assignee,
+		Priority:  task.Priority.String(),
+		Every:     string(task.Recur),
+		Overdue:   task.Overdue(now),
+	}
+	if !task.Due.IsZero() {
+		t.Due = task.Due.Format("2006-01-02")
+	}
+	return t
+}
+
+// announce tells the channel about a change made on the web, like the
+// chat commands do. Changes to personal lists go unannounced.
+func (p *Plugin) announce(channel, user, key string, task *Task) {
+	if isPersonal(channel) {
+		return
+	}
+	p.send(channel, p.bot.T(p.channelLocale(channel), key, user, task.String()))
+}
+
+// editFromWeb applies the given fields to a task, then announces it
+func (p *Plugin) editFromWeb(channel, user, id string, in webTaskInput) (*Task, error) {
+	task, err := p.editTask(channel, user, id, func(task *Task) error {
+		return p.setWebFields(task, in, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.announce(channel, user, "todo.web.updated", task)
+	return task, nil
+}
+
+// addFromWeb creates a task with the given fields, then announces it
+func (p *Plugin) addFromWeb(channel, user string, in webTaskInput) (*Task, error) {
+	if in.Text == nil || *in.Text == "" {
+		return nil, errNoText
+	}
+
+	task, err := p.addTask(channel, user, *in.Text, func(task *Task) error {
+		return p.setWebFields(task, in, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.announce(channel, user, "todo.web.added", task)
+	return task, nil
+}
+
+// setWebFields sets the fields given to the API or the web forms
+func (p *Plugin) setWebFields(task *Task, in webTaskInput, user string) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{fieldText, in.Text},
+		{fieldAssignee, in.Assignee},
+		{fieldDue, in.Due},
+		{fieldPriority, in.Priority},
+		{fieldRecur, in.Every},
+	}
+
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		if err := p.setTaskField(task, f.name, *f.value, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeFromWeb closes a task, then announces it
+func (p *Plugin) closeFromWeb(channel, user, id, note string) (*Task, error) {
+	task, err := p.closeTask(channel, user, id, note)
+	if err != nil {
+		return nil, err
+	}
+
+	p.announce(channel, user, "todo.web.closed", task)
+	return task, nil
+}
+
+// webError answers with the status matching an error of the task
+// operations
+func webError(w http.ResponseWriter, err error) {
+	switch err {
+	case errNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (p *Plugin) handleWebChannelsJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := p.webUser(w, r)
+	if !ok {
+		return
+	}
+
+	p.lock.Lock()
+	channels := p.webChannels(user.ID, time.Now().In(p.loc))
+	p.lock.Unlock()
+
+	if channels == nil {
+		channels = []webChannel{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Channels []webChannel `json:"channels"`
+	}{channels})
+}
+
+func (p *Plugin) handleWebTasksJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := p.webUser(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := p.webList(w, r, user)
+	if !ok {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now().In(p.loc)
+
+	if r.Method == "POST" {
+		var in webTaskInput
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		task, err := p.addFromWeb(channel, user.ID, in)
+		if err != nil {
+			webError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, newWebTask(task, now))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Tasks []webTask `json:"tasks"`
+	}{p.webTasks(channel, now)})
+}
+
+func (p *Plugin) handleWebTaskJSON(w http.ResponseWriter, r *http.Request) {
+	user, ok := p.webUser(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := p.webList(w, r, user)
+	if !ok {
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now().In(p.loc)
+
+	var in webTaskInput
+	if r.Method != "GET" && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var task *Task
+	var err error
+
+	switch r.Method {
+	case "PATCH":
+		task, err = p.editFromWeb(channel, user.ID, id, in)
+	case "DELETE":
+		if in.Note == "" {
+			in.Note = r.FormValue("note")
+		}
+		task, err = p.closeFromWeb(channel, user.ID, id, in.Note)
+	default:
+		todo := p.store.Get(channel)
+		index, e := getTaskIndex(id, todo)
+		if e != nil || todo[index].Closed {
+			err = errNotFound
+		} else {
+			task = todo[index]
+		}
+	}
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newWebTask(task, now))
+}
+
+func (p *Plugin) handleWebChannels(w http.ResponseWriter, r *http.Request) {
+	user, ok := p.webUser(w, r)
+	if !ok {
+		return
+	}
+
+	p.lock.Lock()
+	channels := p.webChannels(user.ID, time.Now().In(p.loc))
+	p.lock.Unlock()
+
+	if err := channelsTemplate.Execute(w, channels); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// handleWebTasks shows the tasks of a channel, and handles the forms
+// adding, editing and closing them
+func (p *Plugin) handleWebTasks(w http.ResponseWriter, r *http.Request) {
+	user, ok := p.webUser(w, r)
+	if !ok {
+		return
+	}
+	channel, ok := p.webList(w, r, user)
+	if !ok {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var formErr error
+	if r.Method == "POST" {
+		value := func(name string) *string {
+			v := r.FormValue(name)
+			return &v
+		}
+
+		switch r.FormValue("action") {
+		case "add":
+			_, formErr = p.addFromWeb(channel, user.ID, webTaskInput{
+				Text: value("text"), Assignee: value("assignee"), Due: value("due"), Priority: value("priority"),
+			})
+		case "edit":
+			_, formErr = p.editFromWeb(channel, user.ID, r.FormValue("id"), webTaskInput{
+				Text: value("text"), Assignee: value("assignee"), Due: value("due"), Priority: value("priority"),
+			})
+		case "close":
+			_, formErr = p.closeFromWeb(channel, user.ID, r.FormValue("id"), r.FormValue("note"))
+		default:
+			http.Error(w, "Unknown action", http.StatusBadRequest)
+			return
+		}
+
+		if formErr == nil {
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	name := channel
+	if c, ok := p.bot.Channels.Get(channel); ok && c.Name != "" {
+		name = c.Name
+	}
+
+	err := tasksTemplate.Execute(w, struct {
+		Channel    string
+		Name       string
+		Error      error
+		Tasks      []webTask
+		Priorities []Priority
+	}{
+		Channel:    channel,
+		Name:       name,
+		Error:      formErr,
+		Tasks:      p.webTasks(channel, time.Now().In(p.loc)),
+		Priorities: []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh},
+	})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+var channelsTemplate = template.Must(template.New("todo").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>To do</title>
+</head>
+<body>
+  <h1>To do</h1>
+  {{if .}}
+  <table>
+    <tr><th>Channel</th><th>Open</th><th>Overdue</th></tr>
+    {{range .}}
+    <tr>
+      <td><a href="/plugins/todo/{{.ID}}">#{{.Name}}</a></td>
+      <td>{{.Open}}</td>
+      <td>{{.Overdue}}</td>
+    </tr>
+    {{end}}
+  </table>
+  {{else}}
+  <p>Nothing to do... Coffee time?</p>
+  {{end}}
+</body>
+</html>
+`))
+
+var tasksTemplate = template.Must(template.New("todo-tasks").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>To do in #{{.Name}}</title>
+</head>
+<body>
+  <p><a href="/plugins/todo">All channels</a></p>
+  <h1>To do in #{{.Name}}</h1>
+  {{if .Error}}<p><strong>{{.Error}}</strong></p>{{end}}
+  <form method="POST">
+    <input type="hidden" name="action" value="add">
+    <input name="text" placeholder="Task" size="50" required>
+    <input name="assignee" placeholder="Assignee">
+    <input name="due" placeholder="Due (YYYY-MM-DD, friday...)">
+    <select name="priority">{{range $.Priorities}}<option>{{.}}</option>{{end}}</select>
+    <input type="submit" value="Add">
+  </form>
+  <table>
+    <tr><th>ID</th><th>Task</th><th>Assignee</th><th>Due</th><th>Priority</th><th></th><th></th></tr>
+    {{range .Tasks}}
+    {{$task := .}}
+    <tr>
+      <td>
+        <form id="edit-{{.ID}}" method="POST"><input type="hidden" name="action" value="edit"><input type="hidden" name="id" value="{{.ID}}"></form>
+        <form id="close-{{.ID}}" method="POST"><input type="hidden" name="action" value="close"><input type="hidden" name="id" value="{{.ID}}"></form>
+        <code>{{.ID}}</code>
+      </td>
+      <td><input form="edit-{{.ID}}" name="text" value="{{.Text}}" size="50"></td>
+      <td><input form="edit-{{.ID}}" name="assignee" value="{{.Assignee}}"></td>
+      <td><input form="edit-{{.ID}}" name="due" value="{{.Due}}">{{if .Overdue}} <strong>overdue</strong>{{end}}</td>
+      <td><select form="edit-{{.ID}}" name="priority">{{range $.Priorities}}<option{{if eq .String $task.Priority}} selected{{end}}>{{.}}</option>{{end}}</select></td>
+      <td><input form="edit-{{.ID}}" type="submit" value="Save"></td>
+      <td><input form="close-{{.ID}}" name="note" placeholder="Closing note"> <input form="close-{{.ID}}" type="submit" value="Close"></td>
+    </tr>
+    {{end}}
+  </table>
+</body>
+</html>
+`))
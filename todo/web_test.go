@@ -0,0 +1,199 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+// testWebServer authenticates every request as its user, if any
+type testWebServer struct {
+	user *slack.User
+}
+
+func (s *testWebServer) InitWebServer(*bawt.Bot, []string)                                 {}
+func (s *testWebServer) RunServer()                                                        {}
+func (s *testWebServer) SetAuthMiddleware(func(http.Handler) http.Handler)                 {}
+func (s *testWebServer) SetAuthenticatedUserFunc(func(*http.Request) (*slack.User, error)) {}
+func (s *testWebServer) PrivateRouter() *mux.Router                                        { return nil }
+func (s *testWebServer) PublicRouter() *mux.Router                                         { return nil }
+func (s *testWebServer) GetSession(*http.Request) *sessions.Session                        { return nil }
+
+func (s *testWebServer) AuthenticatedUser(*http.Request) (*slack.User, error) {
+	if s.user == nil {
+		return nil, errors.New("not logged in")
+	}
+	return s.user, nil
+}
+
+func newTestWeb(t *testing.T) (*Plugin, *mux.Router, *testWebServer, *[]bawttest.Sent) {
+	p, out := newTestPlugin(t)
+
+	server := &testWebServer{user: &slack.User{ID: "U1", Name: "jane"}}
+	p.bot.WebServer = server
+	p.bot.Channels.Set(bawt.Channel{ID: "C1", Name: "general", IsChannel: true})
+
+	router := mux.NewRouter()
+	p.listenWeb(router)
+
+	return p, router, server, out
+}
+
+func serve(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if strings.HasSuffix(target, ".json") {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebJSON(t *testing.T) {
+	p, router, _, out := newTestWeb(t)
+
+	rec := serve(router, "POST", "/plugins/todo/C1.json", `{"text":"ship it","assignee":"me","due":"2026-10-20","priority":"high"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created webTask
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "ship it", created.Text)
+	assert.Equal(t, "U1", created.Assignee)
+	assert.Equal(t, "U1", created.CreatedBy)
+	assert.Equal(t, "2026-10-20", created.Due)
+	assert.Equal(t, "high", created.Priority)
+
+	if assert.Len(t, *out, 1) {
+		assert.Equal(t, "C1", (*out)[0].To)
+		assert.Contains(t, (*out)[0].Text, "<@U1> added from the web: `"+created.ID+"` :red_circle: ship it")
+	}
+
+	rec = serve(router, "GET", "/plugins/todo.json", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"channels":[{"id":"C1","name":"general","open":1,"overdue":0}]}`, rec.Body.String())
+
+	rec = serve(router, "PATCH", "/plugins/todo/C1/"+created.ID+".json", `{"text":"ship it // twice","due":"none"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"ship it", "twice"}, p.store.Get("C1")[0].Text)
+	assert.True(t, p.store.Get("C1")[0].Due.IsZero())
+	assert.Equal(t, PriorityHigh, p.store.Get("C1")[0].Priority)
+
+	rec = serve(router, "PATCH", "/plugins/todo/C1/"+created.ID+".json", `{"priority":"urgent"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(router, "GET", "/plugins/todo/C1.json", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"text":"ship it // twice"`)
+
+	rec = serve(router, "DELETE", "/plugins/todo/C1/"+created.ID+".json", `{"note":"shipped"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, p.store.Get("C1"))
+	assert.Contains(t, (*out)[len(*out)-1].Text, "<@U1> closed from the web:")
+	assert.Contains(t, (*out)[len(*out)-1].Text, "_shipped_")
+
+	rec = serve(router, "GET", "/plugins/todo/C1/"+created.ID+".json", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(router, "POST", "/plugins/todo/C1.json", `{"text":""}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebForms(t *testing.T) {
+	p, router, server, out := newTestWeb(t)
+
+	form := url.Values{"action": {"add"}, "text": {"write docs"}, "priority": {"low"}}
+	rec := serve(router, "POST", "/plugins/todo/C1", form.Encode())
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+
+	tasks := p.store.Get("C1")
+	if !assert.Len(t, tasks, 1) {
+		return
+	}
+	id := tasks[0].ID
+	assert.Equal(t, PriorityLow, tasks[0].Priority)
+
+	rec = serve(router, "GET", "/plugins/todo/C1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `value="write docs"`)
+
+	rec = serve(router, "GET", "/plugins/todo", "")
+	assert.Contains(t, rec.Body.String(), `href="/plugins/todo/C1"`)
+
+	form = url.Values{"action": {"edit"}, "id": {id}, "text": {"write docs"}, "assignee": {"nobody"}, "due": {""}, "priority": {"low"}}
+	rec = serve(router, "POST", "/plugins/todo/C1", form.Encode())
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown user")
+
+	form = url.Values{"action": {"close"}, "id": {id}, "note": {"done"}}
+	rec = serve(router, "POST", "/plugins/todo/C1", form.Encode())
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Empty(t, p.store.Get("C1"))
+	assert.Len(t, *out, 2)
+
+	server.user = nil
+	rec = serve(router, "GET", "/plugins/todo", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(router, "POST", "/plugins/todo/C1.json", `{"text":"sneaky"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWebPersonal(t *testing.T) {
+	p, router, _, out := newTestWeb(t)
+
+	p.store.Put(personalList("U2"), Todo{{ID: "aa", Text: []string{"secret"}, CreatedBy: "U2"}})
+
+	rec := serve(router, "POST", "/plugins/todo/@U1.json", `{"text":"mine","every":"monday"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"every":"monday"`)
+	assert.Empty(t, *out)
+
+	rec = serve(router, "GET", "/plugins/todo.json", "")
+	assert.JSONEq(t, `{"channels":[{"id":"@U1","name":"personal","open":1,"overdue":0}]}`, rec.Body.String())
+
+	rec = serve(router, "GET", "/plugins/todo/@U2.json", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(router, "GET", "/plugins/todo/@U2/aa.json", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(router, "GET", "/plugins/todo/@U2", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWebPrivateConversations(t *testing.T) {
+	p, router, _, _ := newTestWeb(t)
+
+	p.bot.Channels.Set(bawt.Channel{ID: "G1", Name: "secret", IsGroup: true, Members: []string{"U2"}})
+	p.bot.Channels.Set(bawt.Channel{ID: "G2", Name: "ours", IsGroup: true, Members: []string{"U1", "U2"}})
+	p.bot.Channels.Set(bawt.Channel{ID: "D2", IsIM: true, User: "U2"})
+	for _, list := range []string{"G1", "G2", "D2", "C9"} {
+		p.store.Put(list, Todo{{ID: "aa", Text: []string{"in " + list}, CreatedBy: "U2"}})
+	}
+
+	rec := serve(router, "GET", "/plugins/todo.json", "")
+	assert.JSONEq(t, `{"channels":[{"id":"G2","name":"ours","open":1,"overdue":0}]}`, rec.Body.String())
+
+	for _, list := range []string{"G1", "D2", "C9"} {
+		rec = serve(router, "GET", "/plugins/todo/"+list+".json", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code, list)
+		rec = serve(router, "GET", "/plugins/todo/"+list+"/aa.json", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code, list)
+		rec = serve(router, "POST", "/plugins/todo/"+list+".json", `{"text":"sneaky"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, list)
+	}
+
+	rec = serve(router, "GET", "/plugins/todo/G2.json", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "in G2")
+}
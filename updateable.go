@@ -79,3 +79,16 @@ const (
 	updatePrefix
 	updateWhole
 )
+
+// UpdateableMessage returns an UpdateableReply for a message the bot sent
+// earlier, given its channel ID and timestamp, like one stored before a
+// restart
+func (bot *Bot) UpdateableMessage(channel, timestamp string) *UpdateableReply {
+	return &UpdateableReply{
+		reply: &Reply{
+			OutgoingMessage: &slack.OutgoingMessage{Channel: channel},
+			bot:             bot,
+		},
+		msgTimestamp: timestamp,
+	}
+}
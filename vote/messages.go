@@ -0,0 +1,23 @@
+package vote
+
+import "github.com/gopherworks/bawt"
+
+func init() {
+	bawt.RegisterMessages(bawt.DefaultLocale, map[string]bawt.Translation{
+		"vote.usage":           {Other: "you can say `!what-for-lunch 5m` to get a vote that will last 5 minutes. `!vote-for-lunch` is an alias"},
+		"vote.already_running": {Other: "vote is already running!"},
+		"vote.bad_duration":    {Other: "couldn't parse duration: %s"},
+		"vote.no_votes":        {Other: "polls closed, but no one voted"},
+		"vote.results":         {Other: "polls closed, here are the results:"},
+		"vote.result":          {One: "* %[2]s: %[1]d vote", Other: "* %[2]s: %[1]d votes"},
+		"vote.open":            {Other: "<!channel> okay, what do we eat ? Votes are open. Use `!vote The Food Place http://food-place.url` .. you can vote for the same place with a substring, ex: `!vote food place`"},
+		"vote.none":            {Other: "what vote ?!"},
+		"vote.none.hyper":      {Other: "oh you're so cute! voting while there's no vote going on !"},
+		"vote.double":          {Other: "you voted already"},
+		"vote.double.hyper":    {Other: "trying to double vote ! how charming :)"},
+		"vote.counted":         {Other: "okay"},
+		"vote.counted.hyper":   {Other: "hmmm kaay"},
+		"vote.noted":           {Other: "taking note"},
+		"vote.noted.hyper":     {Other: "taking note! what a creative mind..."},
+	})
+}
@@ -42,28 +42,27 @@ func (v *Vote) voteHandler(listen *bawt.Listener, msg *bawt.Message) {
 	v.mutex.Lock()
 	defer v.mutex.Unlock()
 
-	bot := v.bot
 	// TODO:    ok, @kat wants to survey what's for lunch, use "!vote The Food Place http://food-place.url" .. you can vote for the same place with a substring: "!vote food place"
 	// TODO: match "!vote Mucha Dogs http://bigdogs.com"
 	// TODO: match "!vote mucha dogs"
 	// TODO: match "!vote Other place
 
 	if msg.Text == "!what-for-lunch" || msg.Text == "!vote-for-lunch" {
-		msg.ReplyMention("you can say `!what-for-lunch 5m` to get a vote that will last 5 minutes. `!vote-for-lunch` is an alias")
+		msg.ReplyMentionT("vote.usage")
 		return
 	}
 
 	if msg.HasPrefix("!what-for-lunch ") || msg.HasPrefix("!vote-for-lunch ") {
 		fmt.Printf("NPD: %v\n", msg)
 		if v.runningVotes[msg.FromChannel.ID] != nil {
-			msg.ReplyMention("vote is already running!").DeleteAfter("3s")
+			msg.ReplyMentionT("vote.already_running").DeleteAfter("3s")
 			return
 		}
 
 		timing := strings.TrimSpace(strings.SplitN(msg.Text, " ", 2)[1])
 		dur, err := time.ParseDuration(timing)
 		if err != nil {
-			msg.ReplyMention(fmt.Sprintf("couldn't parse duration: %s", err))
+			msg.ReplyMentionT("vote.bad_duration", err)
 			return
 		}
 
@@ -82,15 +81,11 @@ func (v *Vote) voteHandler(listen *bawt.Listener, msg *bawt.Message) {
 
 			// TODO: print report, clear up
 			if len(res) == 0 {
-				msg.ReplyMention("polls closed, but no one voted")
+				msg.ReplyMentionT("vote.no_votes")
 			} else {
-				out := []string{"polls closed, here are the results:"}
+				out := []string{msg.T("vote.results")}
 				for theVote, count := range res {
-					plural := ""
-					if count > 1 {
-						plural = "s"
-					}
-					out = append(out, fmt.Sprintf("* %s: %d vote%s", theVote, count, plural))
+					out = append(out, msg.T("vote.result", count, theVote))
 				}
 				msg.ReplyMention(strings.Join(out, "\n"))
 			}
@@ -98,14 +93,14 @@ func (v *Vote) voteHandler(listen *bawt.Listener, msg *bawt.Message) {
 			delete(v.runningVotes, msg.FromChannel.ID)
 		}()
 
-		msg.Reply("<!channel> okay, what do we eat ? Votes are open. Use `!vote The Food Place http://food-place.url` .. you can vote for the same place with a substring, ex: `!vote food place`")
+		msg.ReplyT("vote.open")
 
 	}
 
 	if msg.HasPrefix("!vote ") {
 		running := v.runningVotes[msg.FromChannel.ID]
 		if running == nil {
-			msg.Reply(bot.WithMood("what vote ?!", "oh you're so cute! voting while there's no vote going on !"))
+			msg.Reply(msg.WithMood(msg.T("vote.none"), msg.T("vote.none.hyper")))
 			return
 		}
 
@@ -118,7 +113,7 @@ func (v *Vote) voteHandler(listen *bawt.Listener, msg *bawt.Message) {
 		for _, prevVote := range running {
 			if msg.FromUser.ID == prevVote.user {
 				// buzz off if you voted already
-				msg.ReplyMention(bot.WithMood("you voted already", "trying to double vote ! how charming :)"))
+				msg.ReplyMention(msg.WithMood(msg.T("vote.double"), msg.T("vote.double.hyper")))
 				return
 			}
 		}
@@ -127,13 +122,13 @@ func (v *Vote) voteHandler(listen *bawt.Listener, msg *bawt.Message) {
 			if strings.Contains(strings.ToLower(prevVote.vote), strings.ToLower(voteCast)) {
 				running = append(running, vote{msg.FromUser.ID, prevVote.vote})
 				v.runningVotes[msg.FromChannel.ID] = running
-				msg.ReplyMention(bot.WithMood("okay", "hmmm kaay")).DeleteAfter("2s")
+				msg.ReplyMention(msg.WithMood(msg.T("vote.counted"), msg.T("vote.counted.hyper"))).DeleteAfter("2s")
 				return
 			}
 		}
 		running = append(running, vote{msg.FromUser.ID, voteCast})
 		v.runningVotes[msg.FromChannel.ID] = running
-		msg.ReplyMention(bot.WithMood("taking note", "taking note! what a creative mind...")).DeleteAfter("2s")
+		msg.ReplyMention(msg.WithMood(msg.T("vote.noted"), msg.T("vote.noted.hyper"))).DeleteAfter("2s")
 
 		// TODO: match "!what-for-lunch 1h|5m|50s"
 
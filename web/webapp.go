@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
@@ -48,7 +49,7 @@ func (webapp *Webapp) InitWebServer(bot *bawt.Bot, enabledPlugins []string) {
 		Webapp WebappConfig
 	}
 	bot.LoadConfig(&conf)
-	bot.Status.Update("http", "not ok")
+	bot.Status.Register("http", true, nil)
 
 	webapp.bot = bot
 	webapp.enabledPlugins = enabledPlugins
@@ -97,6 +98,8 @@ func (webapp *Webapp) RunServer() {
 
 	pubMux := http.NewServeMux()
 	pubMux.Handle("/public/", webapp.PublicRouter())
+	pubMux.HandleFunc("/healthz", webapp.bot.Status.LivenessHandler)
+	pubMux.HandleFunc("/readyz", webapp.bot.Status.ReadinessHandler)
 	if webapp.authMiddleware != nil {
 		pubMux.Handle("/", webapp.authMiddleware(privMux))
 	} else {
@@ -106,10 +109,19 @@ func (webapp *Webapp) RunServer() {
 	webapp.handler = negroni.Classic()
 	webapp.handler.UseHandler(context.ClearHandler(pubMux))
 
-	webapp.handler.Run(webapp.config.Listen)
+	// Listen first so the status only turns ok once we accept connections
+	listener, err := net.Listen("tcp", webapp.config.Listen)
+	if err != nil {
+		log.WithError(err).Error("web: unable to listen")
+		webapp.bot.Status.Set("http", bawt.StateFailing, err)
+		return
+	}
+
+	log.Printf("web: listening on %s", webapp.config.Listen)
+	webapp.bot.Status.Set("http", bawt.StateOK, nil)
 
-	// This is kind of lazy
-	webapp.bot.Status.Update("http", "ok")
+	err = http.Serve(listener, webapp.handler)
+	webapp.bot.Status.Set("http", bawt.StateFailing, err)
 }
 
 // GetSession retrieves the user session
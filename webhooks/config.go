@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+/*
+Config is the `webhooks` section of the configuration, like:
+
+	webhooks:
+	  hooks:
+	    - name: ci
+	      url: https://ci.example.com/bawt
+	      secret: s3cr3t
+	      events: ["bawt:message:received", "recognition:recognized"]
+	      match: "(?i)deploy"
+	      channels: ["#ops"]
+	      max_attempts: 5
+	      timeout: 10s
+
+Events are topics of the event bus, see `bawt.Events()`. Match and
+Channels only filter messages and reactions. Without channels, only the
+messages and reactions of public channels are sent, those of IMs and
+private channels need to be listed.
+*/
+type Config struct {
+	Hooks []Hook `json:"hooks" mapstructure:"hooks"`
+}
+
+// Hook is an HTTP subscriber to some events
+type Hook struct {
+	Name        string   `json:"name" mapstructure:"name"`
+	URL         string   `json:"url" mapstructure:"url"`
+	Secret      string   `json:"secret" mapstructure:"secret"`             // Key of the HMAC-SHA256 signature, sent in `X-Bawt-Signature`. Optional.
+	Events      []string `json:"events" mapstructure:"events"`             // Topics to forward
+	Match       string   `json:"match" mapstructure:"match"`               // Regexp the text of messages must match. Optional.
+	Channels    []string `json:"channels" mapstructure:"channels"`         // Names or IDs of the channels messages and reactions must come from. Optional, public channels only by default.
+	MaxAttempts int      `json:"max_attempts" mapstructure:"max_attempts"` // Attempts before a delivery goes to the dead-letter queue. Defaults to 5.
+	Timeout     string   `json:"timeout" mapstructure:"timeout"`           // Timeout of a single attempt. Defaults to 10s.
+
+	match *regexp.Regexp
+}
+
+const (
+	defaultMaxAttempts = 5
+	defaultTimeout     = 10 * time.Second
+	queueSize          = 100
+)
+
+// Delays between attempts, doubled every time
+var (
+	initialBackoff = time.Second
+	maxBackoff     = 5 * time.Minute
+)
+
+// validate checks the hook and fills in the defaults
+func (h *Hook) validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("webhook without a name")
+	}
+	if h.URL == "" {
+		return fmt.Errorf("webhook %q has no url", h.Name)
+	}
+	if len(h.Events) == 0 {
+		return fmt.Errorf("webhook %q has no events", h.Name)
+	}
+
+	registered := make(map[string]bool)
+	for _, e := range bawt.Events() {
+		registered[e.Topic] = true
+	}
+	for _, topic := range h.Events {
+		if !registered[topic] {
+			return fmt.Errorf("webhook %q: unknown event %q", h.Name, topic)
+		}
+	}
+
+	if h.Match != "" {
+		re, err := regexp.Compile(h.Match)
+		if err != nil {
+			return fmt.Errorf("webhook %q: invalid match: %s", h.Name, err)
+		}
+		h.match = re
+	}
+
+	if h.MaxAttempts <= 0 {
+		h.MaxAttempts = defaultMaxAttempts
+	}
+
+	if h.Timeout != "" {
+		if _, err := time.ParseDuration(h.Timeout); err != nil {
+			return fmt.Errorf("webhook %q: invalid timeout: %s", h.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// timeout returns the timeout of an attempt
+func (h Hook) timeout() time.Duration {
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return defaultTimeout
+	}
+	return d
+}
@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Stats are the counters of a hook since startup
+type Stats struct {
+	Delivered    int
+	Failed       int
+	Retries      int
+	Queued       int
+	LastError    string
+	LastDelivery time.Time
+}
+
+/*
+dispatcher sends the deliveries of a hook, one at a time and in order.
+Failed attempts are retried with an exponential backoff, and deliveries
+still failing after MaxAttempts go to the dead-letter queue.
+*/
+type dispatcher struct {
+	hook   Hook
+	client *http.Client
+	store  Store
+	queue  chan *Delivery
+	stop   chan struct{}
+
+	lock  sync.Mutex
+	stats Stats
+}
+
+func newDispatcher(hook Hook, store Store) *dispatcher {
+	return &dispatcher{
+		hook:   hook,
+		client: &http.Client{Timeout: hook.timeout()},
+		store:  store,
+		queue:  make(chan *Delivery, queueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// enqueue schedules a delivery. When the queue is full, it goes
+// straight to the dead-letter queue rather than blocking the bus.
+func (d *dispatcher) enqueue(del *Delivery) {
+	select {
+	case d.queue <- del:
+	default:
+		del.LastError = "queue full"
+		d.bury(del)
+	}
+}
+
+// run sends the deliveries until stopped
+func (d *dispatcher) run() {
+	for {
+		select {
+		case del := <-d.queue:
+			d.deliver(del)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// deliver tries to send a delivery until it succeeds, runs out of
+// attempts or the dispatcher stops
+func (d *dispatcher) deliver(del *Delivery) {
+	backoff := initialBackoff
+
+	for {
+		del.Attempts++
+		err := d.send(del)
+		if err == nil {
+			d.lock.Lock()
+			d.stats.Delivered++
+			d.stats.LastDelivery = time.Now()
+			d.lock.Unlock()
+			return
+		}
+
+		del.LastError = err.Error()
+		d.lock.Lock()
+		d.stats.LastError = err.Error()
+		d.lock.Unlock()
+
+		if del.Attempts >= d.hook.MaxAttempts {
+			d.bury(del)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-d.stop:
+			d.bury(del)
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		d.lock.Lock()
+		d.stats.Retries++
+		d.lock.Unlock()
+	}
+}
+
+// send makes one attempt. Any 2xx response is a success.
+func (d *dispatcher) send(del *Delivery) error {
+	req, err := http.NewRequest("POST", d.hook.URL, bytes.NewReader(del.Body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bawt-webhooks")
+	req.Header.Set(headerEvent, del.Topic)
+	req.Header.Set(headerDelivery, del.ID)
+	if d.hook.Secret != "" {
+		req.Header.Set(headerSignature, sign(d.hook.Secret, del.Body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// bury puts a delivery in the dead-letter queue
+func (d *dispatcher) bury(del *Delivery) {
+	del.FailedAt = time.Now()
+
+	d.lock.Lock()
+	d.stats.Failed++
+	if del.LastError != "" {
+		d.stats.LastError = del.LastError
+	}
+	d.lock.Unlock()
+
+	if err := d.store.Add(del); err != nil {
+		d.lock.Lock()
+		d.stats.LastError = fmt.Sprintf("couldn't store a dead delivery: %s", err)
+		d.lock.Unlock()
+	}
+}
+
+// Stats returns a snapshot of the counters
+func (d *dispatcher) Stats() Stats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	s := d.stats
+	s.Queued = len(d.queue)
+	return s
+}
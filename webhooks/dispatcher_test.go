@@ -0,0 +1,147 @@
+package webhooks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopherworks/bawt/bawttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) *boltStore {
+	return &boltStore{db: bawttest.NewDB(t, createBuckets)}
+}
+
+// receiver is a local HTTP subscriber failing its first `failures`
+// requests
+type receiver struct {
+	lock     sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, string(body))
+	if len(r.requests) <= r.failures {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) count() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.requests)
+}
+
+func TestDispatcher(t *testing.T) {
+	initialBackoff = time.Millisecond
+
+	tests := []struct {
+		name      string
+		failures  int
+		delivered int
+		failed    int
+		retries   int
+		requests  int
+	}{
+		{name: "success", failures: 0, delivered: 1, requests: 1},
+		{name: "retried", failures: 2, delivered: 1, retries: 2, requests: 3},
+		{name: "dead letter", failures: 10, failed: 1, retries: 2, requests: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newTestStore(t)
+
+			recv := &receiver{failures: test.failures}
+			srv := httptest.NewServer(recv)
+			defer srv.Close()
+
+			d := newDispatcher(Hook{Name: "ci", URL: srv.URL, Secret: "s3cr3t", MaxAttempts: 3}, store)
+			body := []byte(`{"id":"1","topic":"todo:changed"}`)
+			d.deliver(&Delivery{ID: "1", Hook: "ci", Topic: "todo:changed", Body: body})
+
+			s := d.Stats()
+			assert.Equal(t, test.delivered, s.Delivered)
+			assert.Equal(t, test.failed, s.Failed)
+			assert.Equal(t, test.retries, s.Retries)
+			assert.Equal(t, test.requests, recv.count())
+			assert.Equal(t, test.failed, store.Count("ci"))
+
+			req := recv.requests[0]
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			assert.Equal(t, "todo:changed", req.Header.Get(headerEvent))
+			assert.Equal(t, "1", req.Header.Get(headerDelivery))
+			assert.True(t, Verify("s3cr3t", []byte(recv.bodies[0]), req.Header.Get(headerSignature)))
+
+			if test.failed > 0 {
+				assert.Contains(t, s.LastError, "502")
+			}
+		})
+	}
+}
+
+func TestDispatcherRun(t *testing.T) {
+	store := newTestStore(t)
+
+	recv := &receiver{}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	d := newDispatcher(Hook{Name: "ci", URL: srv.URL, MaxAttempts: 1}, store)
+	go d.run()
+	defer close(d.stop)
+
+	for _, id := range []string{"1", "2", "3"} {
+		d.enqueue(&Delivery{ID: id, Hook: "ci", Body: []byte(`{}`)})
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Delivered < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, 3, d.Stats().Delivered)
+	assert.Equal(t, 3, recv.count())
+	assert.Empty(t, recv.requests[0].Header.Get(headerSignature), "no secret, no signature")
+}
+
+func TestBoltStore(t *testing.T) {
+	s := newTestStore(t)
+
+	for _, d := range []*Delivery{
+		{ID: "1", Hook: "ci", Body: []byte(`{"n":1}`)},
+		{ID: "2", Hook: "ci", Body: []byte(`{"n":2}`)},
+		{ID: "1", Hook: "cd", Body: []byte(`{"n":3}`)},
+	} {
+		assert.NoError(t, s.Add(d))
+	}
+
+	list, err := s.List("ci")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.JSONEq(t, `{"n":1}`, string(list[0].Body))
+	assert.Equal(t, 1, s.Count("cd"))
+
+	assert.NoError(t, s.Delete("ci", "1"))
+	assert.Equal(t, 1, s.Count("ci"))
+
+	n, err := s.Purge("ci")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, s.Count("ci"))
+	assert.Equal(t, 1, s.Count("cd"), "other hooks are kept")
+}
@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Headers sent along with every delivery
+const (
+	headerEvent     = "X-Bawt-Event"
+	headerDelivery  = "X-Bawt-Delivery"
+	headerSignature = "X-Bawt-Signature"
+)
+
+// Envelope is the JSON body of a delivery
+type Envelope struct {
+	ID        string      `json:"id"`
+	Topic     string      `json:"topic"`
+	Timestamp time.Time   `json:"timestamp"`
+	Workspace string      `json:"workspace,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// messageData is what subscribers get of a message
+type messageData struct {
+	Channel     string `json:"channel"`
+	ChannelName string `json:"channel_name,omitempty"`
+	User        string `json:"user"`
+	UserName    string `json:"user_name,omitempty"`
+	Text        string `json:"text"`
+	Timestamp   string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	Edited      bool   `json:"edited"`
+}
+
+// reactionData is what subscribers get of a reaction
+type reactionData struct {
+	Type      string `json:"type"`
+	User      string `json:"user"`
+	Emoji     string `json:"emoji"`
+	Channel   string `json:"channel,omitempty"`
+	ItemType  string `json:"item_type"`
+	ItemTS    string `json:"item_ts,omitempty"`
+	File      string `json:"file,omitempty"`
+	Timestamp string `json:"ts"`
+}
+
+// newEnvelope wraps an event, turning the core types, which hold
+// references to the bot, into plain data
+func newEnvelope(topic string, payload interface{}) Envelope {
+	env := Envelope{
+		ID:        newDeliveryID(),
+		Topic:     topic,
+		Timestamp: time.Now().UTC(),
+		Data:      payload,
+	}
+
+	switch p := payload.(type) {
+	case *bawt.Message:
+		d := messageData{
+			Channel:   p.Channel,
+			User:      p.User,
+			Text:      p.Text,
+			Timestamp: p.Timestamp,
+			ThreadTS:  p.ThreadTimestamp,
+			Edited:    p.IsEdit,
+		}
+		if p.FromChannel != nil {
+			d.ChannelName = p.FromChannel.Name
+		}
+		if p.FromUser != nil {
+			d.User = p.FromUser.ID
+			d.UserName = p.FromUser.Name
+		}
+		env.Workspace = p.Workspace
+		env.Data = d
+
+	case *bawt.ReactionEvent:
+		d := reactionData{
+			Type:      "added",
+			User:      p.User,
+			Emoji:     p.Emoji,
+			Channel:   p.Item.Channel,
+			ItemType:  p.Item.Type,
+			ItemTS:    p.Item.Timestamp,
+			File:      p.Item.File,
+			Timestamp: p.Timestamp,
+		}
+		if p.Type == bawt.ReactionRemoved {
+			d.Type = "removed"
+		}
+		env.Data = d
+	}
+
+	return env
+}
+
+// sign returns the value of the signature header of a body
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+/*
+Verify checks the `X-Bawt-Signature` header of a delivery, for receivers
+written in Go. Others compute the hex-encoded HMAC-SHA256 of the raw
+body with the secret and compare it to the header, minus its `sha256=`
+prefix.
+*/
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(sign(secret, body)), []byte(signature))
+}
+
+// newDeliveryID returns a unique ID, sorting chronologically
+func newDeliveryID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return fmt.Sprintf("%x-%s", time.Now().UnixNano(), hex.EncodeToString(b))
+}
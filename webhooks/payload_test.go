@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"topic":"todo:changed"}`)
+
+	sig := sign("s3cr3t", body)
+	assert.Equal(t, "sha256=", sig[:7])
+	assert.Len(t, sig, 7+64)
+
+	assert.True(t, Verify("s3cr3t", body, sig))
+	assert.False(t, Verify("other", body, sig))
+	assert.False(t, Verify("s3cr3t", []byte(`{"topic":"todo:closed"}`), sig))
+	assert.False(t, Verify("s3cr3t", body, ""))
+}
+
+func TestNewEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		payload interface{}
+		want    string
+	}{
+		{
+			name:  "message",
+			topic: bawt.TopicMessageReceived,
+			payload: &bawt.Message{
+				Msg:         &slack.Msg{Channel: "C1", User: "U1", Text: "deploy done", Timestamp: "1.1"},
+				FromUser:    &slack.User{ID: "U1", Name: "jane"},
+				FromChannel: &bawt.Channel{ID: "C1", Name: "ops"},
+				Workspace:   "T1",
+			},
+			want: `{"channel":"C1","channel_name":"ops","user":"U1","user_name":"jane","text":"deploy done","ts":"1.1","edited":false}`,
+		},
+		{
+			name:    "reaction",
+			topic:   bawt.TopicReactionRemoved,
+			payload: reaction(),
+			want:    `{"type":"removed","user":"U2","emoji":"tada","channel":"C1","item_type":"message","item_ts":"1.1","ts":"2.2"}`,
+		},
+		{
+			name:    "other",
+			topic:   "todo:changed",
+			payload: map[string]string{"action": "created"},
+			want:    `{"action":"created"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newEnvelope(test.topic, test.payload)
+			assert.Equal(t, test.topic, env.Topic)
+			assert.NotEmpty(t, env.ID)
+
+			data, err := json.Marshal(env.Data)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.want, string(data))
+		})
+	}
+}
+
+func reaction() *bawt.ReactionEvent {
+	ev := &bawt.ReactionEvent{Type: bawt.ReactionRemoved, User: "U2", Emoji: "tada", Timestamp: "2.2"}
+	ev.Item.Type = "message"
+	ev.Item.Channel = "C1"
+	ev.Item.Timestamp = "1.1"
+	return ev
+}
@@ -0,0 +1,263 @@
+// Package webhooks is a plugin for bawt that forwards events of the event
+// bus, like messages, reactions or recognitions, to HTTP subscribers
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+// Plugin sends the configured events to the hooks
+type Plugin struct {
+	bot         *bawt.Bot
+	store       Store
+	dispatchers map[string]*dispatcher
+}
+
+func init() {
+	bawt.RegisterPlugin(&Plugin{})
+}
+
+// InitPlugin creates the dead-letter bucket and subscribes the hooks
+func (p *Plugin) InitPlugin(bot *bawt.Bot) {
+	p.bot = bot
+	log := bot.Logging.Logger
+
+	err := bot.DB.Update(createBuckets)
+	if err != nil {
+		log.Fatalln("Couldn't create the `webhooks` bucket")
+	}
+
+	var conf struct {
+		Webhooks Config
+	}
+	bot.LoadConfig(&conf)
+
+	p.store = &boltStore{db: bot.DB}
+	p.dispatchers = make(map[string]*dispatcher)
+
+	for _, hook := range conf.Webhooks.Hooks {
+		if err := hook.validate(); err != nil {
+			log.WithError(err).Error("Skipping webhook")
+			continue
+		}
+		if _, ok := p.dispatchers[hook.Name]; ok {
+			log.Errorf("Skipping webhook %q: duplicate name", hook.Name)
+			continue
+		}
+
+		d := newDispatcher(hook, p.store)
+		p.dispatchers[hook.Name] = d
+		go d.run()
+
+		p.subscribe(d)
+	}
+
+	bawt.RegisterAdminCommand(bawt.AdminCommand{
+		Name: "webhooks",
+		Commands: []bawt.Command{
+			{
+				Usage:    "!bawt webhooks",
+				HelpText: "Show the outgoing webhooks, their deliveries and dead-letter queues",
+			},
+			{
+				Usage:    "!bawt webhooks retry <hook>",
+				HelpText: "Send the dead-letter queue of a webhook again",
+			},
+			{
+				Usage:    "!bawt webhooks purge <hook>",
+				HelpText: "Drop the dead-letter queue of a webhook",
+			},
+		},
+		Handler: p.handleAdmin,
+	})
+}
+
+// subscribe forwards the events of a hook to its dispatcher, one
+// subscription per topic so that deliveries carry theirs
+func (p *Plugin) subscribe(d *dispatcher) {
+	hook := d.hook
+
+	for _, topic := range hook.Events {
+		topic := topic
+
+		p.bot.Events.Subscribe(func(payload interface{}) {
+			if !p.accept(hook, payload) {
+				return
+			}
+
+			env := newEnvelope(topic, payload)
+			body, err := json.Marshal(env)
+			if err != nil {
+				p.bot.ReportError("webhooks", fmt.Errorf("couldn't encode %s for %s: %s", topic, hook.Name, err))
+				return
+			}
+
+			d.enqueue(&Delivery{
+				ID:        env.ID,
+				Hook:      hook.Name,
+				Topic:     topic,
+				Body:      body,
+				CreatedAt: time.Now(),
+			})
+		}, topic)
+	}
+}
+
+// accept applies the filters of a hook
+func (p *Plugin) accept(hook Hook, payload interface{}) bool {
+	switch ev := payload.(type) {
+	case *bawt.Message:
+		if ev.FromMe {
+			return false
+		}
+		if hook.match != nil && !hook.match.MatchString(ev.Text) {
+			return false
+		}
+		return p.inChannels(hook, ev.Channel)
+
+	case *bawt.ReactionEvent:
+		return p.inChannels(hook, ev.Item.Channel)
+	}
+
+	return true
+}
+
+// inChannels tells whether a channel passes the Channels filter. Without
+// one, only public channels do: IMs and private conversations are sent
+// out when a hook lists them.
+func (p *Plugin) inChannels(hook Hook, channelID string) bool {
+	c, known := p.bot.Channels.Get(channelID)
+	if len(hook.Channels) == 0 {
+		return known && c.IsChannel
+	}
+
+	name := ""
+	if known {
+		name = c.Name
+	}
+
+	for _, c := range hook.Channels {
+		if c == channelID || (name != "" && strings.TrimPrefix(c, "#") == name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleAdmin answers `!bawt webhooks [retry|purge <hook>]`
+func (p *Plugin) handleAdmin(listen *bawt.Listener, msg *bawt.Message) {
+	parts := strings.Fields(msg.Match[0])[2:]
+
+	if len(parts) == 0 {
+		p.replyStatus(msg)
+		return
+	}
+
+	if len(parts) != 2 || (parts[0] != "retry" && parts[0] != "purge") {
+		msg.Reply("Use `!bawt webhooks`, `!bawt webhooks retry <hook>` or `!bawt webhooks purge <hook>`")
+		return
+	}
+
+	d, ok := p.dispatchers[parts[1]]
+	if !ok {
+		msg.Reply("I don't know the `%s` webhook.", parts[1])
+		return
+	}
+
+	var n int
+	var err error
+	if parts[0] == "retry" {
+		n, err = p.retry(d)
+	} else {
+		n, err = p.store.Purge(d.hook.Name)
+	}
+
+	entry := bawt.AuditEntry{
+		Actor:  msg.FromUser.ID,
+		Action: "webhooks:" + parts[0],
+		Target: d.hook.Name,
+		Params: map[string]string{"deliveries": fmt.Sprintf("%d", n)},
+		Result: bawt.AuditSuccess,
+	}
+	if err != nil {
+		entry.Result = bawt.AuditFailure
+		entry.Error = err.Error()
+	}
+	p.bot.Audit(entry)
+
+	switch {
+	case err != nil:
+		msg.Reply("Something went wrong with the dead-letter queue of `%s`: %s", d.hook.Name, err)
+	case parts[0] == "retry":
+		msg.Reply("Sending %d deliveries to `%s` again.", n, d.hook.Name)
+	default:
+		msg.Reply("Dropped %d deliveries of `%s`.", n, d.hook.Name)
+	}
+}
+
+// retry moves the dead deliveries of a hook back to its queue
+func (p *Plugin) retry(d *dispatcher) (int, error) {
+	dead, err := p.store.List(d.hook.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, del := range dead {
+		if err := p.store.Delete(del.Hook, del.ID); err != nil {
+			return n, err
+		}
+
+		del.Attempts = 0
+		del.LastError = ""
+		del.FailedAt = time.Time{}
+		d.enqueue(del)
+		n++
+	}
+
+	return n, nil
+}
+
+func (p *Plugin) replyStatus(msg *bawt.Message) {
+	if len(p.dispatchers) == 0 {
+		msg.Reply("No outgoing webhooks are configured.")
+		return
+	}
+
+	names := make([]string, 0, len(p.dispatchers))
+	for name := range p.dispatchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		d := p.dispatchers[name]
+		s := d.Stats()
+
+		host := d.hook.URL
+		if u, err := url.Parse(d.hook.URL); err == nil {
+			host = u.Host
+		}
+
+		line := fmt.Sprintf("*%s* → %s (%s)\n    delivered: %d, retries: %d, failed: %d, queued: %d, dead letters: %d",
+			name, host, strings.Join(d.hook.Events, ", "), s.Delivered, s.Retries, s.Failed, s.Queued, p.store.Count(name))
+		if !s.LastDelivery.IsZero() {
+			line += fmt.Sprintf("\n    last delivery: %s", s.LastDelivery.Format("2006-01-02 15:04:05"))
+		}
+		if s.LastError != "" {
+			line += fmt.Sprintf("\n    last error: %s", s.LastError)
+		}
+		lines = append(lines, line)
+	}
+
+	msg.Reply(strings.Join(lines, "\n"))
+}
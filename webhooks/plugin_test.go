@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/gopherworks/bawt"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptChannels(t *testing.T) {
+	bot := bawt.New("")
+	bot.Channels.Set(bawt.Channel{ID: "C1", Name: "ops", IsChannel: true})
+	bot.Channels.Set(bawt.Channel{ID: "G1", Name: "secret", IsGroup: true})
+	bot.Channels.Set(bawt.Channel{ID: "D1", IsIM: true, User: "U1"})
+	p := &Plugin{bot: bot}
+
+	message := func(channel string) *bawt.Message {
+		return &bawt.Message{Msg: &slack.Msg{Channel: channel, Text: "deploy done"}}
+	}
+
+	// Without channels, public channels only
+	all := Hook{Name: "all"}
+	assert.True(t, p.accept(all, message("C1")))
+	for _, channel := range []string{"G1", "D1", "C9"} {
+		assert.False(t, p.accept(all, message(channel)), channel)
+	}
+
+	// Private conversations once listed
+	listed := Hook{Name: "listed", Channels: []string{"#secret", "D1"}}
+	assert.True(t, p.accept(listed, message("G1")))
+	assert.True(t, p.accept(listed, message("D1")))
+	assert.False(t, p.accept(listed, message("C1")))
+}
@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Delivery is an event on its way to a hook
+type Delivery struct {
+	ID        string          `json:"id"`
+	Hook      string          `json:"hook"`
+	Topic     string          `json:"topic"`
+	Body      json.RawMessage `json:"body"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	FailedAt  time.Time       `json:"failed_at,omitempty"`
+}
+
+// Store is the dead-letter queue of the deliveries that failed
+type Store interface {
+	Add(d *Delivery) error
+	List(hook string) ([]*Delivery, error)
+	Delete(hook, id string) error
+	Purge(hook string) (int, error)
+	Count(hook string) int
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+var (
+	bucketName = []byte("webhooks")
+	deadBucket = []byte("dead") // [hook/id] = Delivery
+)
+
+func createBuckets(tx *bolt.Tx) error {
+	b, err := tx.CreateBucketIfNotExists(bucketName)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.CreateBucketIfNotExists(deadBucket)
+	return err
+}
+
+func deliveryKey(hook, id string) []byte {
+	return []byte(hook + "/" + id)
+}
+
+func (s *boltStore) bucket(tx *bolt.Tx) *bolt.Bucket {
+	return tx.Bucket(bucketName).Bucket(deadBucket)
+}
+
+// Add puts a delivery in the dead-letter queue
+func (s *boltStore) Add(d *Delivery) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cnt, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+
+		return s.bucket(tx).Put(deliveryKey(d.Hook, d.ID), cnt)
+	})
+}
+
+// List returns the dead deliveries of a hook, oldest first
+func (s *boltStore) List(hook string) (out []*Delivery, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(hook + "/")
+
+		c := s.bucket(tx).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var d Delivery
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			out = append(out, &d)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// Delete removes a delivery from the dead-letter queue
+func (s *boltStore) Delete(hook, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.bucket(tx).Delete(deliveryKey(hook, id))
+	})
+}
+
+// Purge drops all the dead deliveries of a hook
+func (s *boltStore) Purge(hook string) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := s.bucket(tx)
+		prefix := []byte(hook + "/")
+
+		var drop [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			drop = append(drop, append([]byte{}, k...))
+		}
+
+		for _, key := range drop {
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		removed = len(drop)
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// Count returns the number of dead deliveries of a hook
+func (s *boltStore) Count(hook string) (count int) {
+	s.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(hook + "/")
+
+		c := s.bucket(tx).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			count++
+		}
+
+		return nil
+	})
+
+	return
+}
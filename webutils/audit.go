@@ -0,0 +1,114 @@
+package webutils
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gopherworks/bawt"
+)
+
+func (utils *Utils) handleGetAuditJSON(w http.ResponseWriter, r *http.Request) {
+	entries, ok := utils.auditEntries(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	out := struct {
+		Entries []bawt.AuditEntry `json:"entries"`
+	}{
+		Entries: entries,
+	}
+
+	err := json.NewEncoder(w).Encode(out)
+	if err != nil {
+		webReportError(w, "Error encoding JSON", err)
+		return
+	}
+}
+
+func (utils *Utils) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	entries, ok := utils.auditEntries(w, r)
+	if !ok {
+		return
+	}
+
+	err := auditTemplate.Execute(w, struct {
+		User    string
+		Since   string
+		Entries []bawt.AuditEntry
+	}{
+		User:    r.FormValue("user"),
+		Since:   r.FormValue("since"),
+		Entries: entries,
+	})
+	if err != nil {
+		webReportError(w, "Error rendering template", err)
+	}
+}
+
+// auditEntries checks that the web user is a GlobalAdmin and returns
+// the entries matching the `user` and `since` (2006-01-02) parameters
+func (utils *Utils) auditEntries(w http.ResponseWriter, r *http.Request) ([]bawt.AuditEntry, bool) {
+	user, err := utils.bot.WebServer.AuthenticatedUser(r)
+	if err != nil || user == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	admins := bawt.InternalGroup{Name: "GlobalAdmins"}
+	admin, err := admins.IsUserMember(utils.bot.DB, user.ID)
+	if err != nil || !admin {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+
+	since := time.Now().AddDate(0, 0, -30)
+	if s := r.FormValue("since"); s != "" {
+		since, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			http.Error(w, "Invalid since parameter, use YYYY-MM-DD", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	entries, err := utils.bot.AuditLog(r.FormValue("user"), since)
+	if err != nil {
+		webReportError(w, "Error reading the audit log", err)
+		return nil, false
+	}
+
+	return entries, true
+}
+
+var auditTemplate = template.Must(template.New("audit").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+  <title>Audit log</title>
+</head>
+<body>
+  <h1>Audit log</h1>
+  <form method="GET">
+    User ID: <input name="user" value="{{.User}}">
+    Since: <input name="since" type="date" value="{{.Since}}">
+    <input type="submit" value="Filter">
+  </form>
+  <table>
+    <tr><th>Time</th><th>Actor</th><th>Action</th><th>Target</th><th>Parameters</th><th>Result</th></tr>
+    {{range .Entries}}
+    <tr>
+      <td>{{.Time.Format "2006-01-02 15:04:05"}}</td>
+      <td>{{.Actor}}</td>
+      <td>{{.Action}}</td>
+      <td>{{.Target}}</td>
+      <td>{{range $k, $v := .Params}}{{$k}}={{$v}} {{end}}</td>
+      <td>{{.Result}}{{if .Error}} ({{.Error}}){{end}}</td>
+    </tr>
+    {{end}}
+  </table>
+</body>
+</html>
+`))
@@ -0,0 +1,27 @@
+package webutils
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (utils *Utils) handleGetCommands(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := utils.bot.CommandReference().WriteHTML(w); err != nil {
+		webReportError(w, "Error rendering template", err)
+	}
+}
+
+func (utils *Utils) handleGetCommandsMarkdown(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	if err := utils.bot.CommandReference().WriteMarkdown(w); err != nil {
+		webReportError(w, "Error rendering template", err)
+	}
+}
+
+func (utils *Utils) handleGetCommandsJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(utils.bot.CommandReference()); err != nil {
+		webReportError(w, "Error encoding JSON", err)
+	}
+}
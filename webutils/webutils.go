@@ -22,6 +22,11 @@ func (utils *Utils) InitWebPlugin(bot *bawt.Bot, privRouter *mux.Router, pubRout
 	utils.bot = bot
 	privRouter.HandleFunc("/slack/channels", utils.handleGetChannels)
 	privRouter.HandleFunc("/slack/users", utils.handleGetUsers)
+	privRouter.HandleFunc("/bawt/audit", utils.handleGetAudit)
+	privRouter.HandleFunc("/bawt/audit.json", utils.handleGetAuditJSON)
+	privRouter.HandleFunc("/bawt/commands", utils.handleGetCommands)
+	privRouter.HandleFunc("/bawt/commands.md", utils.handleGetCommandsMarkdown)
+	privRouter.HandleFunc("/bawt/commands.json", utils.handleGetCommandsJSON)
 }
 
 func (utils *Utils) handleGetUsers(w http.ResponseWriter, r *http.Request) {
@@ -30,7 +35,7 @@ func (utils *Utils) handleGetUsers(w http.ResponseWriter, r *http.Request) {
 	out := struct {
 		Users map[string]slack.User `json:"users"`
 	}{
-		Users: utils.bot.Users,
+		Users: utils.bot.Users.Snapshot(),
 	}
 
 	err := enc.Encode(out)
@@ -47,7 +52,7 @@ func (utils *Utils) handleGetChannels(w http.ResponseWriter, r *http.Request) {
 	out := struct {
 		Channels map[string]bawt.Channel `json:"channels"`
 	}{
-		Channels: utils.bot.Channels,
+		Channels: utils.bot.Channels.Snapshot(),
 	}
 
 	err := enc.Encode(out)
@@ -14,11 +14,11 @@ func TestFindNextRoom(t *testing.T) {
 	c3.ID = "room3"
 	c3.Name = "room3"
 
+	channels := bawt.NewChannelDirectory(nil)
+	channels.Replace([]bawt.Channel{c2, c3})
+
 	w := &Wicked{
-		bot: &bawt.Bot{Channels: map[string]bawt.Channel{
-			"room2": c2,
-			"room3": c3,
-		}},
+		bot: &bawt.Bot{Channels: channels},
 		meetings: map[string]*Meeting{
 			"room1": {},
 		},
@@ -52,10 +52,11 @@ func TestFindNextRoomNilFromRoom(t *testing.T) {
 	c1 := bawt.Channel{}
 	c1.ID = "room1"
 	c1.Name = "room1"
+	channels := bawt.NewChannelDirectory(nil)
+	channels.Set(c1)
+
 	w := &Wicked{
-		bot: &bawt.Bot{Channels: map[string]bawt.Channel{
-			"room1": c1,
-		}},
+		bot:       &bawt.Bot{Channels: channels},
 		meetings:  map[string]*Meeting{},
 		confRooms: []string{"room1"},
 	}
@@ -72,7 +73,7 @@ func TestFindNextRoomNilFromRoom(t *testing.T) {
 
 func TestFindNextRoomAllTake(t *testing.T) {
 	w := &Wicked{
-		bot: &bawt.Bot{Channels: map[string]bawt.Channel{}},
+		bot: &bawt.Bot{Channels: bawt.NewChannelDirectory(nil)},
 		meetings: map[string]*Meeting{
 			"room1": {},
 		},
@@ -0,0 +1,219 @@
+package bawt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+WorkspaceConfig configures an additional Slack workspace, served by the
+same process as the one of the top-level `config`:
+
+	config:
+	  api_token: xoxb-first
+	  workspaces:
+	    - team_id: T0123
+	      team_domain: other
+	      api_token: xoxb-second
+	      join_channels: ["#general"]
+
+Everything else (database, plugins, admins, web server) is shared.
+*/
+type WorkspaceConfig struct {
+	TeamID         string   `json:"team_id" mapstructure:"team_id"`
+	TeamDomain     string   `json:"team_domain" mapstructure:"team_domain"`
+	APIToken       string   `json:"api_token" mapstructure:"api_token"`
+	JoinChannels   []string `json:"join_channels" mapstructure:"join_channels"`
+	GeneralChannel string   `json:"general_channel" mapstructure:"general_channel"`
+}
+
+// incomingEvent is an RTM event along with the workspace it comes from
+type incomingEvent struct {
+	bot   *Bot
+	event slack.RTMEvent
+}
+
+/*
+newWorkspace returns a Bot talking to another workspace. It has its own
+Slack connection, caches and outgoing queue, and shares everything else
+with `bot`, including the listeners: events of all the workspaces go
+through the same event loop.
+*/
+func (bot *Bot) newWorkspace(wc WorkspaceConfig) *Bot {
+	config := bot.Config
+	config.TeamID = wc.TeamID
+	config.TeamDomain = wc.TeamDomain
+	config.APIToken = wc.APIToken
+	config.JoinChannels = wc.JoinChannels
+	config.GeneralChannel = wc.GeneralChannel
+	config.Workspaces = nil
+
+	ws := &Bot{
+		parent:       bot,
+		configFile:   bot.configFile,
+		Status:       bot.Status,
+		Config:       config,
+		Logging:      bot.Logging,
+		GlobalAdmins: bot.GlobalAdmins,
+
+		Users:    NewUserDirectory(bot.PubSub),
+		Groups:   bot.Groups,
+		Channels: NewChannelDirectory(bot.PubSub),
+
+		addListenerCh:  bot.addListenerCh,
+		delListenerCh:  bot.delListenerCh,
+		outgoingMsgCh:  make(chan *slack.OutgoingMessage, 500),
+		outgoingFileCh: make(chan *slack.File, 500),
+		incomingEvents: bot.incomingEvents,
+		stopCh:         bot.stopCh,
+
+		DB:        bot.DB,
+		PubSub:    bot.PubSub,
+		Events:    bot.Events,
+		WebServer: bot.WebServer,
+		Moods:     bot.Moods,
+	}
+
+	ws.workspaceID = wc.TeamID
+	ws.Channels.SetWorkspace(wc.TeamID)
+
+	return ws
+}
+
+// startWorkspaces connects the additional workspaces in the background
+func (bot *Bot) startWorkspaces() {
+	log := bot.Logging.Logger
+
+	for _, wc := range bot.Config.Workspaces {
+		if wc.APIToken == "" || wc.TeamID == "" {
+			log.Errorf("Skipping workspace %q: team_id and api_token are required", wc.TeamDomain)
+			continue
+		}
+
+		ws := bot.newWorkspace(wc)
+
+		if strings.ToUpper(bot.Logging.Level) == "TRACE" {
+			ws.Slack = slack.New(wc.APIToken, slack.OptionDebug(true))
+		} else {
+			ws.Slack = slack.New(wc.APIToken)
+		}
+		ws.Users.SetFetcher(ws.Slack.GetUserInfo)
+
+		bot.Status.Register(ws.chatComponent(), false, nil)
+
+		bot.workspacesLock.Lock()
+		bot.workspaces = append(bot.workspaces, ws)
+		bot.workspacesLock.Unlock()
+
+		go ws.replyHandler()
+		go ws.manageConnection()
+
+		log.Infof("Connecting to workspace %s (%s)", wc.TeamDomain, wc.TeamID)
+	}
+}
+
+// root returns the Bot owning the shared state: itself, unless it
+// serves an additional workspace
+func (bot *Bot) root() *Bot {
+	if bot.parent != nil {
+		return bot.parent
+	}
+	return bot
+}
+
+// WorkspaceID returns the team ID of the workspace this Bot talks to.
+// For the main workspace, it is `config.team_id` or, when not configured,
+// learned once connected.
+func (bot *Bot) WorkspaceID() string {
+	bot.connLock.Lock()
+	defer bot.connLock.Unlock()
+
+	if bot.workspaceID != "" {
+		return bot.workspaceID
+	}
+	return bot.Config.TeamID
+}
+
+// Workspaces returns the Bots of all the workspaces, the main one first
+func (bot *Bot) Workspaces() []*Bot {
+	root := bot.root()
+
+	root.workspacesLock.RLock()
+	defer root.workspacesLock.RUnlock()
+
+	return append([]*Bot{root}, root.workspaces...)
+}
+
+// Workspace returns the Bot of a workspace, by team ID or domain. An
+// empty string returns the main workspace.
+func (bot *Bot) Workspace(workspace string) *Bot {
+	if workspace == "" {
+		return bot.root()
+	}
+
+	for _, ws := range bot.Workspaces() {
+		if ws.WorkspaceID() == workspace || strings.EqualFold(ws.Config.TeamDomain, workspace) {
+			return ws
+		}
+	}
+
+	return nil
+}
+
+// SendToChannelIn sends a message to a channel of the given workspace,
+// see Workspace
+func (bot *Bot) SendToChannelIn(workspace, channelName, message string) *Reply {
+	ws := bot.Workspace(workspace)
+	if ws == nil {
+		bot.Logging.Logger.WithFields(logrus.Fields{
+			"Type":      "WorkspaceNotFound",
+			"Workspace": workspace,
+			"Channel":   channelName,
+		}).Error("Error sending message to channel.")
+
+		return nil
+	}
+
+	return ws.SendToChannel(channelName, message)
+}
+
+// SendPrivateMessageIn sends a message to a user of the given workspace,
+// see Workspace
+func (bot *Bot) SendPrivateMessageIn(workspace, username, message string) *Reply {
+	ws := bot.Workspace(workspace)
+	if ws == nil {
+		bot.Logging.Logger.WithFields(logrus.Fields{
+			"Type":      "WorkspaceNotFound",
+			"Workspace": workspace,
+			"Recipient": username,
+		}).Error("Error sending message.")
+
+		return nil
+	}
+
+	return ws.SendPrivateMessage(username, message)
+}
+
+/*
+Namespace prefixes a storage key with the workspace ID, so plugins
+storing data that isn't keyed by Slack IDs (which are unique across
+workspaces) don't mix workspaces up. Keys of the main workspace are left
+as they are, so existing data keeps working.
+*/
+func (bot *Bot) Namespace(key string) string {
+	if bot.parent == nil {
+		return key
+	}
+	return fmt.Sprintf("%s:%s", bot.WorkspaceID(), key)
+}
+
+// chatComponent is the name of the Status component of the connection
+func (bot *Bot) chatComponent() string {
+	if bot.parent == nil {
+		return "chat"
+	}
+	return "chat:" + strings.ToLower(bot.Config.TeamDomain)
+}
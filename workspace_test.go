@@ -0,0 +1,83 @@
+package bawt
+
+import (
+	"testing"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWorkspaces() (*Bot, *Bot) {
+	root := New("")
+	root.Logging.Logger = logrus.New()
+	root.Config.TeamID = "T1"
+	root.Config.TeamDomain = "main"
+
+	ws := root.newWorkspace(WorkspaceConfig{TeamID: "T2", TeamDomain: "Other", GeneralChannel: "lobby"})
+	root.workspaces = append(root.workspaces, ws)
+
+	return root, ws
+}
+
+func TestWorkspaces(t *testing.T) {
+	root, ws := newTestWorkspaces()
+
+	assert.Equal(t, []*Bot{root, ws}, root.Workspaces())
+	assert.Equal(t, []*Bot{root, ws}, ws.Workspaces())
+
+	assert.Equal(t, root, ws.Workspace(""))
+	assert.Equal(t, root, ws.Workspace("T1"))
+	assert.Equal(t, ws, root.Workspace("T2"))
+	assert.Equal(t, ws, root.Workspace("other"))
+	assert.Nil(t, root.Workspace("T3"))
+
+	assert.Equal(t, "T2", ws.WorkspaceID())
+	assert.Equal(t, "lobby", ws.Config.GeneralChannel)
+	assert.Nil(t, ws.Config.Workspaces)
+
+	assert.Equal(t, "chat", root.chatComponent())
+	assert.Equal(t, "chat:other", ws.chatComponent())
+
+	assert.Equal(t, "key", root.Namespace("key"))
+	assert.Equal(t, "T2:key", ws.Namespace("key"))
+}
+
+func TestWorkspaceDispatch(t *testing.T) {
+	root, ws := newTestWorkspaces()
+
+	c := Channel{ID: "C2", Name: "lobby", IsChannel: true}
+	ws.Channels.Set(c)
+	ws.Users.Set(slack.User{ID: "U2", Name: "bob"})
+
+	var received *Message
+	root.listeners = append(root.listeners, &Listener{
+		Bot: root,
+		EventHandlerFunc: func(_ *Listener, event interface{}) {
+			received, _ = event.(*Message)
+		},
+	})
+	mainOnly := false
+	root.listeners = append(root.listeners, &Listener{
+		Bot:               root,
+		MainWorkspaceOnly: true,
+		EventHandlerFunc: func(_ *Listener, event interface{}) {
+			mainOnly = true
+		},
+	})
+
+	ws.handleRTMEvent(&slack.RTMEvent{Data: &slack.MessageEvent{Msg: slack.Msg{Channel: "C2", User: "U2", Text: "hello"}}})
+
+	if assert.NotNil(t, received) {
+		assert.Equal(t, "T2", received.Workspace)
+		assert.Equal(t, ws, received.Bot())
+		assert.Equal(t, "T2", received.FromChannel.Workspace)
+		assert.Equal(t, "bob", received.FromUser.Name)
+	}
+
+	assert.False(t, mainOnly)
+
+	// The main workspace doesn't know about the other's channels
+	_, ok := root.Channels.Get("C2")
+	assert.False(t, ok)
+}